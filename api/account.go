@@ -30,20 +30,20 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return 
 	}
   authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	arg := db.CreateAccountParams{
+	arg := db.CreateAccountTxParams{
 		Owner:         authPayload.Username,
 		Currency:      req.Currency,
-		Balance:       10000, // $100 free credit for testing
 		AccountNumber: sql.NullString{String: util.RandomAccountNumber(), Valid: true},
+		WelcomeCredit: util.WelcomeCreditAmount, // $100 free credit for testing
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	txResult, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return 
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, txResult.Account)
 }
 
 func (server *Server) getAccount(ctx *gin.Context) {
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/gin-gonic/gin"
+)
+
+type listAccountEntriesURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type listAccountEntriesRequest struct {
+	StartTime time.Time `form:"start_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	EndTime   time.Time `form:"end_time" time_format:"2006-01-02T15:04:05Z07:00"`
+	PageID    int32     `form:"page_id" binding:"required,min=1"`
+	PageSize  int32     `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+type listAccountEntriesResponse struct {
+	OpeningBalance int64                               `json:"opening_balance"`
+	ClosingBalance int64                               `json:"closing_balance"`
+	Currency       string                              `json:"currency"`
+	Entries        []db.ListAccountStatementEntriesRow `json:"entries"`
+}
+
+func (server *Server) listAccountEntries(ctx *gin.Context) {
+	var uri listAccountEntriesURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var req listAccountEntriesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		err := errors.New("account does not belong to authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	// Default to the whole history of the account
+	startTime := req.StartTime
+	if startTime.IsZero() {
+		startTime = account.CreatedAt
+	}
+	endTime := req.EndTime
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+	if !startTime.Before(endTime) {
+		err := errors.New("end_time must be after start_time")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	entries, err := server.store.ListAccountStatementEntries(ctx, db.ListAccountStatementEntriesParams{
+		AccountID: account.ID,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	balances, err := server.store.GetAccountStatementBalances(ctx, db.GetAccountStatementBalancesParams{
+		StartTime: startTime,
+		EndTime:   endTime,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := listAccountEntriesResponse{
+		OpeningBalance: balances.OpeningBalance,
+		ClosingBalance: balances.ClosingBalance,
+		Currency:       account.Currency,
+		Entries:        entries,
+	}
+	ctx.JSON(http.StatusOK, rsp)
+}
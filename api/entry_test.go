@@ -0,0 +1,266 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListAccountEntriesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	counterparty := randomAccount(otherUser.Username)
+
+	n := 5
+	entries := make([]db.ListAccountStatementEntriesRow, n)
+	for i := 0; i < n; i++ {
+		entries[i] = db.ListAccountStatementEntriesRow{
+			ID:                        util.RandomInt(1, 1000),
+			AccountID:                 account.ID,
+			Amount:                    util.RandomMoney(),
+			CreatedAt:                 time.Now().UTC().Truncate(time.Second),
+			Currency:                  account.Currency,
+			TransferID:                sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true},
+			RunningBalance:            util.RandomMoney(),
+			CounterpartyAccountID:     counterparty.ID,
+			CounterpartyAccountNumber: counterparty.AccountNumber.String,
+		}
+	}
+	balances := db.GetAccountStatementBalancesRow{
+		OpeningBalance: util.RandomMoney(),
+		ClosingBalance: util.RandomMoney(),
+	}
+
+	startTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	endTime := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		accountID     int64
+		query         string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			accountID: account.ID,
+			query: fmt.Sprintf("page_id=1&page_size=%d&start_time=%s&end_time=%s",
+				n, startTime.Format(time.RFC3339), endTime.Format(time.RFC3339)),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					ListAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ListAccountStatementEntriesParams) ([]db.ListAccountStatementEntriesRow, error) {
+						require.Equal(t, account.ID, arg.AccountID)
+						require.True(t, startTime.Equal(arg.StartTime))
+						require.True(t, endTime.Equal(arg.EndTime))
+						require.Equal(t, int32(n), arg.Limit)
+						require.Equal(t, int32(0), arg.Offset)
+						return entries, nil
+					})
+
+				store.EXPECT().
+					GetAccountStatementBalances(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(balances, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+
+				var rsp listAccountEntriesResponse
+				err = json.Unmarshal(data, &rsp)
+				require.NoError(t, err)
+				require.Equal(t, balances.OpeningBalance, rsp.OpeningBalance)
+				require.Equal(t, balances.ClosingBalance, rsp.ClosingBalance)
+				require.Equal(t, account.Currency, rsp.Currency)
+				require.Len(t, rsp.Entries, n)
+				for i := range entries {
+					require.Equal(t, entries[i].ID, rsp.Entries[i].ID)
+					require.Equal(t, entries[i].RunningBalance, rsp.Entries[i].RunningBalance)
+					require.Equal(t, entries[i].CounterpartyAccountID, rsp.Entries[i].CounterpartyAccountID)
+				}
+			},
+		},
+		{
+			name:      "DefaultDateRange",
+			accountID: account.ID,
+			query:     fmt.Sprintf("page_id=1&page_size=%d", n),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					ListAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ any, arg db.ListAccountStatementEntriesParams) ([]db.ListAccountStatementEntriesRow, error) {
+						require.True(t, account.CreatedAt.Equal(arg.StartTime))
+						require.WithinDuration(t, time.Now(), arg.EndTime, time.Second)
+						return entries, nil
+					})
+
+				store.EXPECT().
+					GetAccountStatementBalances(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(balances, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidDateRange",
+			accountID: account.ID,
+			query: fmt.Sprintf("page_id=1&page_size=%d&start_time=%s&end_time=%s",
+				n, endTime.Format(time.RFC3339), startTime.Format(time.RFC3339)),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					ListAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidPageSize",
+			accountID: account.ID,
+			query:     "page_id=1&page_size=100000",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "NotFound",
+			accountID: account.ID,
+			query:     fmt.Sprintf("page_id=1&page_size=%d", n),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					ListAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "UnauthorizedUser",
+			accountID: account.ID,
+			query:     fmt.Sprintf("page_id=1&page_size=%d", n),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, otherUser.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					ListAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "DatabaseError",
+			accountID: account.ID,
+			query:     fmt.Sprintf("page_id=1&page_size=%d", n),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					ListAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListAccountStatementEntriesRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/entries?%s", tc.accountID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,7 +17,16 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// Roles allowed on each group of routes, as for the matching gRPC methods.
+// Support staff can read any account but can't move money.
+var (
+	allRoles   = []string{util.DepositorRole, util.SupportRole, util.AdminRole}
+	moneyRoles = []string{util.DepositorRole, util.AdminRole}
+)
+
+// authMiddleware authenticates the request and lets it through only if the token's
+// role is one of accessibleRoles. MFA pending tokens are never accepted here.
+func authMiddleware(tokenMaker token.Maker, passwordChanges *token.PasswordChangeCache, accessibleRoles []string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
@@ -46,7 +56,28 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 			return
 		}
 
+		if !hasPermission(payload.Role, accessibleRoles) {
+			err := fmt.Errorf("role %s is not allowed to access this route", payload.Role)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		err = passwordChanges.CheckToken(ctx, payload)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
 		ctx.Set(authorizationPayloadKey, payload)
 		ctx.Next()
 	}
 }
+
+func hasPermission(userRole string, accessibleRoles []string) bool {
+	for _, role := range accessibleRoles {
+		if userRole == role {
+			return true
+		}
+	}
+	return false
+}
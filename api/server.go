@@ -19,6 +19,7 @@ type Server struct {
 	store           db.Store
 	tokenMaker      token.Maker
 	taskDistributor worker.TaskDistributor
+	passwordChanges *token.PasswordChangeCache
 	router          *gin.Engine
 }
 
@@ -33,6 +34,7 @@ func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDi
 		store:           store,
 		tokenMaker:      tokenMaker,
 		taskDistributor: taskDistributor,
+		passwordChanges: token.NewPasswordChangeCache(db.PasswordChangedAt(store), config.PasswordChangeCacheDuration),
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
@@ -53,15 +55,17 @@ func (server *Server) setUpRouter() {
 	router.POST("/token.renew_access", server.renewAccessToken)
 	router.GET("/verify_email", server.verifyEmail)
 	router.POST("/resend_verification", server.resendVerificationEmail)
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	readRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.passwordChanges, allRoles))
+	moneyRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.passwordChanges, moneyRoles))
 	// Account routes
-	authRoutes.POST("/accounts", server.createAccount)
-	authRoutes.GET("/accounts/:id", server.getAccount)
-	authRoutes.GET("/accounts", server.listAccount)
+	moneyRoutes.POST("/accounts", server.createAccount)
+	readRoutes.GET("/accounts/:id", server.getAccount)
+	readRoutes.GET("/accounts", server.listAccount)
+	readRoutes.GET("/accounts/:id/entries", server.listAccountEntries)
 
 	// Transfer routes
-	authRoutes.POST("/transfers", server.createTransfer)
-	authRoutes.GET("/transfers", server.listTransfers)
+	moneyRoutes.POST("/transfers", server.createTransfer)
+	readRoutes.GET("/transfers", server.listTransfers)
 
 	server.router = router
 }
@@ -1,10 +1,12 @@
 package api
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/token"
 	"github.com/OmSingh2003/nimbus/util"
 	mockwk "github.com/OmSingh2003/nimbus/worker/mock"
 	"github.com/stretchr/testify/require"
@@ -25,6 +27,13 @@ func NewTestServer(t *testing.T, store db.Store) *Server {
 	server, err := NewServer(config, store, taskDistributor)
 	require.NoError(t, err)
 
+	// Most tests don't stub the user lookup behind the password change check.
+	// The router is set up again since the middleware holds on to the cache.
+	server.passwordChanges = token.NewPasswordChangeCache(func(ctx context.Context, username string) (time.Time, error) {
+		return time.Time{}, nil
+	}, time.Minute)
+	server.setUpRouter()
+
 	return server
 }
 
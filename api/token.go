@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
 	"github.com/gin-gonic/gin"
 )
 
@@ -34,6 +35,16 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		return
 	}
 
+	// A rotated refresh token is being replayed: block every session of the login
+	if session.ReplacedBy.Valid {
+		_, err = server.store.BlockSessionFamily(ctx, session.FamilyID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusUnauthorized, errorResponse(db.ErrRefreshTokenReused))
+		return
+	}
 	if session.IsBoolean {
 		err := fmt.Errorf("blocked session")
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
@@ -54,8 +65,14 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
 		return
 	}
+	err = server.passwordChanges.CheckToken(ctx, refreshPayload)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
 		refreshPayload.Username,
+		refreshPayload.Role,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 	"github.com/gin-gonic/gin"
 	db "github.com/OmSingh2003/nimbus/db/sqlc"
 	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/val"
 )
 
 
@@ -19,6 +20,9 @@ type transferRequest struct {
 	Currency         string `json:"currency" binding:"required,currency"`
 }
 
+// idempotencyKeyHeader lets clients safely retry a transfer request
+const idempotencyKeyHeader = "Idempotency-Key"
+
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -40,14 +44,39 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 	if !valid{
 		return
 	}
+	idempotencyKey := ctx.GetHeader(idempotencyKeyHeader)
+	if idempotencyKey != "" {
+		if err := val.ValidateIdempotencyKey(idempotencyKey); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("invalid %s header: %w", idempotencyKeyHeader, err)))
+			return
+		}
+	}
 	arg := db.TransferTxParams{
 		FromAccountID :req.FromAccountID,
 		ToAccountID: req.ToAccountID,
 		Amount: req.Amount,
+		Currency: req.Currency,
+		IdempotencyKey: idempotencyKey,
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrCurrencyMismatch) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return 
 	}
@@ -87,8 +116,7 @@ func (server *Server) listTransfers(ctx *gin.Context) {
 	}
 
 	arg := db.ListTransfersParams{
-		FromAccountID: req.AccountID,
-		ToAccountID:   req.AccountID,
+		AccountID: req.AccountID,
 		Limit:        req.PageSize,
 		Offset:       (req.PageID - 1) * req.PageSize,
 	}
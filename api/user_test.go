@@ -345,6 +345,7 @@ func randomUser(t *testing.T) (user db.User, password string) {
 		IsEmailVerified:  true, // Set to true for login tests to pass
 		PasswordChangedAt: time.Now(),
 		CreatedAt:        time.Now(),
+		Role:             util.DepositorRole,
 	}
 	return
 }
@@ -0,0 +1,3 @@
+ALTER TABLE "account" DROP CONSTRAINT IF EXISTS "account_overdraft_limit_check";
+
+ALTER TABLE "account" DROP COLUMN IF EXISTS "overdraft_limit";
@@ -0,0 +1,6 @@
+-- Add a per-account overdraft limit; 0 means the balance may never go negative
+ALTER TABLE "account" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "account" ADD CONSTRAINT "account_overdraft_limit_check" CHECK ("overdraft_limit" >= 0);
+
+COMMENT ON COLUMN "account"."overdraft_limit" IS 'how far below zero the balance is allowed to go';
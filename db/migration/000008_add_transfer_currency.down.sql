@@ -0,0 +1,3 @@
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "currency";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "currency";
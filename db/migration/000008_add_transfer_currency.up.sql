@@ -0,0 +1,18 @@
+-- Store the currency on transfers and entries so history doesn't depend on the accounts
+ALTER TABLE "transfers" ADD COLUMN "currency" varchar;
+
+ALTER TABLE "entries" ADD COLUMN "currency" varchar;
+
+UPDATE "transfers" AS t
+SET "currency" = a."currency"
+FROM "account" AS a
+WHERE a."id" = t."from_account_id";
+
+UPDATE "entries" AS e
+SET "currency" = a."currency"
+FROM "account" AS a
+WHERE a."id" = e."account_id";
+
+ALTER TABLE "transfers" ALTER COLUMN "currency" SET NOT NULL;
+
+ALTER TABLE "entries" ALTER COLUMN "currency" SET NOT NULL;
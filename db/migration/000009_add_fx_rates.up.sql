@@ -0,0 +1,37 @@
+-- Exchange rates: 1 unit of base_currency buys "rate" units of quote_currency
+CREATE TABLE "fx_rates" (
+  "base_currency" varchar NOT NULL,
+  "quote_currency" varchar NOT NULL,
+  "rate" numeric(20,10) NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("base_currency", "quote_currency"),
+  CONSTRAINT "fx_rates_rate_check" CHECK ("rate" > 0)
+);
+
+-- Seed with the rates the welcome credits used to hard-code ($100 = €95 = ₹8300)
+INSERT INTO "fx_rates" ("base_currency", "quote_currency", "rate") VALUES
+  ('USD', 'EUR', 0.95),
+  ('USD', 'INR', 83),
+  ('EUR', 'INR', 87.3684210526),
+  ('EUR', 'USD', 1.0526315789),
+  ('INR', 'USD', 0.0120481928),
+  ('INR', 'EUR', 0.0114457831);
+
+-- Cross-currency transfers credit to_amount in to_currency; same-currency ones use rate 1
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "to_currency" varchar;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" numeric(20,10) NOT NULL DEFAULT 1;
+
+ALTER TABLE "transfers" ADD COLUMN "spread_bps" bigint NOT NULL DEFAULT 0;
+
+UPDATE "transfers" SET "to_amount" = "amount", "to_currency" = "currency";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "to_currency" SET NOT NULL;
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited in to_currency';
+
+COMMENT ON COLUMN "transfers"."spread_bps" IS 'spread kept on the conversion, in basis points';
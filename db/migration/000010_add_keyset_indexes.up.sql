@@ -0,0 +1,6 @@
+-- Indexes backing keyset pagination on (created_at, id)
+CREATE INDEX "account_owner_created_at_id_idx" ON "account" ("owner", "created_at", "id");
+
+CREATE INDEX "entries_account_id_created_at_id_idx" ON "entries" ("account_id", "created_at", "id");
+
+CREATE INDEX "transfers_created_at_id_idx" ON "transfers" ("created_at", "id");
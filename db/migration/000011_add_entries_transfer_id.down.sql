@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "entries_transfer_id_idx";
+
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "transfer_id";
@@ -0,0 +1,10 @@
+-- Sessions created by rotating a refresh token share the family of the login that started them
+ALTER TABLE "sessions" ADD COLUMN "family_id" uuid;
+UPDATE "sessions" SET "family_id" = "id";
+ALTER TABLE "sessions" ALTER COLUMN "family_id" SET NOT NULL;
+
+-- The session that replaced this one when its refresh token was used
+ALTER TABLE "sessions" ADD COLUMN "replaced_by" uuid;
+ALTER TABLE "sessions" ADD FOREIGN KEY ("replaced_by") REFERENCES "sessions" ("id");
+
+CREATE INDEX "sessions_family_id_idx" ON "sessions" ("family_id");
@@ -0,0 +1,4 @@
+-- Staff roles: support can read any account, admin can also manage them
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('depositor', 'support', 'admin'));
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "account_adjustments";
+
+ALTER TABLE "account" DROP CONSTRAINT IF EXISTS "account_status_check";
+
+ALTER TABLE "account" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,23 @@
+-- Frozen accounts can't send or receive transfers
+ALTER TABLE "account" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+ALTER TABLE "account" ADD CONSTRAINT "account_status_check" CHECK ("status" IN ('active', 'frozen'));
+
+-- Balance corrections posted by admins, each backed by an entry
+CREATE TABLE "account_adjustments" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "entry_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason" varchar NOT NULL,
+  "created_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_adjustments" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "account_adjustments" ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+
+ALTER TABLE "account_adjustments" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+CREATE INDEX ON "account_adjustments" ("account_id");
@@ -0,0 +1,7 @@
+ALTER TABLE "account" DROP COLUMN IF EXISTS "closed_at";
+
+ALTER TABLE "account" DROP CONSTRAINT IF EXISTS "account_status_check";
+
+UPDATE "account" SET "status" = 'frozen' WHERE "status" = 'closed';
+
+ALTER TABLE "account" ADD CONSTRAINT "account_status_check" CHECK ("status" IN ('active', 'frozen'));
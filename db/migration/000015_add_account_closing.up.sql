@@ -0,0 +1,6 @@
+-- Closed accounts are kept for their history but can't be used again
+ALTER TABLE "account" DROP CONSTRAINT IF EXISTS "account_status_check";
+
+ALTER TABLE "account" ADD CONSTRAINT "account_status_check" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+ALTER TABLE "account" ADD COLUMN "closed_at" timestamptz;
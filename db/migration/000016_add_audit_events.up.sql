@@ -0,0 +1,34 @@
+-- Security and money events. Each row carries the hash of the row before it, so
+-- changing or removing a row breaks the chain from that point on.
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  -- The user whose security log the event belongs to; not a foreign key so failed
+  -- logins for unknown usernames can be recorded too
+  "username" varchar NOT NULL,
+  -- Who triggered the event: the user, or the admin acting on their account
+  "actor" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  -- json rather than jsonb so the text that was hashed is kept as is
+  "details" json NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL,
+  "prev_hash" varchar UNIQUE NOT NULL,
+  "hash" varchar UNIQUE NOT NULL
+);
+
+CREATE INDEX ON "audit_events" ("username", "id");
+
+CREATE FUNCTION "audit_events_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_no_update"
+  BEFORE UPDATE OR DELETE ON "audit_events"
+  FOR EACH ROW EXECUTE FUNCTION "audit_events_append_only"();
+
+CREATE TRIGGER "audit_events_no_truncate"
+  BEFORE TRUNCATE ON "audit_events"
+  FOR EACH STATEMENT EXECUTE FUNCTION "audit_events_append_only"();
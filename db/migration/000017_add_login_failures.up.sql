@@ -0,0 +1,11 @@
+-- Consecutive failed logins per username and per client IP
+CREATE TABLE "login_failures" (
+  -- username or ip
+  "scope" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "failure_count" bigint NOT NULL DEFAULT 0,
+  "last_failed_at" timestamptz NOT NULL DEFAULT (now()),
+  -- No login is attempted for the key before this time
+  "locked_until" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("scope", "key")
+);
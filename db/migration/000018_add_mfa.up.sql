@@ -0,0 +1,25 @@
+-- TOTP second factor. Enrollment isn't finished until enabled_at is set
+-- by confirming a first code.
+CREATE TABLE "user_mfa" (
+  "username" varchar PRIMARY KEY,
+  "totp_secret" varchar NOT NULL,
+  -- Time step of the last accepted code, so each code works only once
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "enabled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "user_mfa" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+-- One-time codes for logging in without the authenticator app, stored hashed
+CREATE TABLE "mfa_recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "mfa_recovery_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE UNIQUE INDEX ON "mfa_recovery_codes" ("username", "code_hash");
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "pending_transfers";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "transfer_confirmation_threshold";
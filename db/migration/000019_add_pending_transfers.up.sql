@@ -0,0 +1,36 @@
+-- Transfers above this amount must be confirmed again; NULL uses the configured default
+ALTER TABLE "users" ADD COLUMN "transfer_confirmation_threshold" bigint;
+
+-- Transfers held until the user re-authenticates to confirm them. The quote is
+-- kept so the confirmed transfer is exactly the one the user was shown.
+CREATE TABLE "pending_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "exchange_rate" varchar NOT NULL,
+  "spread_bps" bigint NOT NULL,
+  "idempotency_key" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  -- The transfer made once confirmed
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "confirmed_at" timestamptz
+);
+
+ALTER TABLE "pending_transfers" ADD CONSTRAINT "pending_transfers_status_check" CHECK ("status" IN ('pending', 'confirmed', 'expired'));
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "pending_transfers" ("username");
@@ -0,0 +1,16 @@
+-- Email changes waiting for the new address to be verified. The user's email
+-- stays the same until the link sent to new_email is followed.
+CREATE TABLE "email_changes" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "old_email" varchar NOT NULL,
+  "new_email" varchar NOT NULL,
+  "secret_hash" varchar NOT NULL,
+  "is_used" bool NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL DEFAULT (now() + interval '24 hours')
+);
+
+ALTER TABLE "email_changes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+CREATE INDEX ON "email_changes" ("username");
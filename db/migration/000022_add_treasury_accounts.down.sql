@@ -0,0 +1,31 @@
+-- Removing the treasury also removes every transfer it took part in, leaving the
+-- balances it funded without entries again
+CREATE TEMPORARY TABLE "treasury_transfers" AS
+SELECT t."id"
+FROM "transfers" AS t
+JOIN "account" AS a ON a."id" IN (t."from_account_id", t."to_account_id")
+WHERE a."owner" = 'treasury';
+
+-- Adjustments go back to being a single entry on the adjusted account
+UPDATE "entries" SET "transfer_id" = NULL
+WHERE "id" IN (SELECT "entry_id" FROM "account_adjustments");
+
+ALTER TABLE "account_adjustments" DROP COLUMN "transfer_id";
+
+UPDATE "idempotency_keys" SET "transfer_id" = NULL
+WHERE "transfer_id" IN (SELECT "id" FROM "treasury_transfers");
+
+UPDATE "pending_transfers" SET "transfer_id" = NULL
+WHERE "transfer_id" IN (SELECT "id" FROM "treasury_transfers");
+
+DELETE FROM "entries"
+WHERE "transfer_id" IN (SELECT "id" FROM "treasury_transfers")
+   OR "account_id" IN (SELECT "id" FROM "account" WHERE "owner" = 'treasury');
+
+DELETE FROM "transfers" WHERE "id" IN (SELECT "id" FROM "treasury_transfers");
+
+DROP TABLE "treasury_transfers";
+
+DELETE FROM "account" WHERE "owner" = 'treasury';
+
+DELETE FROM "users" WHERE "username" = 'treasury';
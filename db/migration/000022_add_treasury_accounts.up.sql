@@ -0,0 +1,144 @@
+-- The treasury is a system user whose accounts fund credits that don't come from a
+-- customer, like welcome credits. Its balances go negative by exactly what it has paid out,
+-- so the entries of each currency always sum to zero.
+-- The password hash is not a valid bcrypt hash, so nobody can log in as the treasury.
+-- A customer who already registered the name must be renamed first, otherwise their
+-- accounts would become the treasury's.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM "users" WHERE "username" = 'treasury') THEN
+    RAISE EXCEPTION 'username "treasury" is reserved for the system treasury; rename that user before migrating';
+  END IF;
+END $$;
+
+INSERT INTO "users" (
+  "username",
+  "hashed_password",
+  "full_name",
+  "email",
+  "is_email_verified"
+) VALUES (
+  'treasury',
+  '!',
+  'Nimbus Treasury',
+  'treasury@nimbus.internal',
+  true
+);
+
+-- One treasury account per supported currency, and per any other currency already in use
+INSERT INTO "account" ("owner", "balance", "currency", "account_number")
+SELECT 'treasury', 0, c."currency", 'TREASURY-' || c."currency"
+FROM (
+  SELECT unnest(ARRAY['USD', 'EUR', 'INR']) AS "currency"
+  UNION
+  SELECT DISTINCT "currency" FROM "account"
+) AS c;
+
+-- Admin adjustments are booked as transfers with the treasury too
+ALTER TABLE "account_adjustments" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "account_adjustments" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- Adjustments posted before the treasury existed only have the entry on the adjusted
+-- account. Give each one a transfer and the matching treasury entry.
+CREATE TEMPORARY TABLE "adjustment_transfers" (
+  "adjustment_id" bigint NOT NULL,
+  "entry_id" bigint NOT NULL,
+  "account_id" bigint NOT NULL,
+  "treasury_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "transfer_id" bigint NOT NULL DEFAULT nextval(pg_get_serial_sequence('transfers', 'id'))
+);
+
+INSERT INTO "adjustment_transfers" ("adjustment_id", "entry_id", "account_id", "treasury_id", "currency", "amount")
+SELECT j."id", e."id", e."account_id", t."id", e."currency", e."amount"
+FROM "account_adjustments" AS j
+JOIN "entries" AS e ON e."id" = j."entry_id"
+JOIN "account" AS t ON t."owner" = 'treasury' AND t."currency" = e."currency"
+WHERE e."transfer_id" IS NULL;
+
+INSERT INTO "transfers" ("id", "from_account_id", "to_account_id", "amount", "currency", "to_amount", "to_currency")
+SELECT
+  "transfer_id",
+  CASE WHEN "amount" > 0 THEN "treasury_id" ELSE "account_id" END,
+  CASE WHEN "amount" > 0 THEN "account_id" ELSE "treasury_id" END,
+  abs("amount"),
+  "currency",
+  abs("amount"),
+  "currency"
+FROM "adjustment_transfers";
+
+UPDATE "entries" AS e
+SET "transfer_id" = a."transfer_id"
+FROM "adjustment_transfers" AS a
+WHERE e."id" = a."entry_id";
+
+INSERT INTO "entries" ("account_id", "amount", "currency", "transfer_id")
+SELECT "treasury_id", -"amount", "currency", "transfer_id" FROM "adjustment_transfers";
+
+UPDATE "account_adjustments" AS j
+SET "transfer_id" = a."transfer_id"
+FROM "adjustment_transfers" AS a
+WHERE j."id" = a."adjustment_id";
+
+UPDATE "account" AS t
+SET "balance" = t."balance" - a."total"
+FROM (
+  SELECT "treasury_id", SUM("amount") AS "total"
+  FROM "adjustment_transfers"
+  GROUP BY "treasury_id"
+) AS a
+WHERE t."id" = a."treasury_id";
+
+DROP TABLE "adjustment_transfers";
+
+ALTER TABLE "account_adjustments" ALTER COLUMN "transfer_id" SET NOT NULL;
+
+-- Balances minted before the treasury existed (welcome credits, the demo account) have
+-- no entries behind them. Book each difference as an opening transfer from the treasury.
+CREATE TEMPORARY TABLE "opening_balances" (
+  "account_id" bigint NOT NULL,
+  "treasury_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "transfer_id" bigint NOT NULL DEFAULT nextval(pg_get_serial_sequence('transfers', 'id'))
+);
+
+INSERT INTO "opening_balances" ("account_id", "treasury_id", "currency", "amount")
+SELECT a."id", t."id", a."currency", a."balance" - COALESCE(SUM(e."amount"), 0)
+FROM "account" AS a
+JOIN "account" AS t ON t."owner" = 'treasury' AND t."currency" = a."currency"
+LEFT JOIN "entries" AS e ON e."account_id" = a."id"
+WHERE a."owner" <> 'treasury'
+GROUP BY a."id", t."id"
+HAVING a."balance" <> COALESCE(SUM(e."amount"), 0);
+
+-- A negative difference is booked the other way, from the account back to the treasury
+INSERT INTO "transfers" ("id", "from_account_id", "to_account_id", "amount", "currency", "to_amount", "to_currency")
+SELECT
+  "transfer_id",
+  CASE WHEN "amount" > 0 THEN "treasury_id" ELSE "account_id" END,
+  CASE WHEN "amount" > 0 THEN "account_id" ELSE "treasury_id" END,
+  abs("amount"),
+  "currency",
+  abs("amount"),
+  "currency"
+FROM "opening_balances";
+
+INSERT INTO "entries" ("account_id", "amount", "currency", "transfer_id")
+SELECT "treasury_id", -"amount", "currency", "transfer_id" FROM "opening_balances"
+UNION ALL
+SELECT "account_id", "amount", "currency", "transfer_id" FROM "opening_balances";
+
+-- Only the treasury balances move; the accounts already hold the money
+UPDATE "account" AS t
+SET "balance" = t."balance" - o."total"
+FROM (
+  SELECT "treasury_id", SUM("amount") AS "total"
+  FROM "opening_balances"
+  GROUP BY "treasury_id"
+) AS o
+WHERE t."id" = o."treasury_id";
+
+DROP TABLE "opening_balances";
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "entries_created_at_idx";
+
+DROP TABLE IF EXISTS "ledger_discrepancies";
+
+DROP TABLE IF EXISTS "reconciliation_runs";
@@ -0,0 +1,38 @@
+-- Each reconciliation run checks the ledger rows added since the previous one and
+-- remembers how far it got, so the next run picks up where it left off
+CREATE TABLE "reconciliation_runs" (
+  "id" bigserial PRIMARY KEY,
+  -- The admin who asked for the run, or "scheduler"
+  "triggered_by" varchar NOT NULL,
+  "last_entry_id" bigint NOT NULL,
+  "last_transfer_id" bigint NOT NULL,
+  "last_account_id" bigint NOT NULL,
+  "discrepancy_count" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+-- What a run found wrong with the ledger: an account whose balance isn't the sum of its
+-- entries, or a transfer without exactly one debit and one credit entry matching it
+CREATE TABLE "ledger_discrepancies" (
+  "id" bigserial PRIMARY KEY,
+  "run_id" bigint NOT NULL,
+  "kind" varchar NOT NULL,
+  "account_id" bigint,
+  "transfer_id" bigint,
+  "expected" bigint NOT NULL,
+  "actual" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "ledger_discrepancies" ADD CONSTRAINT "ledger_discrepancies_kind_check" CHECK ("kind" IN ('account_balance', 'transfer_entry_count', 'transfer_debit', 'transfer_credit'));
+
+ALTER TABLE "ledger_discrepancies" ADD FOREIGN KEY ("run_id") REFERENCES "reconciliation_runs" ("id");
+
+ALTER TABLE "ledger_discrepancies" ADD FOREIGN KEY ("account_id") REFERENCES "account" ("id");
+
+ALTER TABLE "ledger_discrepancies" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "ledger_discrepancies" ("run_id");
+
+-- Reports scan the entries of a time window across all accounts
+CREATE INDEX "entries_created_at_idx" ON "entries" ("created_at");
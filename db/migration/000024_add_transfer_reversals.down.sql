@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "transfers_reverses_transfer_id_idx";
+
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_reversed_amount_check";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reversed_amount";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reverses_transfer_id";
@@ -0,0 +1,12 @@
+-- A reversal is a transfer back from the recipient to the sender that points at the
+-- transfer it reverses. reversed_amount is how much of to_amount has been sent back
+-- so far, which can never be more than was received.
+ALTER TABLE "transfers" ADD COLUMN "reverses_transfer_id" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "reversed_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reverses_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_reversed_amount_check" CHECK ("reversed_amount" >= 0 AND "reversed_amount" <= "to_amount");
+
+CREATE INDEX "transfers_reverses_transfer_id_idx" ON "transfers" ("reverses_transfer_id");
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS "transfers_status_transition" ON "transfers";
+
+DROP FUNCTION IF EXISTS "transfers_status_transition"();
+
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_status_check";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status";
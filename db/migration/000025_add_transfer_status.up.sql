@@ -0,0 +1,26 @@
+-- Every transfer so far was posted when it was created, or has since been reversed in full
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'posted';
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_status_check" CHECK ("status" IN ('pending', 'posted', 'failed', 'reversed'));
+
+UPDATE "transfers" SET "status" = 'reversed'
+WHERE "reverses_transfer_id" IS NULL AND "reversed_amount" = "to_amount";
+
+-- pending -> posted or failed, posted -> reversed; failed and reversed are final
+CREATE FUNCTION "transfers_status_transition"() RETURNS trigger AS $$
+BEGIN
+  IF NOT (
+    (OLD."status" = 'pending' AND NEW."status" IN ('posted', 'failed')) OR
+    (OLD."status" = 'posted' AND NEW."status" = 'reversed')
+  ) THEN
+    RAISE EXCEPTION 'transfer % cannot go from % to %', OLD."id", OLD."status", NEW."status";
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "transfers_status_transition"
+  BEFORE UPDATE OF "status" ON "transfers"
+  FOR EACH ROW
+  WHEN (OLD."status" IS DISTINCT FROM NEW."status")
+  EXECUTE FUNCTION "transfers_status_transition"();
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "transfers_pending_hold_expires_at_idx";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "hold_expires_at";
+
+ALTER TABLE "account" DROP CONSTRAINT IF EXISTS "account_held_amount_check";
+
+ALTER TABLE "account" DROP COLUMN IF EXISTS "held_amount";
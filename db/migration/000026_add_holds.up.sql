@@ -0,0 +1,10 @@
+-- A hold is a pending transfer with an expiry. Until it is captured or released its
+-- amount is reserved on the from account, so it can't be spent by other transfers.
+ALTER TABLE "account" ADD COLUMN "held_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "account" ADD CONSTRAINT "account_held_amount_check" CHECK ("held_amount" >= 0);
+
+ALTER TABLE "transfers" ADD COLUMN "hold_expires_at" timestamptz;
+
+CREATE INDEX "transfers_pending_hold_expires_at_idx" ON "transfers" ("hold_expires_at")
+WHERE "status" = 'pending' AND "hold_expires_at" IS NOT NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "pending_transfers_username_idempotency_key_idx";
@@ -0,0 +1,15 @@
+-- A retried transfer with the same idempotency key returns the transfer already held
+-- for confirmation instead of holding another one. Earlier duplicates keep only the
+-- first pending transfer under the key.
+UPDATE "pending_transfers" AS p
+SET "idempotency_key" = ''
+WHERE "idempotency_key" <> ''
+  AND EXISTS (
+    SELECT 1 FROM "pending_transfers" AS first
+    WHERE first."username" = p."username"
+      AND first."idempotency_key" = p."idempotency_key"
+      AND first."id" < p."id"
+  );
+
+CREATE UNIQUE INDEX "pending_transfers_username_idempotency_key_idx" ON "pending_transfers" ("username", "idempotency_key")
+WHERE "idempotency_key" <> '';
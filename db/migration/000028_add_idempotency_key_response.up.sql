@@ -0,0 +1,3 @@
+-- The response a transfer returned, kept with its idempotency key so a retry gets
+-- back exactly the same response. Keys used before this column existed hold null.
+ALTER TABLE "idempotency_keys" ADD COLUMN "response" json NOT NULL DEFAULT 'null';
@@ -0,0 +1,28 @@
+DELETE FROM "ledger_discrepancies" WHERE "kind" = 'currency_imbalance';
+
+ALTER TABLE "ledger_discrepancies" DROP CONSTRAINT IF EXISTS "ledger_discrepancies_kind_check";
+
+ALTER TABLE "ledger_discrepancies" ADD CONSTRAINT "ledger_discrepancies_kind_check" CHECK ("kind" IN ('account_balance', 'transfer_entry_count', 'transfer_debit', 'transfer_credit'));
+
+-- The treasury entries of cross-currency transfers are their exchange entries
+CREATE TEMPORARY TABLE "exchange_entries" AS
+SELECT e."id", e."account_id", e."amount"
+FROM "entries" AS e
+JOIN "transfers" AS t ON t."id" = e."transfer_id"
+JOIN "account" AS r ON r."id" = e."account_id"
+WHERE t."currency" <> t."to_currency"
+  AND r."owner" = 'treasury'
+  AND e."account_id" NOT IN (t."from_account_id", t."to_account_id");
+
+UPDATE "account" AS r
+SET "balance" = r."balance" - x."total"
+FROM (
+  SELECT "account_id", SUM("amount") AS "total"
+  FROM "exchange_entries"
+  GROUP BY "account_id"
+) AS x
+WHERE r."id" = x."account_id";
+
+DELETE FROM "entries" WHERE "id" IN (SELECT "id" FROM "exchange_entries");
+
+DROP TABLE "exchange_entries";
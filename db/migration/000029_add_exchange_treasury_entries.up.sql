@@ -0,0 +1,41 @@
+-- A cross-currency transfer is exchanged through the treasury: its account in the
+-- transfer's currency takes the amount debited and its account in the to currency pays
+-- the amount credited, keeping the spread. The entries of each currency then sum to zero.
+-- Give the cross-currency transfers posted before this their two treasury entries.
+CREATE TEMPORARY TABLE "exchange_entries" (
+  "treasury_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "transfer_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL
+);
+
+INSERT INTO "exchange_entries" ("treasury_id", "amount", "currency", "transfer_id", "created_at")
+SELECT r."id", t."amount", t."currency", t."id", t."created_at"
+FROM "transfers" AS t
+JOIN "account" AS r ON r."owner" = 'treasury' AND r."currency" = t."currency"
+WHERE t."currency" <> t."to_currency" AND t."status" IN ('posted', 'reversed')
+UNION ALL
+SELECT r."id", -t."to_amount", t."to_currency", t."id", t."created_at"
+FROM "transfers" AS t
+JOIN "account" AS r ON r."owner" = 'treasury' AND r."currency" = t."to_currency"
+WHERE t."currency" <> t."to_currency" AND t."status" IN ('posted', 'reversed');
+
+INSERT INTO "entries" ("account_id", "amount", "currency", "transfer_id", "created_at")
+SELECT "treasury_id", "amount", "currency", "transfer_id", "created_at" FROM "exchange_entries";
+
+UPDATE "account" AS r
+SET "balance" = r."balance" + x."total"
+FROM (
+  SELECT "treasury_id", SUM("amount") AS "total"
+  FROM "exchange_entries"
+  GROUP BY "treasury_id"
+) AS x
+WHERE r."id" = x."treasury_id";
+
+DROP TABLE "exchange_entries";
+
+-- Reconciliation checks that the entries of each currency sum to zero
+ALTER TABLE "ledger_discrepancies" DROP CONSTRAINT "ledger_discrepancies_kind_check";
+
+ALTER TABLE "ledger_discrepancies" ADD CONSTRAINT "ledger_discrepancies_kind_check" CHECK ("kind" IN ('account_balance', 'transfer_entry_count', 'transfer_debit', 'transfer_credit', 'currency_imbalance'));
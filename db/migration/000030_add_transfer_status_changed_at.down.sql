@@ -0,0 +1,3 @@
+ALTER TABLE "reconciliation_runs" DROP COLUMN IF EXISTS "checked_until";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "status_changed_at";
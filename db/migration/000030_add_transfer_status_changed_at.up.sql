@@ -0,0 +1,17 @@
+-- Reconciliation checks a transfer again when its status changes after it was made
+ALTER TABLE "transfers" ADD COLUMN "status_changed_at" timestamptz;
+
+UPDATE "transfers" SET "status_changed_at" = "created_at";
+
+ALTER TABLE "transfers" ALTER COLUMN "status_changed_at" SET NOT NULL;
+
+ALTER TABLE "transfers" ALTER COLUMN "status_changed_at" SET DEFAULT (now());
+
+CREATE INDEX ON "transfers" ("status_changed_at");
+
+-- How far each run has looked at status changes
+ALTER TABLE "reconciliation_runs" ADD COLUMN "checked_until" timestamptz;
+
+UPDATE "reconciliation_runs" SET "checked_until" = "created_at";
+
+ALTER TABLE "reconciliation_runs" ALTER COLUMN "checked_until" SET NOT NULL;
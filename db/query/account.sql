@@ -14,18 +14,22 @@ WHERE id = $1 LIMIT 1;
 SELECT * FROM account
 WHERE account_number = $1 LIMIT 1;
 
+-- name: GetAccountForUpdate :one
 SELECT * FROM account
 WHERE id = $1 LIMIT 1
-FOR UPDATE;
-
+FOR NO KEY UPDATE;
 
+-- name: GetTreasuryAccount :one
+SELECT * FROM account
+WHERE owner = 'treasury' AND currency = $1 LIMIT 1;
 
 -- name: ListAccounts :many
 SELECT * FROM account
-WHERE owner = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3;
+WHERE owner = sqlc.arg(owner)
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
 
 -- name: UpdateAccount :exec
 UPDATE account
@@ -41,3 +45,27 @@ UPDATE account
 SET balance = balance + $2
 WHERE id = $1
 RETURNING *;
+
+-- name: AddAccountHeldAmount :one
+UPDATE account
+SET held_amount = held_amount + $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateAccountOverdraftLimit :one
+UPDATE account
+SET overdraft_limit = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateAccountStatus :one
+UPDATE account
+SET status = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: CloseAccount :one
+UPDATE account
+SET status = 'closed', closed_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,18 @@
+-- name: CreateAccountAdjustment :one
+INSERT INTO account_adjustments (
+  account_id,
+  entry_id,
+  transfer_id,
+  amount,
+  reason,
+  created_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListAccountAdjustments :many
+SELECT * FROM account_adjustments
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
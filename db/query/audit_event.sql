@@ -0,0 +1,36 @@
+-- name: LockAuditChain :exec
+-- Serializes appends to the audit log until the end of the transaction
+SELECT pg_advisory_xact_lock(hashtext('audit_events'));
+
+-- name: GetLastAuditEvent :one
+SELECT * FROM audit_events
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  username,
+  actor,
+  event_type,
+  details,
+  client_ip,
+  user_agent,
+  created_at,
+  prev_hash,
+  hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: ListUserAuditEvents :many
+SELECT * FROM audit_events
+WHERE username = sqlc.arg(username)
+  AND (sqlc.narg(before_id)::bigint IS NULL OR id < sqlc.narg(before_id))
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
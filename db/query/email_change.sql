@@ -0,0 +1,20 @@
+-- name: CreateEmailChange :one
+INSERT INTO email_changes (
+    username,
+    old_email,
+    new_email,
+    secret_hash
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UseEmailChange :one
+UPDATE email_changes
+SET
+    is_used = TRUE
+WHERE
+    id = @id
+    AND secret_hash = @secret_hash
+    AND is_used = FALSE
+    AND expired_at > now()
+RETURNING *;
@@ -1,9 +1,11 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  currency,
+  transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -12,7 +14,56 @@ WHERE id = $1 LIMIT 1;
 
 -- name: ListEntries :many
 SELECT * FROM entries
-WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3;
\ No newline at end of file
+WHERE account_id = sqlc.arg(account_id)
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListAccountStatementEntries :many
+WITH page AS (
+  SELECT id, account_id, amount, created_at, currency, transfer_id
+  FROM entries
+  WHERE account_id = sqlc.arg(account_id)
+    AND created_at >= sqlc.arg(start_time)
+    AND created_at < sqlc.arg(end_time)
+    AND (sqlc.narg(after_created_at)::timestamptz IS NULL OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint))
+  ORDER BY created_at, id
+  LIMIT sqlc.arg('limit')
+  OFFSET sqlc.arg('offset')
+), opening AS (
+  -- The balance before the page is the current balance less every entry from its first on,
+  -- so only the page goes through the running total
+  SELECT a.balance - COALESCE((
+    SELECT SUM(e.amount) FROM entries AS e
+    WHERE e.account_id = a.id
+      AND (e.created_at, e.id) >= (SELECT p.created_at, p.id FROM page AS p ORDER BY p.created_at, p.id LIMIT 1)
+  ), 0) AS balance
+  FROM account AS a
+  WHERE a.id = sqlc.arg(account_id)
+)
+SELECT
+  p.id, p.account_id, p.amount, p.created_at, p.currency, p.transfer_id,
+  (o.balance + SUM(p.amount) OVER (ORDER BY p.created_at, p.id ROWS UNBOUNDED PRECEDING))::bigint AS running_balance,
+  COALESCE(c.id, 0)::bigint AS counterparty_account_id,
+  COALESCE(c.account_number, '')::varchar AS counterparty_account_number
+FROM page AS p
+CROSS JOIN opening AS o
+LEFT JOIN transfers AS t ON t.id = p.transfer_id
+LEFT JOIN account AS c ON c.id = CASE WHEN t.from_account_id = p.account_id THEN t.to_account_id ELSE t.from_account_id END
+ORDER BY p.created_at, p.id;
+
+-- name: GetAccountStatementBalances :one
+SELECT
+  (a.balance - COALESCE(SUM(e.amount) FILTER (WHERE e.created_at >= sqlc.arg(start_time)), 0))::bigint AS opening_balance,
+  (a.balance - COALESCE(SUM(e.amount) FILTER (WHERE e.created_at >= sqlc.arg(end_time)), 0))::bigint AS closing_balance
+FROM account AS a
+LEFT JOIN entries AS e ON e.account_id = a.id
+WHERE a.id = sqlc.arg(account_id)
+GROUP BY a.id;
+
+-- name: CountAccountStatementEntries :one
+SELECT COUNT(*) FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(start_time)
+  AND created_at < sqlc.arg(end_time);
@@ -0,0 +1,45 @@
+-- name: GetLoginFailure :one
+SELECT * FROM login_failures
+WHERE scope = $1 AND key = $2
+LIMIT 1;
+
+-- name: CreateLoginFailure :exec
+-- Starts counting failures for a key unless it already has a count
+INSERT INTO login_failures (
+  scope,
+  key
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (scope, key) DO NOTHING;
+
+-- name: GetLoginFailureForUpdate :one
+SELECT * FROM login_failures
+WHERE scope = $1 AND key = $2
+LIMIT 1
+FOR UPDATE;
+
+-- name: UpdateLoginFailure :one
+UPDATE login_failures
+SET
+  failure_count = $3,
+  last_failed_at = $4,
+  locked_until = $5
+WHERE scope = $1 AND key = $2
+RETURNING *;
+
+-- name: ReleaseLoginAttempt :exec
+-- Puts back a key's failures from before an attempt that succeeded, unless
+-- another attempt has been reserved against the key since
+UPDATE login_failures
+SET
+  failure_count = sqlc.arg(failure_count),
+  last_failed_at = sqlc.arg(last_failed_at),
+  locked_until = sqlc.arg(locked_until)
+WHERE scope = sqlc.arg(scope)
+  AND key = sqlc.arg(key)
+  AND locked_until = sqlc.arg(reserved_until);
+
+-- name: DeleteLoginFailure :exec
+DELETE FROM login_failures
+WHERE scope = $1 AND key = $2;
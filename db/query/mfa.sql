@@ -0,0 +1,60 @@
+-- name: StartUserMFAEnrollment :one
+-- Replaces an unconfirmed secret; returns no rows if MFA is already enabled
+INSERT INTO user_mfa (
+  username,
+  totp_secret
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (username) DO UPDATE
+SET
+  totp_secret = EXCLUDED.totp_secret,
+  last_used_step = 0,
+  created_at = now()
+WHERE user_mfa.enabled_at IS NULL
+RETURNING *;
+
+-- name: GetUserMFA :one
+SELECT * FROM user_mfa
+WHERE username = $1
+LIMIT 1;
+
+-- name: EnableUserMFA :one
+UPDATE user_mfa
+SET
+  enabled_at = now(),
+  last_used_step = $2
+WHERE username = $1
+  AND enabled_at IS NULL
+RETURNING *;
+
+-- name: UseTOTPStep :one
+-- Returns no rows if a code for this step or a later one was already used
+UPDATE user_mfa
+SET last_used_step = $2
+WHERE username = $1
+  AND enabled_at IS NOT NULL
+  AND last_used_step < $2
+RETURNING *;
+
+-- name: CreateMFARecoveryCode :one
+INSERT INTO mfa_recovery_codes (
+  username,
+  code_hash
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: DeleteMFARecoveryCodes :exec
+DELETE FROM mfa_recovery_codes
+WHERE username = $1;
+
+-- name: UseMFARecoveryCode :one
+-- Returns no rows if the code doesn't exist or was already used
+UPDATE mfa_recovery_codes
+SET used_at = now()
+WHERE username = $1
+  AND code_hash = $2
+  AND used_at IS NULL
+RETURNING *;
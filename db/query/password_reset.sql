@@ -0,0 +1,20 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (
+    username,
+    email,
+    secret_hash,
+    expired_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: UsePasswordReset :one
+UPDATE password_resets
+SET
+    is_used = TRUE
+WHERE
+    id = @id
+    AND secret_hash = @secret_hash
+    AND is_used = FALSE
+    AND expired_at > now()
+RETURNING *;
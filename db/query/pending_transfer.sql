@@ -0,0 +1,49 @@
+-- name: CreatePendingTransfer :one
+-- Returns no rows if the user already has a pending transfer with the idempotency key
+INSERT INTO pending_transfers (
+  username,
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  to_amount,
+  to_currency,
+  exchange_rate,
+  spread_bps,
+  idempotency_key,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+ON CONFLICT (username, idempotency_key) WHERE idempotency_key <> '' DO NOTHING
+RETURNING *;
+
+-- name: GetPendingTransfer :one
+SELECT * FROM pending_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPendingTransferByIdempotencyKey :one
+SELECT * FROM pending_transfers
+WHERE username = $1 AND idempotency_key = $2 LIMIT 1;
+
+-- name: GetPendingTransferForUpdate :one
+SELECT * FROM pending_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ConfirmPendingTransfer :one
+UPDATE pending_transfers
+SET
+  status = 'confirmed',
+  transfer_id = $2,
+  confirmed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ExpirePendingTransfer :one
+-- Returns no rows if the transfer was already confirmed or expired
+UPDATE pending_transfers
+SET status = 'expired'
+WHERE id = $1
+  AND status = 'pending'
+RETURNING *;
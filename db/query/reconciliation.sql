@@ -0,0 +1,85 @@
+-- name: LockLedgerReconciliation :exec
+-- Serializes reconciliation runs until the end of the transaction
+SELECT pg_advisory_xact_lock(hashtext('ledger_reconciliation'));
+
+-- name: GetLastReconciliationRun :one
+SELECT * FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetLedgerHighWater :one
+SELECT
+  (SELECT COALESCE(MAX(id), 0) FROM entries WHERE created_at < sqlc.arg(created_before))::bigint AS last_entry_id,
+  (SELECT COALESCE(MAX(id), 0) FROM transfers WHERE created_at < sqlc.arg(created_before))::bigint AS last_transfer_id,
+  (SELECT COALESCE(MAX(id), 0) FROM account WHERE created_at < sqlc.arg(created_before))::bigint AS last_account_id;
+
+-- name: ListAccountBalanceMismatches :many
+SELECT a.id, a.currency, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM account AS a
+LEFT JOIN entries AS e ON e.account_id = a.id
+WHERE a.id IN (
+    SELECT n.account_id FROM entries AS n
+    WHERE n.id > sqlc.arg(after_entry_id) AND n.id <= sqlc.arg(last_entry_id)
+      AND n.created_at >= sqlc.arg(created_from) AND n.created_at < sqlc.arg(created_before)
+  )
+  OR (
+    a.id > sqlc.arg(after_account_id) AND a.id <= sqlc.arg(last_account_id)
+      AND a.created_at >= sqlc.arg(created_from) AND a.created_at < sqlc.arg(created_before)
+  )
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListTransferEntryMismatches :many
+SELECT
+  t.id, t.from_account_id, t.to_account_id, t.amount, t.currency, t.to_amount, t.to_currency, t.status,
+  COUNT(e.id) AS entry_count,
+  COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id), 0)::bigint AS debited,
+  COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id), 0)::bigint AS credited
+FROM transfers AS t
+LEFT JOIN entries AS e ON e.transfer_id = t.id
+WHERE (
+    t.id > sqlc.arg(after_transfer_id) AND t.id <= sqlc.arg(last_transfer_id)
+      AND t.created_at >= sqlc.arg(created_from) AND t.created_at < sqlc.arg(created_before)
+  )
+  OR t.id IN (
+    SELECT n.transfer_id FROM entries AS n
+    WHERE n.id > sqlc.arg(after_entry_id) AND n.id <= sqlc.arg(last_entry_id)
+      AND n.created_at >= sqlc.arg(created_from) AND n.created_at < sqlc.arg(created_before)
+  )
+  OR (t.status_changed_at >= sqlc.arg(status_changed_from) AND t.status_changed_at < sqlc.arg(created_before))
+GROUP BY t.id
+HAVING (t.status IN ('posted', 'reversed') AND (
+    COUNT(e.id) <> CASE WHEN t.currency = t.to_currency THEN 2 ELSE 4 END
+    OR COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id), 0) <> -t.amount
+    OR COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id), 0) <> t.to_amount
+  ))
+  OR (t.status IN ('pending', 'failed') AND COUNT(e.id) <> 0)
+ORDER BY t.id;
+
+-- name: ListCurrencyImbalances :many
+-- Every transfer debits as much as it credits in each currency, through the treasury
+-- when it converts, so the entries of each currency sum to zero
+SELECT e.currency, r.id AS treasury_account_id, SUM(e.amount)::bigint AS total
+FROM entries AS e
+LEFT JOIN account AS r ON r.owner = 'treasury' AND r.currency = e.currency
+WHERE e.id <= sqlc.arg(last_entry_id) AND e.created_at < sqlc.arg(created_before)
+GROUP BY e.currency, r.id
+HAVING SUM(e.amount) <> 0
+ORDER BY e.currency;
+
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (
+  triggered_by, last_entry_id, last_transfer_id, last_account_id, discrepancy_count, checked_until
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: CreateLedgerDiscrepancy :one
+INSERT INTO ledger_discrepancies (
+  run_id, kind, account_id, transfer_id, expected, actual
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
@@ -1,11 +1,54 @@
 -- name: CreateSession :one
 INSERT INTO sessions(
-  id, username, refresh_token, user_agent, client_ip, is_boolean, expires_at
+  id, username, refresh_token, user_agent, client_ip, is_boolean, expires_at, family_id
 ) VALUES (
-  $1, $2, $3, $4, $5, $6, $7
+  $1, $2, $3, $4, $5, $6, $7, $8
 )
 RETURNING *;
 
 -- name: GetSession :one
 SELECT * FROM sessions 
 WHERE id = $1 LIMIT 1;
+
+-- name: GetSessionForUpdate :one
+SELECT * FROM sessions
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListActiveSessions :many
+SELECT * FROM sessions
+WHERE username = $1
+  AND is_boolean = false
+  AND replaced_by IS NULL
+  AND expires_at > now()
+ORDER BY created_at DESC;
+
+-- name: BlockSession :one
+UPDATE sessions
+SET is_boolean = true
+WHERE id = $1 AND username = $2
+RETURNING *;
+
+-- name: BlockOtherSessions :execrows
+UPDATE sessions
+SET is_boolean = true
+WHERE username = $1
+  AND id <> $2
+  AND is_boolean = false;
+
+-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_boolean = true
+WHERE username = $1
+  AND is_boolean = false;
+
+-- name: SetSessionReplacedBy :exec
+UPDATE sessions
+SET replaced_by = $2
+WHERE id = $1;
+
+-- name: BlockSessionFamily :execrows
+UPDATE sessions
+SET is_boolean = true
+WHERE family_id = $1
+  AND is_boolean = false;
@@ -2,20 +2,100 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  currency,
+  to_amount,
+  to_currency,
+  exchange_rate,
+  spread_bps,
+  reverses_transfer_id,
+  status,
+  hold_expires_at
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = sqlc.arg(status), status_changed_at = now()
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 AND hold_expires_at IS NOT NULL LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateHoldAmount :one
+UPDATE transfers
+SET amount = $2, to_amount = $3
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
+
+-- name: ListExpiredHolds :many
+SELECT id FROM transfers
+WHERE status = 'pending' AND hold_expires_at <= sqlc.arg(expired_before)
+ORDER BY hold_expires_at
+LIMIT sqlc.arg('limit');
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
-WHERE 
-    from_account_id = $1 OR
-    to_account_id = $2
+WHERE
+    from_account_id = sqlc.arg(account_id) OR
+    to_account_id = sqlc.arg(account_id)
 ORDER BY id
-LIMIT $3
-OFFSET $4;
\ No newline at end of file
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListUserTransfers :many
+SELECT t.* FROM transfers AS t
+WHERE EXISTS (
+    SELECT 1 FROM account AS a
+    WHERE a.owner = sqlc.arg(owner)
+      AND (sqlc.narg(account_id)::bigint IS NULL OR a.id = sqlc.narg(account_id))
+      AND (
+        (a.id = t.from_account_id AND COALESCE(sqlc.narg(direction)::varchar, 'out') = 'out') OR
+        (a.id = t.to_account_id AND COALESCE(sqlc.narg(direction)::varchar, 'in') = 'in')
+      )
+  )
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(status)::varchar IS NULL OR t.status = sqlc.narg(status))
+  AND (sqlc.narg(before_created_at)::timestamptz IS NULL OR (t.created_at, t.id) < (sqlc.narg(before_created_at), sqlc.narg(before_id)::bigint))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: CountUserTransfers :one
+SELECT COUNT(*) FROM transfers AS t
+WHERE EXISTS (
+    SELECT 1 FROM account AS a
+    WHERE a.owner = sqlc.arg(owner)
+      AND (sqlc.narg(account_id)::bigint IS NULL OR a.id = sqlc.narg(account_id))
+      AND (
+        (a.id = t.from_account_id AND COALESCE(sqlc.narg(direction)::varchar, 'out') = 'out') OR
+        (a.id = t.to_account_id AND COALESCE(sqlc.narg(direction)::varchar, 'in') = 'in')
+      )
+  )
+  AND (sqlc.narg(start_time)::timestamptz IS NULL OR t.created_at >= sqlc.narg(start_time))
+  AND (sqlc.narg(end_time)::timestamptz IS NULL OR t.created_at < sqlc.narg(end_time))
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR t.amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR t.amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(status)::varchar IS NULL OR t.status = sqlc.narg(status));
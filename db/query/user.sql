@@ -12,6 +12,10 @@ INSERT INTO users (
 SELECT * FROM users
 WHERE username = $1 LIMIT 1;
 
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
 -- name: UpdateUser :one
 UPDATE users
 SET
@@ -19,7 +23,17 @@ SET
   password_changed_at = COALESCE(sqlc.narg(password_changed_at), password_changed_at),
   full_name = COALESCE(sqlc.narg(full_name), full_name),
   email = COALESCE(sqlc.narg(email), email),
-  is_email_verified = COALESCE(sqlc.narg(is_email_verified), is_email_verified)
+  is_email_verified = COALESCE(sqlc.narg(is_email_verified), is_email_verified),
+  transfer_confirmation_threshold = COALESCE(sqlc.narg(transfer_confirmation_threshold), transfer_confirmation_threshold)
 WHERE
   username = sqlc.arg(username)
 RETURNING *;
+
+-- name: SearchUsers :many
+-- The query is matched literally, so % and _ in it aren't wildcards
+SELECT * FROM users
+WHERE username ILIKE '%' || replace(replace(replace(sqlc.arg(query)::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+   OR email ILIKE '%' || replace(replace(replace(sqlc.arg(query)::text, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+ORDER BY username
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
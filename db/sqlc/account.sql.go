@@ -14,7 +14,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE account
 SET balance = balance + $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at, account_number
+RETURNING id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount
 `
 
 type AddAccountBalanceParams struct {
@@ -32,6 +32,65 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Currency,
 		&i.CreatedAt,
 		&i.AccountNumber,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.ClosedAt,
+		&i.HeldAmount,
+	)
+	return i, err
+}
+
+const addAccountHeldAmount = `-- name: AddAccountHeldAmount :one
+UPDATE account
+SET held_amount = held_amount + $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount
+`
+
+type AddAccountHeldAmountParams struct {
+	ID         int64 `json:"id"`
+	HeldAmount int64 `json:"held_amount"`
+}
+
+func (q *Queries) AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountHeldAmount, arg.ID, arg.HeldAmount)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.ClosedAt,
+		&i.HeldAmount,
+	)
+	return i, err
+}
+
+const closeAccount = `-- name: CloseAccount :one
+UPDATE account
+SET status = 'closed', closed_at = now()
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount
+`
+
+func (q *Queries) CloseAccount(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, closeAccount, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.ClosedAt,
+		&i.HeldAmount,
 	)
 	return i, err
 }
@@ -42,7 +101,7 @@ INSERT INTO account (
 ) VALUES (
   $1, $2, $3, $4
 )
-RETURNING id, owner, balance, currency, created_at, account_number
+RETURNING id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount
 `
 
 type CreateAccountParams struct {
@@ -67,6 +126,10 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Currency,
 		&i.CreatedAt,
 		&i.AccountNumber,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.ClosedAt,
+		&i.HeldAmount,
 	)
 	return i, err
 }
@@ -82,7 +145,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at, account_number FROM account
+SELECT id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount FROM account
 WHERE id = $1 LIMIT 1
 `
 
@@ -96,12 +159,16 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Currency,
 		&i.CreatedAt,
 		&i.AccountNumber,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.ClosedAt,
+		&i.HeldAmount,
 	)
 	return i, err
 }
 
 const getAccountByNumber = `-- name: GetAccountByNumber :one
-SELECT id, owner, balance, currency, created_at, account_number FROM account
+SELECT id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount FROM account
 WHERE account_number = $1 LIMIT 1
 `
 
@@ -115,26 +182,86 @@ func (q *Queries) GetAccountByNumber(ctx context.Context, accountNumber sql.Null
 		&i.Currency,
 		&i.CreatedAt,
 		&i.AccountNumber,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.ClosedAt,
+		&i.HeldAmount,
+	)
+	return i, err
+}
+
+const getAccountForUpdate = `-- name: GetAccountForUpdate :one
+SELECT id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount FROM account
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountForUpdate, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.ClosedAt,
+		&i.HeldAmount,
+	)
+	return i, err
+}
+
+const getTreasuryAccount = `-- name: GetTreasuryAccount :one
+SELECT id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount FROM account
+WHERE owner = 'treasury' AND currency = $1 LIMIT 1
+`
+
+func (q *Queries) GetTreasuryAccount(ctx context.Context, currency string) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getTreasuryAccount, currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.ClosedAt,
+		&i.HeldAmount,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at, account_number FROM account
+SELECT id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount FROM account
 WHERE owner = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3
+  AND ($2::timestamptz IS NULL OR (created_at, id) > ($2, $3::bigint))
+ORDER BY created_at, id
+LIMIT $4
+OFFSET $5
 `
 
 type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Owner          string        `json:"owner"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+	Limit          int32         `json:"limit"`
+	Offset         int32         `json:"offset"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts,
+		arg.Owner,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -149,6 +276,10 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Currency,
 			&i.CreatedAt,
 			&i.AccountNumber,
+			&i.OverdraftLimit,
+			&i.Status,
+			&i.ClosedAt,
+			&i.HeldAmount,
 		); err != nil {
 			return nil, err
 		}
@@ -178,3 +309,63 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) er
 	_, err := q.db.ExecContext(ctx, updateAccount, arg.ID, arg.Balance)
 	return err
 }
+
+const updateAccountOverdraftLimit = `-- name: UpdateAccountOverdraftLimit :one
+UPDATE account
+SET overdraft_limit = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount
+`
+
+type UpdateAccountOverdraftLimitParams struct {
+	ID             int64 `json:"id"`
+	OverdraftLimit int64 `json:"overdraft_limit"`
+}
+
+func (q *Queries) UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountOverdraftLimit, arg.ID, arg.OverdraftLimit)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.ClosedAt,
+		&i.HeldAmount,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE account
+SET status = $2
+WHERE id = $1
+RETURNING id, owner, balance, currency, created_at, account_number, overdraft_limit, status, closed_at, held_amount
+`
+
+type UpdateAccountStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.ID, arg.Status)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.AccountNumber,
+		&i.OverdraftLimit,
+		&i.Status,
+		&i.ClosedAt,
+		&i.HeldAmount,
+	)
+	return i, err
+}
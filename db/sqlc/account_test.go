@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 
 	"github.com/OmSingh2003/nimbus/util"
@@ -9,13 +10,17 @@ import (
 )
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountWithCurrency(t, util.RandomCurrency())
+}
+
+func createRandomAccountWithCurrency(t *testing.T, currency string) Account {
 	// Create a user first (required due to foreign key constraint)
 	user := createRandomUser(t)
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Currency: currency,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -55,3 +60,34 @@ func TestListAccounts(t *testing.T) {
 		require.Equal(t,lastAccount.Owner,account.Owner)
 	}
 }
+
+func TestListAccountsKeyset(t *testing.T) {
+	user := createRandomUser(t)
+	for _, currency := range []string{util.USD, util.EUR, util.INR} {
+		_, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  util.RandomMoney(),
+			Currency: currency,
+		})
+		require.NoError(t, err)
+	}
+
+	firstPage, err := testQueries.ListAccounts(context.Background(), ListAccountsParams{
+		Owner: user.Username,
+		Limit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, firstPage, 2)
+
+	// Resume after the last row of the first page
+	last := firstPage[len(firstPage)-1]
+	secondPage, err := testQueries.ListAccounts(context.Background(), ListAccountsParams{
+		Owner:          user.Username,
+		AfterCreatedAt: sql.NullTime{Time: last.CreatedAt, Valid: true},
+		AfterID:        sql.NullInt64{Int64: last.ID, Valid: true},
+		Limit:          2,
+	})
+	require.NoError(t, err)
+	require.Len(t, secondPage, 1)
+	require.NotContains(t, firstPage, secondPage[0])
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Types of audit events
+const (
+	AuditLogin            = "login"
+	AuditLoginFailed      = "login_failed"
+	AuditUserUpdated      = "user_updated"
+	AuditPasswordChanged  = "password_changed"
+	AuditPasswordReset    = "password_reset"
+	AuditEmailChanged     = "email_changed"
+	AuditAccountCreated   = "account_created"
+	AuditAccountClosed    = "account_closed"
+	AuditTransferCreated  = "transfer_created"
+	AuditTransferReversed = "transfer_reversed"
+	AuditHoldCreated      = "hold_created"
+	AuditHoldCaptured     = "hold_captured"
+	AuditHoldReleased     = "hold_released"
+	AuditHoldExpired      = "hold_expired"
+	AuditAccountFrozen    = "account_frozen"
+	AuditAccountUnfrozen  = "account_unfrozen"
+	AuditBalanceAdjusted  = "balance_adjusted"
+	AuditMFAEnabled       = "mfa_enabled"
+	AuditStepUpFailed     = "step_up_failed"
+	AuditOverdraftChanged = "overdraft_limit_changed"
+)
+
+// ErrAuditChainBroken is returned by VerifyAuditChain when an event doesn't hash to
+// what is stored or doesn't follow the event before it.
+var ErrAuditChainBroken = errors.New("audit chain is broken")
+
+// AuditContext identifies who made a change and where the request came from.
+// Actor defaults to the user the event is about.
+type AuditContext struct {
+	Actor     string `json:"actor"`
+	ClientIP  string `json:"client_ip"`
+	UserAgent string `json:"user_agent"`
+}
+
+// AuditEventParams describes an event to append to the audit log
+type AuditEventParams struct {
+	// Username is the user the event shows up for in their security log
+	Username  string         `json:"username"`
+	EventType string         `json:"event_type"`
+	Details   map[string]any `json:"details"`
+	Audit     AuditContext   `json:"audit"`
+}
+
+// RecordAuditEventTx appends a single event to the audit log. Changes that are
+// already made in a transaction record their event in that transaction instead.
+func (store *SQLStore) RecordAuditEventTx(ctx context.Context, arg AuditEventParams) (AuditEvent, error) {
+	var event AuditEvent
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		event, err = appendAuditEvent(ctx, q, arg)
+		return err
+	})
+
+	return event, err
+}
+
+// appendAuditEvent chains a new event onto the last one in the log. It holds a
+// transaction-wide lock so concurrent appends can't fork the chain, so callers
+// should append as the last step of their transaction.
+func appendAuditEvent(ctx context.Context, q *Queries, arg AuditEventParams) (AuditEvent, error) {
+	err := q.LockAuditChain(ctx)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+
+	var prevHash string
+	last, err := q.GetLastAuditEvent(ctx)
+	if err == nil {
+		prevHash = last.Hash
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return AuditEvent{}, err
+	}
+
+	if arg.Details == nil {
+		arg.Details = map[string]any{}
+	}
+	details, err := json.Marshal(arg.Details)
+	if err != nil {
+		return AuditEvent{}, fmt.Errorf("cannot encode audit event details: %w", err)
+	}
+
+	actor := arg.Audit.Actor
+	if actor == "" {
+		actor = arg.Username
+	}
+
+	event := AuditEvent{
+		Username:  arg.Username,
+		Actor:     actor,
+		EventType: arg.EventType,
+		Details:   details,
+		ClientIp:  arg.Audit.ClientIP,
+		UserAgent: arg.Audit.UserAgent,
+		// Postgres keeps microseconds, so drop the rest before hashing
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		PrevHash:  prevHash,
+	}
+	event.Hash = HashAuditEvent(event)
+
+	return q.CreateAuditEvent(ctx, CreateAuditEventParams{
+		Username:  event.Username,
+		Actor:     event.Actor,
+		EventType: event.EventType,
+		Details:   event.Details,
+		ClientIp:  event.ClientIp,
+		UserAgent: event.UserAgent,
+		CreatedAt: event.CreatedAt,
+		PrevHash:  event.PrevHash,
+		Hash:      event.Hash,
+	})
+}
+
+// HashAuditEvent returns the hash of an event's contents and the hash of the event before it.
+// The ID isn't covered, since it is only known after the insert.
+func HashAuditEvent(event AuditEvent) string {
+	fields, _ := json.Marshal([]string{
+		event.PrevHash,
+		event.Username,
+		event.Actor,
+		event.EventType,
+		string(event.Details),
+		event.ClientIp,
+		event.UserAgent,
+		event.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAuditChain checks that events, in ID order, each follow prevHash or the event
+// before them and hash to what is stored. It returns the hash of the last event so a
+// long log can be checked a page at a time.
+func VerifyAuditChain(prevHash string, events []AuditEvent) (string, error) {
+	for _, event := range events {
+		if event.PrevHash != prevHash {
+			return prevHash, fmt.Errorf("%w: event %d doesn't follow the event before it", ErrAuditChainBroken, event.ID)
+		}
+		if HashAuditEvent(event) != event.Hash {
+			return prevHash, fmt.Errorf("%w: event %d was modified", ErrAuditChainBroken, event.ID)
+		}
+		prevHash = event.Hash
+	}
+	return prevHash, nil
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAuditEventTx(t *testing.T) {
+	user := createRandomUser(t)
+
+	event1, err := testStore.RecordAuditEventTx(context.Background(), AuditEventParams{
+		Username:  user.Username,
+		EventType: AuditLoginFailed,
+		Details:   map[string]any{"reason": "incorrect_password"},
+		Audit:     AuditContext{ClientIP: "10.0.0.1", UserAgent: "test"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, event1.Actor)
+	require.JSONEq(t, `{"reason":"incorrect_password"}`, string(event1.Details))
+	require.Equal(t, HashAuditEvent(event1), event1.Hash)
+
+	event2, err := testStore.RecordAuditEventTx(context.Background(), AuditEventParams{
+		Username:  user.Username,
+		EventType: AuditLogin,
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(event2.Details))
+
+	events, err := testQueries.ListUserAuditEvents(context.Background(), ListUserAuditEventsParams{
+		Username: user.Username,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, event2.ID, events[0].ID)
+	require.Equal(t, event1.ID, events[1].ID)
+
+	events, err = testQueries.ListUserAuditEvents(context.Background(), ListUserAuditEventsParams{
+		Username: user.Username,
+		BeforeID: sql.NullInt64{Int64: event2.ID, Valid: true},
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, event1.ID, events[0].ID)
+}
+
+func TestAuditChain(t *testing.T) {
+	user := createRandomUser(t)
+
+	first, err := testStore.RecordAuditEventTx(context.Background(), AuditEventParams{
+		Username:  user.Username,
+		EventType: AuditLogin,
+	})
+	require.NoError(t, err)
+
+	// Audited changes append to the same chain as standalone events
+	account := createRandomAccountWithCurrency(t, util.USD)
+	_, err = testStore.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    10,
+		Reason:    "missing interest",
+		CreatedBy: user.Username,
+	})
+	require.NoError(t, err)
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		ID:    first.ID - 1,
+		Limit: 1000,
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(events), 2)
+	require.Equal(t, first, events[0])
+
+	_, err = VerifyAuditChain(first.PrevHash, events)
+	require.NoError(t, err)
+
+	// Changing an event is detected
+	tampered := append([]AuditEvent{}, events...)
+	tampered[0].Details = json.RawMessage(`{"reason":"edited"}`)
+	_, err = VerifyAuditChain(first.PrevHash, tampered)
+	require.ErrorIs(t, err, ErrAuditChainBroken)
+
+	// So is removing one
+	_, err = VerifyAuditChain(first.PrevHash, events[1:])
+	require.ErrorIs(t, err, ErrAuditChainBroken)
+}
+
+func TestAuditEventsAreAppendOnly(t *testing.T) {
+	user := createRandomUser(t)
+
+	event, err := testStore.RecordAuditEventTx(context.Background(), AuditEventParams{
+		Username:  user.Username,
+		EventType: AuditLogin,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.db.ExecContext(context.Background(), "UPDATE audit_events SET actor = 'someone' WHERE id = $1", event.ID)
+	require.Error(t, err)
+
+	_, err = testQueries.db.ExecContext(context.Background(), "DELETE FROM audit_events WHERE id = $1", event.ID)
+	require.Error(t, err)
+}
+
+func TestTransferTxAudit(t *testing.T) {
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	account1 = fundAccount(t, account1, 100)
+
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      util.USD,
+		Audit:         AuditContext{ClientIP: "10.0.0.1"},
+	})
+	require.NoError(t, err)
+
+	events, err := testQueries.ListUserAuditEvents(context.Background(), ListUserAuditEventsParams{
+		Username: account1.Owner,
+		Limit:    1,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditTransferCreated, events[0].EventType)
+	require.Equal(t, account1.Owner, events[0].Actor)
+	require.Equal(t, "10.0.0.1", events[0].ClientIp)
+
+	var details map[string]any
+	require.NoError(t, json.Unmarshal(events[0].Details, &details))
+	require.Equal(t, float64(result.Transfer.ID), details["transfer_id"])
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomEmailChange(t *testing.T, user User, newEmail string) (EmailChange, string) {
+	secretCode, err := util.NewSecretCode()
+	require.NoError(t, err)
+
+	emailChange, err := testStore.CreateEmailChange(context.Background(), CreateEmailChangeParams{
+		Username:   user.Username,
+		OldEmail:   user.Email,
+		NewEmail:   newEmail,
+		SecretHash: util.HashSecretCode(secretCode),
+	})
+	require.NoError(t, err)
+	require.False(t, emailChange.IsUsed)
+	require.True(t, emailChange.ExpiredAt.After(emailChange.CreatedAt))
+
+	return emailChange, secretCode
+}
+
+func TestConfirmEmailChangeTx(t *testing.T) {
+	user := createRandomUser(t)
+	newEmail := util.RandomEmail()
+
+	first, firstCode := createRandomEmailChange(t, user, util.RandomEmail())
+	second, secondCode := createRandomEmailChange(t, user, newEmail)
+
+	result, err := testStore.ConfirmEmailChangeTx(context.Background(), ConfirmEmailChangeTxParams{
+		ChangeID:   second.ID,
+		SecretHash: util.HashSecretCode(secondCode),
+	})
+	require.NoError(t, err)
+	require.Equal(t, newEmail, result.User.Email)
+	require.True(t, result.User.IsEmailVerified)
+	require.True(t, result.EmailChange.IsUsed)
+
+	// Each link can only be used once
+	_, err = testStore.ConfirmEmailChangeTx(context.Background(), ConfirmEmailChangeTxParams{
+		ChangeID:   second.ID,
+		SecretHash: util.HashSecretCode(secondCode),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// An earlier link can't undo the change
+	_, err = testStore.ConfirmEmailChangeTx(context.Background(), ConfirmEmailChangeTxParams{
+		ChangeID:   first.ID,
+		SecretHash: util.HashSecretCode(firstCode),
+	})
+	require.ErrorIs(t, err, ErrEmailChangeSuperseded)
+
+	got, err := testStore.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, newEmail, got.Email)
+}
+
+func TestConfirmEmailChangeTxEmailTaken(t *testing.T) {
+	user := createRandomUser(t)
+	other := createRandomUser(t)
+
+	emailChange, secretCode := createRandomEmailChange(t, user, other.Email)
+
+	_, err := testStore.ConfirmEmailChangeTx(context.Background(), ConfirmEmailChangeTxParams{
+		ChangeID:   emailChange.ID,
+		SecretHash: util.HashSecretCode(secretCode),
+	})
+	require.ErrorIs(t, err, ErrEmailTaken)
+
+	got, err := testStore.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.Email, got.Email)
+}
@@ -7,36 +7,100 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const countAccountStatementEntries = `-- name: CountAccountStatementEntries :one
+SELECT COUNT(*) FROM entries
+WHERE account_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+`
+
+type CountAccountStatementEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+func (q *Queries) CountAccountStatementEntries(ctx context.Context, arg CountAccountStatementEntriesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAccountStatementEntries, arg.AccountID, arg.StartTime, arg.EndTime)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
   account_id,
-  amount
+  amount,
+  currency,
+  transfer_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3, $4
+) RETURNING id, account_id, amount, created_at, currency, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	Currency   string        `json:"currency"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.Currency,
+		arg.TransferID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Currency,
+		&i.TransferID,
+	)
+	return i, err
+}
+
+const getAccountStatementBalances = `-- name: GetAccountStatementBalances :one
+SELECT
+  (a.balance - COALESCE(SUM(e.amount) FILTER (WHERE e.created_at >= $1), 0))::bigint AS opening_balance,
+  (a.balance - COALESCE(SUM(e.amount) FILTER (WHERE e.created_at >= $2), 0))::bigint AS closing_balance
+FROM account AS a
+LEFT JOIN entries AS e ON e.account_id = a.id
+WHERE a.id = $3
+GROUP BY a.id
+`
+
+type GetAccountStatementBalancesParams struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	AccountID int64     `json:"account_id"`
+}
+
+type GetAccountStatementBalancesRow struct {
+	OpeningBalance int64 `json:"opening_balance"`
+	ClosingBalance int64 `json:"closing_balance"`
+}
+
+func (q *Queries) GetAccountStatementBalances(ctx context.Context, arg GetAccountStatementBalancesParams) (GetAccountStatementBalancesRow, error) {
+	row := q.db.QueryRowContext(ctx, getAccountStatementBalances, arg.StartTime, arg.EndTime, arg.AccountID)
+	var i GetAccountStatementBalancesRow
+	err := row.Scan(
+		&i.OpeningBalance,
+		&i.ClosingBalance,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, currency, transfer_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,26 +112,134 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Currency,
+		&i.TransferID,
 	)
 	return i, err
 }
 
+const listAccountStatementEntries = `-- name: ListAccountStatementEntries :many
+WITH page AS (
+  SELECT id, account_id, amount, created_at, currency, transfer_id
+  FROM entries
+  WHERE account_id = $1
+    AND created_at >= $2
+    AND created_at < $3
+    AND ($4::timestamptz IS NULL OR (created_at, id) > ($4, $5::bigint))
+  ORDER BY created_at, id
+  LIMIT $6
+  OFFSET $7
+), opening AS (
+  -- The balance before the page is the current balance less every entry from its first on,
+  -- so only the page goes through the running total
+  SELECT a.balance - COALESCE((
+    SELECT SUM(e.amount) FROM entries AS e
+    WHERE e.account_id = a.id
+      AND (e.created_at, e.id) >= (SELECT p.created_at, p.id FROM page AS p ORDER BY p.created_at, p.id LIMIT 1)
+  ), 0) AS balance
+  FROM account AS a
+  WHERE a.id = $1
+)
+SELECT
+  p.id, p.account_id, p.amount, p.created_at, p.currency, p.transfer_id,
+  (o.balance + SUM(p.amount) OVER (ORDER BY p.created_at, p.id ROWS UNBOUNDED PRECEDING))::bigint AS running_balance,
+  COALESCE(c.id, 0)::bigint AS counterparty_account_id,
+  COALESCE(c.account_number, '')::varchar AS counterparty_account_number
+FROM page AS p
+CROSS JOIN opening AS o
+LEFT JOIN transfers AS t ON t.id = p.transfer_id
+LEFT JOIN account AS c ON c.id = CASE WHEN t.from_account_id = p.account_id THEN t.to_account_id ELSE t.from_account_id END
+ORDER BY p.created_at, p.id
+`
+
+type ListAccountStatementEntriesParams struct {
+	AccountID      int64         `json:"account_id"`
+	StartTime      time.Time     `json:"start_time"`
+	EndTime        time.Time     `json:"end_time"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+	Limit          int32         `json:"limit"`
+	Offset         int32         `json:"offset"`
+}
+
+type ListAccountStatementEntriesRow struct {
+	ID                        int64         `json:"id"`
+	AccountID                 int64         `json:"account_id"`
+	Amount                    int64         `json:"amount"`
+	CreatedAt                 time.Time     `json:"created_at"`
+	Currency                  string        `json:"currency"`
+	TransferID                sql.NullInt64 `json:"transfer_id"`
+	RunningBalance            int64         `json:"running_balance"`
+	CounterpartyAccountID     int64         `json:"counterparty_account_id"`
+	CounterpartyAccountNumber string        `json:"counterparty_account_number"`
+}
+
+func (q *Queries) ListAccountStatementEntries(ctx context.Context, arg ListAccountStatementEntriesParams) ([]ListAccountStatementEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatementEntries,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAccountStatementEntriesRow
+	for rows.Next() {
+		var i ListAccountStatementEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Currency,
+			&i.TransferID,
+			&i.RunningBalance,
+			&i.CounterpartyAccountID,
+			&i.CounterpartyAccountNumber,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, currency, transfer_id FROM entries
 WHERE account_id = $1
-ORDER BY id
-LIMIT $2
-OFFSET $3
+  AND ($2::timestamptz IS NULL OR (created_at, id) > ($2, $3::bigint))
+ORDER BY created_at, id
+LIMIT $4
+OFFSET $5
 `
 
 type ListEntriesParams struct {
-	AccountID int64 `json:"account_id"`
-	Limit     int32 `json:"limit"`
-	Offset    int32 `json:"offset"`
+	AccountID      int64         `json:"account_id"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+	Limit          int32         `json:"limit"`
+	Offset         int32         `json:"offset"`
 }
 
 func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error) {
-	rows, err := q.db.QueryContext(ctx, listEntries, arg.AccountID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listEntries,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -80,6 +252,8 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Currency,
+			&i.TransferID,
 		); err != nil {
 			return nil, err
 		}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ func createRandomEntry(t *testing.T, account Account) Entry {
 	arg := CreateEntryParams{
 		AccountID: account.ID,
 		Amount:    util.RandomMoney(),
+		Currency:  account.Currency,
 	}
 
 	entry, err := testStore.CreateEntry(context.Background(), arg)
@@ -21,6 +23,7 @@ func createRandomEntry(t *testing.T, account Account) Entry {
 
 	require.Equal(t, arg.AccountID, entry.AccountID)
 	require.Equal(t, arg.Amount, entry.Amount)
+	require.Equal(t, arg.Currency, entry.Currency)
 
 	require.NotZero(t, entry.ID)
 	require.NotZero(t, entry.CreatedAt)
@@ -67,3 +70,119 @@ func TestListEntries(t *testing.T) {
 		require.Equal(t, arg.AccountID, entry.AccountID)
 	}
 }
+
+func TestListAccountStatementEntries(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	startTime := time.Now().Add(-time.Minute)
+
+	n := 3
+	amount := int64(10)
+	for i := 0; i < n; i++ {
+		_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Currency:      util.USD,
+		})
+		require.NoError(t, err)
+	}
+	endTime := time.Now().Add(time.Minute)
+
+	entries, err := testStore.ListAccountStatementEntries(context.Background(), ListAccountStatementEntriesParams{
+		AccountID: account1.ID,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, n)
+
+	for i, entry := range entries {
+		require.Equal(t, account1.ID, entry.AccountID)
+		require.Equal(t, -amount, entry.Amount)
+		require.True(t, entry.TransferID.Valid)
+		require.Equal(t, account2.ID, entry.CounterpartyAccountID)
+		require.Equal(t, account1.Balance-int64(i+1)*amount, entry.RunningBalance)
+	}
+
+	balances, err := testStore.GetAccountStatementBalances(context.Background(), GetAccountStatementBalancesParams{
+		StartTime: startTime,
+		EndTime:   endTime,
+		AccountID: account1.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, balances.OpeningBalance)
+	require.Equal(t, account1.Balance-int64(n)*amount, balances.ClosingBalance)
+}
+
+func TestListAccountStatementEntriesPages(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	amount := int64(10)
+
+	transfer := func() TransferTxResult {
+		result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			Currency:      util.USD,
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	// Entries before the range still count towards the running balance
+	before := 2
+	var last TransferTxResult
+	for i := 0; i < before; i++ {
+		last = transfer()
+	}
+	startTime := last.FromEntry.CreatedAt.Add(time.Microsecond)
+
+	n := 5
+	for i := 0; i < n; i++ {
+		transfer()
+	}
+	endTime := time.Now().Add(time.Minute)
+
+	// Page through the range with the keyset cursor, two entries at a time
+	arg := ListAccountStatementEntriesParams{
+		AccountID: account1.ID,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     2,
+	}
+	var entries []ListAccountStatementEntriesRow
+	for {
+		page, err := testStore.ListAccountStatementEntries(context.Background(), arg)
+		require.NoError(t, err)
+		entries = append(entries, page...)
+		if len(page) < int(arg.Limit) {
+			break
+		}
+		lastEntry := page[len(page)-1]
+		arg.AfterCreatedAt = sql.NullTime{Time: lastEntry.CreatedAt, Valid: true}
+		arg.AfterID = sql.NullInt64{Int64: lastEntry.ID, Valid: true}
+	}
+
+	require.Len(t, entries, n)
+	for i, entry := range entries {
+		require.Equal(t, -amount, entry.Amount)
+		require.Equal(t, account1.Balance-int64(before+i+1)*amount, entry.RunningBalance)
+	}
+
+	// Offset pages start from the right balance too
+	page, err := testStore.ListAccountStatementEntries(context.Background(), ListAccountStatementEntriesParams{
+		AccountID: account1.ID,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     2,
+		Offset:    3,
+	})
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, entries[3].ID, page[0].ID)
+	require.Equal(t, entries[3].RunningBalance, page[0].RunningBalance)
+	require.Equal(t, entries[4].RunningBalance, page[1].RunningBalance)
+}
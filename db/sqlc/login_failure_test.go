@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+)
+
+func reserveLoginAttempt(keys []LoginFailureKey, resetBefore time.Time) (ReserveLoginAttemptTxResult, error) {
+	return testStore.ReserveLoginAttemptTx(context.Background(), ReserveLoginAttemptTxParams{
+		Keys:        keys,
+		ResetBefore: resetBefore,
+		// Keys stay open so every attempt in the test can be reserved
+		LockFor: func(failures int64) time.Duration { return 0 },
+	})
+}
+
+func TestReserveLoginAttemptTx(t *testing.T) {
+	key := util.RandomOwner()
+	keys := []LoginFailureKey{{Scope: "username", Key: key}}
+
+	for i := int64(1); i <= 3; i++ {
+		result, err := reserveLoginAttempt(keys, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		require.Len(t, result.Reserved, 1)
+		require.Equal(t, i-1, result.Previous[0].FailureCount)
+		require.Equal(t, i, result.Reserved[0].FailureCount)
+		require.WithinDuration(t, time.Now(), result.Reserved[0].LastFailedAt, time.Second)
+	}
+
+	// Failures older than the window start the count over
+	result, err := reserveLoginAttempt(keys, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Reserved[0].FailureCount)
+
+	// The same key under another scope is counted separately
+	result, err = reserveLoginAttempt([]LoginFailureKey{{Scope: "ip", Key: key}}, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), result.Reserved[0].FailureCount)
+
+	err = testStore.DeleteLoginFailure(context.Background(), DeleteLoginFailureParams{Scope: "username", Key: key})
+	require.NoError(t, err)
+
+	_, err = testStore.GetLoginFailure(context.Background(), GetLoginFailureParams{Scope: "username", Key: key})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testStore.GetLoginFailure(context.Background(), GetLoginFailureParams{Scope: "ip", Key: key})
+	require.NoError(t, err)
+}
+
+func TestReserveLoginAttemptTxLocked(t *testing.T) {
+	username := LoginFailureKey{Scope: "username", Key: util.RandomOwner()}
+	ip := LoginFailureKey{Scope: "ip", Key: util.RandomOwner()}
+
+	result, err := testStore.ReserveLoginAttemptTx(context.Background(), ReserveLoginAttemptTxParams{
+		Keys:        []LoginFailureKey{username},
+		ResetBefore: time.Now().Add(-time.Hour),
+		LockFor:     func(failures int64) time.Duration { return time.Minute },
+	})
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Minute), result.Reserved[0].LockedUntil, time.Second)
+
+	// Further attempts are turned away until the lock runs out
+	_, err = reserveLoginAttempt([]LoginFailureKey{username, ip}, time.Now().Add(-time.Hour))
+	require.ErrorIs(t, err, ErrLoginLocked)
+
+	// Nothing is counted against the other keys of a locked attempt
+	_, err = testStore.GetLoginFailure(context.Background(), GetLoginFailureParams{Scope: ip.Scope, Key: ip.Key})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestReleaseLoginAttempt(t *testing.T) {
+	key := LoginFailureKey{Scope: "username", Key: util.RandomOwner()}
+
+	_, err := reserveLoginAttempt([]LoginFailureKey{key}, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	attempt, err := reserveLoginAttempt([]LoginFailureKey{key}, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	release := ReleaseLoginAttemptParams{
+		FailureCount:  attempt.Previous[0].FailureCount,
+		LastFailedAt:  attempt.Previous[0].LastFailedAt,
+		LockedUntil:   attempt.Previous[0].LockedUntil,
+		Scope:         key.Scope,
+		Key:           key.Key,
+		ReservedUntil: attempt.Reserved[0].LockedUntil,
+	}
+
+	// An attempt reserved in the meantime keeps its count
+	_, err = reserveLoginAttempt([]LoginFailureKey{key}, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	err = testStore.ReleaseLoginAttempt(context.Background(), release)
+	require.NoError(t, err)
+
+	failure, err := testStore.GetLoginFailure(context.Background(), GetLoginFailureParams{Scope: key.Scope, Key: key.Key})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), failure.FailureCount)
+
+	// The latest attempt is put back to the count before it
+	latest, err := reserveLoginAttempt([]LoginFailureKey{key}, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	err = testStore.ReleaseLoginAttempt(context.Background(), ReleaseLoginAttemptParams{
+		FailureCount:  latest.Previous[0].FailureCount,
+		LastFailedAt:  latest.Previous[0].LastFailedAt,
+		LockedUntil:   latest.Previous[0].LockedUntil,
+		Scope:         key.Scope,
+		Key:           key.Key,
+		ReservedUntil: latest.Reserved[0].LockedUntil,
+	})
+	require.NoError(t, err)
+
+	failure, err = testStore.GetLoginFailure(context.Background(), GetLoginFailureParams{Scope: key.Scope, Key: key.Key})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), failure.FailureCount)
+}
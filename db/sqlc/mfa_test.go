@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableMFATx(t *testing.T) {
+	user := createRandomUser(t)
+
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+	pending, err := testStore.StartUserMFAEnrollment(context.Background(), StartUserMFAEnrollmentParams{
+		Username:   user.Username,
+		TotpSecret: secret,
+	})
+	require.NoError(t, err)
+	require.False(t, pending.EnabledAt.Valid)
+
+	codes, err := util.NewRecoveryCodes(util.RecoveryCodeCount)
+	require.NoError(t, err)
+	codeHashes := make([]string, len(codes))
+	for i, code := range codes {
+		codeHashes[i] = util.HashRecoveryCode(code)
+	}
+
+	result, err := testStore.EnableMFATx(context.Background(), EnableMFATxParams{
+		EnableUserMFAParams: EnableUserMFAParams{
+			Username:     user.Username,
+			LastUsedStep: 100,
+		},
+		RecoveryCodeHashes: codeHashes,
+	})
+	require.NoError(t, err)
+	require.True(t, result.UserMFA.EnabledAt.Valid)
+	require.Equal(t, secret, result.UserMFA.TotpSecret)
+
+	// Enabling twice fails, and so does enrolling again
+	_, err = testStore.EnableMFATx(context.Background(), EnableMFATxParams{
+		EnableUserMFAParams: EnableUserMFAParams{Username: user.Username, LastUsedStep: 101},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testStore.StartUserMFAEnrollment(context.Background(), StartUserMFAEnrollmentParams{
+		Username:   user.Username,
+		TotpSecret: secret,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// A time step can only be used once
+	_, err = testStore.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, LastUsedStep: 100})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = testStore.UseTOTPStep(context.Background(), UseTOTPStepParams{Username: user.Username, LastUsedStep: 101})
+	require.NoError(t, err)
+
+	// And so can a recovery code
+	arg := UseMFARecoveryCodeParams{Username: user.Username, CodeHash: codeHashes[0]}
+	used, err := testStore.UseMFARecoveryCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, used.UsedAt.Valid)
+	_, err = testStore.UseMFARecoveryCode(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	events, err := testStore.ListUserAuditEvents(context.Background(), ListUserAuditEventsParams{
+		Username: user.Username,
+		Limit:    10,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+	require.Equal(t, AuditMFAEnabled, events[0].EventType)
+}
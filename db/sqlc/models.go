@@ -6,6 +6,7 @@ package db
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,25 +19,158 @@ type Account struct {
 	Currency      string         `json:"currency"`
 	CreatedAt     time.Time      `json:"created_at"`
 	AccountNumber sql.NullString `json:"account_number"`
+	// how far below zero the balance is allowed to go
+	OverdraftLimit int64        `json:"overdraft_limit"`
+	Status         string       `json:"status"`
+	ClosedAt       sql.NullTime `json:"closed_at"`
+	// reserved by active holds; balance - held_amount is available to spend
+	HeldAmount int64 `json:"held_amount"`
+}
+
+type AccountAdjustment struct {
+	ID         int64     `json:"id"`
+	AccountID  int64     `json:"account_id"`
+	EntryID    int64     `json:"entry_id"`
+	Amount     int64     `json:"amount"`
+	Reason     string    `json:"reason"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	TransferID int64     `json:"transfer_id"`
+}
+
+type AuditEvent struct {
+	ID        int64           `json:"id"`
+	Username  string          `json:"username"`
+	Actor     string          `json:"actor"`
+	EventType string          `json:"event_type"`
+	Details   json.RawMessage `json:"details"`
+	ClientIp  string          `json:"client_ip"`
+	UserAgent string          `json:"user_agent"`
+	CreatedAt time.Time       `json:"created_at"`
+	PrevHash  string          `json:"prev_hash"`
+	Hash      string          `json:"hash"`
+}
+
+type EmailChange struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	OldEmail   string    `json:"old_email"`
+	NewEmail   string    `json:"new_email"`
+	SecretHash string    `json:"secret_hash"`
+	IsUsed     bool      `json:"is_used"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
 }
 
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
 	// can be negative or positive
-	Amount    int64     `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	Amount     int64         `json:"amount"`
+	CreatedAt  time.Time     `json:"created_at"`
+	Currency   string        `json:"currency"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+type FxRate struct {
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          string    `json:"rate"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	Username       string          `json:"username"`
+	IdempotencyKey string          `json:"idempotency_key"`
+	RequestHash    string          `json:"request_hash"`
+	TransferID     sql.NullInt64   `json:"transfer_id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Response       json.RawMessage `json:"response"`
+}
+
+type LedgerDiscrepancy struct {
+	ID         int64         `json:"id"`
+	RunID      int64         `json:"run_id"`
+	Kind       string        `json:"kind"`
+	AccountID  sql.NullInt64 `json:"account_id"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	Expected   int64         `json:"expected"`
+	Actual     int64         `json:"actual"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type LoginFailure struct {
+	// username or ip
+	Scope        string    `json:"scope"`
+	Key          string    `json:"key"`
+	FailureCount int64     `json:"failure_count"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+	// No login is attempted for the key before this time
+	LockedUntil time.Time `json:"locked_until"`
+}
+
+type MfaRecoveryCode struct {
+	ID        int64        `json:"id"`
+	Username  string       `json:"username"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type PasswordReset struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	SecretHash string    `json:"secret_hash"`
+	IsUsed     bool      `json:"is_used"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
+}
+
+type PendingTransfer struct {
+	ID             int64  `json:"id"`
+	Username       string `json:"username"`
+	FromAccountID  int64  `json:"from_account_id"`
+	ToAccountID    int64  `json:"to_account_id"`
+	Amount         int64  `json:"amount"`
+	Currency       string `json:"currency"`
+	ToAmount       int64  `json:"to_amount"`
+	ToCurrency     string `json:"to_currency"`
+	ExchangeRate   string `json:"exchange_rate"`
+	SpreadBps      int64  `json:"spread_bps"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Status         string `json:"status"`
+	// The transfer made once confirmed
+	TransferID  sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+	ConfirmedAt sql.NullTime  `json:"confirmed_at"`
+}
+
+type ReconciliationRun struct {
+	ID int64 `json:"id"`
+	// The admin who asked for the run, or "scheduler"
+	TriggeredBy      string    `json:"triggered_by"`
+	LastEntryID      int64     `json:"last_entry_id"`
+	LastTransferID   int64     `json:"last_transfer_id"`
+	LastAccountID    int64     `json:"last_account_id"`
+	DiscrepancyCount int64     `json:"discrepancy_count"`
+	CreatedAt        time.Time `json:"created_at"`
+	// status changes before it have been checked
+	CheckedUntil time.Time `json:"checked_until"`
 }
 
 type Session struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	RefreshToken string    `json:"refresh_token"`
-	UserAgent    string    `json:"user_agent"`
-	ClientIp     string    `json:"client_ip"`
-	IsBoolean    bool      `json:"is_boolean"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           uuid.UUID     `json:"id"`
+	Username     string        `json:"username"`
+	RefreshToken string        `json:"refresh_token"`
+	UserAgent    string        `json:"user_agent"`
+	ClientIp     string        `json:"client_ip"`
+	IsBoolean    bool          `json:"is_boolean"`
+	ExpiresAt    time.Time     `json:"expires_at"`
+	CreatedAt    time.Time     `json:"created_at"`
+	FamilyID     uuid.UUID     `json:"family_id"`
+	ReplacedBy   uuid.NullUUID `json:"replaced_by"`
 }
 
 type Transfer struct {
@@ -46,6 +180,22 @@ type Transfer struct {
 	// It must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	Currency  string    `json:"currency"`
+	// amount credited in to_currency
+	ToAmount     int64  `json:"to_amount"`
+	ToCurrency   string `json:"to_currency"`
+	ExchangeRate string `json:"exchange_rate"`
+	// spread kept on the conversion, in basis points
+	SpreadBps int64 `json:"spread_bps"`
+	// the transfer this one reverses, if it is a reversal
+	ReversesTransferID sql.NullInt64 `json:"reverses_transfer_id"`
+	// how much of to_amount has been reversed so far
+	ReversedAmount int64 `json:"reversed_amount"`
+	// pending, posted, failed or reversed
+	Status string `json:"status"`
+	// set on holds, which are released if not captured by then
+	HoldExpiresAt   sql.NullTime `json:"hold_expires_at"`
+	StatusChangedAt time.Time    `json:"status_changed_at"`
 }
 
 type User struct {
@@ -56,6 +206,18 @@ type User struct {
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 	IsEmailVerified   bool      `json:"is_email_verified"`
+	Role              string    `json:"role"`
+	// Transfers above this amount must be confirmed again; NULL uses the configured default
+	TransferConfirmationThreshold sql.NullInt64 `json:"transfer_confirmation_threshold"`
+}
+
+type UserMfa struct {
+	Username   string `json:"username"`
+	TotpSecret string `json:"totp_secret"`
+	// Time step of the last accepted code, so each code works only once
+	LastUsedStep int64        `json:"last_used_step"`
+	EnabledAt    sql.NullTime `json:"enabled_at"`
+	CreatedAt    time.Time    `json:"created_at"`
 }
 
 type VerifyEmail struct {
@@ -0,0 +1,20 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/OmSingh2003/nimbus/token"
+)
+
+// PasswordChangedAt loads when a user last changed their password from the store,
+// for a token.PasswordChangeCache to reject tokens issued before then
+func PasswordChangedAt(store Store) token.PasswordChangeLoader {
+	return func(ctx context.Context, username string) (time.Time, error) {
+		user, err := store.GetUser(ctx, username)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return user.PasswordChangedAt, nil
+	}
+}
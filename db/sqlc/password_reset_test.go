@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetPasswordTx(t *testing.T) {
+	user := createRandomUser(t)
+
+	session, err := testStore.CreateSession(context.Background(), CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		ExpiresAt:    time.Now().Add(time.Hour),
+		FamilyID:     uuid.New(),
+	})
+	require.NoError(t, err)
+
+	secretCode, err := util.NewSecretCode()
+	require.NoError(t, err)
+	reset, err := testStore.CreatePasswordReset(context.Background(), CreatePasswordResetParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretHash: util.HashSecretCode(secretCode),
+		ExpiredAt:  time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.False(t, reset.IsUsed)
+
+	// The code itself isn't what's stored
+	_, err = testStore.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		ResetID:        reset.ID,
+		SecretHash:     secretCode,
+		HashedPassword: "hash",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	result, err := testStore.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		ResetID:        reset.ID,
+		SecretHash:     util.HashSecretCode(secretCode),
+		HashedPassword: "new-hash",
+	})
+	require.NoError(t, err)
+	require.True(t, result.PasswordReset.IsUsed)
+	require.Equal(t, "new-hash", result.User.HashedPassword)
+	require.True(t, result.User.PasswordChangedAt.After(user.PasswordChangedAt))
+	require.Equal(t, int64(1), result.RevokedSessions)
+
+	session, err = testStore.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, session.IsBoolean)
+
+	// Each link can only be used once
+	_, err = testStore.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		ResetID:        reset.ID,
+		SecretHash:     util.HashSecretCode(secretCode),
+		HashedPassword: "another-hash",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestResetPasswordTxExpired(t *testing.T) {
+	user := createRandomUser(t)
+
+	secretCode, err := util.NewSecretCode()
+	require.NoError(t, err)
+	reset, err := testStore.CreatePasswordReset(context.Background(), CreatePasswordResetParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretHash: util.HashSecretCode(secretCode),
+		ExpiredAt:  time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		ResetID:        reset.ID,
+		SecretHash:     util.HashSecretCode(secretCode),
+		HashedPassword: "new-hash",
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := testStore.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.HashedPassword, got.HashedPassword)
+}
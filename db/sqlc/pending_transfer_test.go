@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPendingTransfer(t *testing.T, from Account, to Account, expiresAt time.Time) PendingTransfer {
+	arg := CreatePendingTransferParams{
+		Username:      from.Owner,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Currency:      from.Currency,
+		ToAmount:      10,
+		ToCurrency:    to.Currency,
+		ExchangeRate:  "1",
+		ExpiresAt:     expiresAt,
+	}
+
+	pending, err := testStore.CreatePendingTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, pending.Username)
+	require.Equal(t, arg.Amount, pending.Amount)
+	require.Equal(t, PendingTransferPending, pending.Status)
+	require.False(t, pending.TransferID.Valid)
+
+	return pending
+}
+
+func TestConfirmPendingTransferTx(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, from.Currency)
+	pending := createRandomPendingTransfer(t, from, to, time.Now().Add(time.Minute))
+
+	result, err := testStore.ConfirmPendingTransferTx(context.Background(), ConfirmPendingTransferTxParams{
+		ID:    pending.ID,
+		Audit: AuditContext{Actor: from.Owner},
+	})
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferConfirmed, result.PendingTransfer.Status)
+	require.Equal(t, result.Transfer.ID, result.PendingTransfer.TransferID.Int64)
+	require.True(t, result.PendingTransfer.ConfirmedAt.Valid)
+	require.Equal(t, from.Balance-pending.Amount, result.FromAccount.Balance)
+	require.Equal(t, to.Balance+pending.Amount, result.ToAccount.Balance)
+
+	// A pending transfer can only be confirmed once
+	_, err = testStore.ConfirmPendingTransferTx(context.Background(), ConfirmPendingTransferTxParams{ID: pending.ID})
+	require.ErrorIs(t, err, ErrPendingTransferNotPending)
+
+	// and can't be expired once confirmed
+	_, err = testStore.ExpirePendingTransfer(context.Background(), pending.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestExpirePendingTransfer(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, from.Currency)
+	pending := createRandomPendingTransfer(t, from, to, time.Now().Add(time.Minute))
+
+	expired, err := testStore.ExpirePendingTransfer(context.Background(), pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferExpired, expired.Status)
+
+	_, err = testStore.ConfirmPendingTransferTx(context.Background(), ConfirmPendingTransferTxParams{ID: pending.ID})
+	require.ErrorIs(t, err, ErrPendingTransferNotPending)
+
+	// Past its expiry time a transfer can't be confirmed even before the task has run
+	late := createRandomPendingTransfer(t, from, to, time.Now().Add(-time.Second))
+	_, err = testStore.ConfirmPendingTransferTx(context.Background(), ConfirmPendingTransferTxParams{ID: late.ID})
+	require.ErrorIs(t, err, ErrPendingTransferNotPending)
+
+	account, err := testStore.GetAccount(context.Background(), from.ID)
+	require.NoError(t, err)
+	require.Equal(t, from.Balance, account.Balance)
+}
+
+func TestCreatePendingTransferIdempotencyKey(t *testing.T) {
+	from := createRandomAccount(t)
+	to := createRandomAccountWithCurrency(t, from.Currency)
+
+	arg := CreatePendingTransferParams{
+		Username:       from.Owner,
+		FromAccountID:  from.ID,
+		ToAccountID:    to.ID,
+		Amount:         10,
+		Currency:       from.Currency,
+		ToAmount:       10,
+		ToCurrency:     to.Currency,
+		ExchangeRate:   "1",
+		IdempotencyKey: util.RandomString(16),
+		ExpiresAt:      time.Now().Add(time.Minute),
+	}
+	pending, err := testStore.CreatePendingTransfer(context.Background(), arg)
+	require.NoError(t, err)
+
+	// A second transfer under the same key isn't held
+	_, err = testStore.CreatePendingTransfer(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	held, err := testStore.GetPendingTransferByIdempotencyKey(context.Background(), GetPendingTransferByIdempotencyKeyParams{
+		Username:       arg.Username,
+		IdempotencyKey: arg.IdempotencyKey,
+	})
+	require.NoError(t, err)
+	require.Equal(t, pending.ID, held.ID)
+
+	// Transfers without a key are all held
+	createRandomPendingTransfer(t, from, to, time.Now().Add(time.Minute))
+	createRandomPendingTransfer(t, from, to, time.Now().Add(time.Minute))
+}
@@ -7,31 +7,115 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountHeldAmount(ctx context.Context, arg AddAccountHeldAmountParams) (Account, error)
+	AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error)
+	BlockOtherSessions(ctx context.Context, arg BlockOtherSessionsParams) (int64, error)
+	BlockSession(ctx context.Context, arg BlockSessionParams) (Session, error)
+	BlockSessionFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
+	CloseAccount(ctx context.Context, id int64) (Account, error)
+	ConfirmPendingTransfer(ctx context.Context, arg ConfirmPendingTransferParams) (PendingTransfer, error)
+	CountAccountStatementEntries(ctx context.Context, arg CountAccountStatementEntriesParams) (int64, error)
+	CountUserTransfers(ctx context.Context, arg CountUserTransfersParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountAdjustment(ctx context.Context, arg CreateAccountAdjustmentParams) (AccountAdjustment, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	CreateEmailChange(ctx context.Context, arg CreateEmailChangeParams) (EmailChange, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateLedgerDiscrepancy(ctx context.Context, arg CreateLedgerDiscrepancyParams) (LedgerDiscrepancy, error)
+	// Starts counting failures for a key unless it already has a count
+	CreateLoginFailure(ctx context.Context, arg CreateLoginFailureParams) error
+	CreateMFARecoveryCode(ctx context.Context, arg CreateMFARecoveryCodeParams) (MfaRecoveryCode, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	// Returns no rows if the user already has a pending transfer with the idempotency key
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (PendingTransfer, error)
+	CreateReconciliationRun(ctx context.Context, arg CreateReconciliationRunParams) (ReconciliationRun, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteLoginFailure(ctx context.Context, arg DeleteLoginFailureParams) error
+	DeleteMFARecoveryCodes(ctx context.Context, username string) error
+	EnableUserMFA(ctx context.Context, arg EnableUserMFAParams) (UserMfa, error)
+	// Returns no rows if the transfer was already confirmed or expired
+	ExpirePendingTransfer(ctx context.Context, id int64) (PendingTransfer, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
 	GetAccountByNumber(ctx context.Context, accountNumber sql.NullString) (Account, error)
+	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountStatementBalances(ctx context.Context, arg GetAccountStatementBalancesParams) (GetAccountStatementBalancesRow, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetFxRate(ctx context.Context, arg GetFxRateParams) (FxRate, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLastAuditEvent(ctx context.Context) (AuditEvent, error)
+	GetLastReconciliationRun(ctx context.Context) (ReconciliationRun, error)
+	GetLedgerHighWater(ctx context.Context, createdBefore time.Time) (GetLedgerHighWaterRow, error)
+	GetLoginFailure(ctx context.Context, arg GetLoginFailureParams) (LoginFailure, error)
+	GetLoginFailureForUpdate(ctx context.Context, arg GetLoginFailureForUpdateParams) (LoginFailure, error)
+	GetPendingTransfer(ctx context.Context, id int64) (PendingTransfer, error)
+	GetPendingTransferByIdempotencyKey(ctx context.Context, arg GetPendingTransferByIdempotencyKeyParams) (PendingTransfer, error)
+	GetPendingTransferForUpdate(ctx context.Context, id int64) (PendingTransfer, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetSessionForUpdate(ctx context.Context, id uuid.UUID) (Session, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTreasuryAccount(ctx context.Context, currency string) (Account, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserMFA(ctx context.Context, username string) (UserMfa, error)
+	ListAccountAdjustments(ctx context.Context, arg ListAccountAdjustmentsParams) ([]AccountAdjustment, error)
+	ListAccountBalanceMismatches(ctx context.Context, arg ListAccountBalanceMismatchesParams) ([]ListAccountBalanceMismatchesRow, error)
+	ListAccountStatementEntries(ctx context.Context, arg ListAccountStatementEntriesParams) ([]ListAccountStatementEntriesRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListActiveSessions(ctx context.Context, username string) ([]Session, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	// Every transfer debits as much as it credits in each currency, through the treasury
+	// when it converts, so the entries of each currency sum to zero
+	ListCurrencyImbalances(ctx context.Context, arg ListCurrencyImbalancesParams) ([]ListCurrencyImbalancesRow, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListExpiredHolds(ctx context.Context, arg ListExpiredHoldsParams) ([]int64, error)
+	ListFxRates(ctx context.Context) ([]FxRate, error)
+	ListTransferEntryMismatches(ctx context.Context, arg ListTransferEntryMismatchesParams) ([]ListTransferEntryMismatchesRow, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListUserAuditEvents(ctx context.Context, arg ListUserAuditEventsParams) ([]AuditEvent, error)
+	ListUserTransfers(ctx context.Context, arg ListUserTransfersParams) ([]Transfer, error)
+	// Serializes appends to the audit log until the end of the transaction
+	LockAuditChain(ctx context.Context) error
+	// Serializes reconciliation runs until the end of the transaction
+	LockLedgerReconciliation(ctx context.Context) error
+	// Puts back a key's failures from before an attempt that succeeded, unless
+	// another attempt has been reserved against the key since
+	ReleaseLoginAttempt(ctx context.Context, arg ReleaseLoginAttemptParams) error
+	// The query is matched literally, so % and _ in it aren't wildcards
+	SearchUsers(ctx context.Context, arg SearchUsersParams) ([]User, error)
+	SetIdempotencyKeyTransfer(ctx context.Context, arg SetIdempotencyKeyTransferParams) error
+	SetSessionReplacedBy(ctx context.Context, arg SetSessionReplacedByParams) error
+	// Replaces an unconfirmed secret; returns no rows if MFA is already enabled
+	StartUserMFAEnrollment(ctx context.Context, arg StartUserMFAEnrollmentParams) (UserMfa, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) error
+	UpdateAccountOverdraftLimit(ctx context.Context, arg UpdateAccountOverdraftLimitParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateHoldAmount(ctx context.Context, arg UpdateHoldAmountParams) (Transfer, error)
+	UpdateLoginFailure(ctx context.Context, arg UpdateLoginFailureParams) (LoginFailure, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateVerifyEmail(ctx context.Context, arg UpdateVerifyEmailParams) (VerifyEmail, error)
+	UpsertFxRate(ctx context.Context, arg UpsertFxRateParams) (FxRate, error)
+	UseEmailChange(ctx context.Context, arg UseEmailChangeParams) (EmailChange, error)
+	// Returns no rows if the code doesn't exist or was already used
+	UseMFARecoveryCode(ctx context.Context, arg UseMFARecoveryCodeParams) (MfaRecoveryCode, error)
+	UsePasswordReset(ctx context.Context, arg UsePasswordResetParams) (PasswordReset, error)
+	// Returns no rows if a code for this step or a later one was already used
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (UserMfa, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,232 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+)
+
+// discrepancyKinds indexes discrepancies by account or transfer ID and kind
+func discrepancyKinds(discrepancies []LedgerDiscrepancy) map[[2]int64]map[string]LedgerDiscrepancy {
+	found := make(map[[2]int64]map[string]LedgerDiscrepancy)
+	for _, discrepancy := range discrepancies {
+		key := [2]int64{discrepancy.AccountID.Int64, discrepancy.TransferID.Int64}
+		if found[key] == nil {
+			found[key] = make(map[string]LedgerDiscrepancy)
+		}
+		found[key][discrepancy.Kind] = discrepancy
+	}
+	return found
+}
+
+func TestReconcileLedgerTx(t *testing.T) {
+	store := testStore
+	startTime := time.Now().Add(-time.Second)
+
+	// Catch up with whatever other tests left in the ledger
+	_, err := store.ReconcileLedgerTx(context.Background(), ReconcileLedgerTxParams{
+		TriggeredBy: "test",
+		Until:       time.Now(),
+	})
+	require.NoError(t, err)
+
+	// A balance with no entries behind it, and a transfer with no entries at all
+	broken := createRandomAccountWithCurrency(t, util.USD)
+	other := createRandomAccountWithCurrency(t, util.USD)
+	transfer := createRandomTransfer(t, broken, other)
+
+	// An account funded through the treasury reconciles
+	user := createRandomUser(t)
+	funded, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:         user.Username,
+		Currency:      util.USD,
+		WelcomeCredit: util.WelcomeCreditAmount,
+	})
+	require.NoError(t, err)
+
+	result, err := store.ReconcileLedgerTx(context.Background(), ReconcileLedgerTxParams{
+		TriggeredBy: "test",
+		Until:       time.Now().Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.Equal(t, "test", result.Run.TriggeredBy)
+	require.Equal(t, int64(len(result.Discrepancies)), result.Run.DiscrepancyCount)
+	require.GreaterOrEqual(t, result.Run.LastTransferID, transfer.ID)
+	require.GreaterOrEqual(t, result.Run.LastAccountID, funded.Account.ID)
+
+	found := discrepancyKinds(result.Discrepancies)
+
+	balance := found[[2]int64{broken.ID, 0}][DiscrepancyAccountBalance]
+	require.Equal(t, result.Run.ID, balance.RunID)
+	require.NotZero(t, balance.ID)
+	require.Zero(t, balance.Expected)
+	require.Equal(t, broken.Balance, balance.Actual)
+
+	entryCount := found[[2]int64{0, transfer.ID}][DiscrepancyTransferEntryCount]
+	require.Equal(t, int64(2), entryCount.Expected)
+	require.Zero(t, entryCount.Actual)
+
+	debit := found[[2]int64{broken.ID, transfer.ID}][DiscrepancyTransferDebit]
+	require.Equal(t, -transfer.Amount, debit.Expected)
+	require.Zero(t, debit.Actual)
+
+	require.Empty(t, found[[2]int64{funded.Account.ID, 0}])
+	require.Empty(t, found[[2]int64{funded.Account.ID, funded.WelcomeTransfer.ID}])
+
+	// The next run picks up after this one, so nothing is reported twice
+	next, err := store.ReconcileLedgerTx(context.Background(), ReconcileLedgerTxParams{
+		TriggeredBy: "test",
+		Until:       time.Now().Add(time.Second),
+	})
+	require.NoError(t, err)
+	found = discrepancyKinds(next.Discrepancies)
+	require.Empty(t, found[[2]int64{broken.ID, 0}])
+	require.Empty(t, found[[2]int64{0, transfer.ID}])
+
+	// A report over the window finds them again without recording anything
+	report, err := store.ReportLedgerTx(context.Background(), ReportLedgerTxParams{
+		StartTime: startTime,
+		EndTime:   time.Now().Add(time.Second),
+	})
+	require.NoError(t, err)
+	found = discrepancyKinds(report.Discrepancies)
+
+	balance = found[[2]int64{broken.ID, 0}][DiscrepancyAccountBalance]
+	require.Zero(t, balance.ID)
+	require.Equal(t, broken.Balance, balance.Actual)
+	require.Contains(t, found[[2]int64{0, transfer.ID}], DiscrepancyTransferEntryCount)
+	require.Empty(t, found[[2]int64{funded.Account.ID, 0}])
+}
+
+func TestReconcileLedgerLateChanges(t *testing.T) {
+	store := testStore
+
+	caughtUp, err := store.ReconcileLedgerTx(context.Background(), ReconcileLedgerTxParams{
+		TriggeredBy: "test",
+		Until:       time.Now(),
+	})
+	require.NoError(t, err)
+	// Earlier runs may have looked ahead; status changes before then count as checked
+	time.Sleep(time.Until(caughtUp.Run.CheckedUntil))
+
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 0)
+
+	hold, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Currency:      util.USD,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        200,
+		Currency:      util.USD,
+	})
+	require.NoError(t, err)
+
+	// Both are fine when first checked
+	result, err := store.ReconcileLedgerTx(context.Background(), ReconcileLedgerTxParams{
+		TriggeredBy: "test",
+		Until:       time.Now(),
+	})
+	require.NoError(t, err)
+	found := discrepancyKinds(result.Discrepancies)
+	require.Empty(t, found[[2]int64{0, hold.Hold.ID}])
+	require.Empty(t, found[[2]int64{0, transfer.Transfer.ID}])
+
+	// The hold is posted without entries and the transfer gets a stray one
+	_, err = testQueries.UpdateTransferStatus(context.Background(), UpdateTransferStatusParams{
+		ID:         hold.Hold.ID,
+		Status:     TransferPosted,
+		FromStatus: TransferPending,
+	})
+	require.NoError(t, err)
+	_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID:  account2.ID,
+		Amount:     50,
+		Currency:   util.USD,
+		TransferID: sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// The next run checks both again
+	result, err = store.ReconcileLedgerTx(context.Background(), ReconcileLedgerTxParams{
+		TriggeredBy: "test",
+		Until:       time.Now(),
+	})
+	require.NoError(t, err)
+	found = discrepancyKinds(result.Discrepancies)
+
+	entryCount := found[[2]int64{0, hold.Hold.ID}][DiscrepancyTransferEntryCount]
+	require.Equal(t, int64(2), entryCount.Expected)
+	require.Zero(t, entryCount.Actual)
+
+	entryCount = found[[2]int64{0, transfer.Transfer.ID}][DiscrepancyTransferEntryCount]
+	require.Equal(t, int64(2), entryCount.Expected)
+	require.Equal(t, int64(3), entryCount.Actual)
+
+	credit := found[[2]int64{account2.ID, transfer.Transfer.ID}][DiscrepancyTransferCredit]
+	require.Equal(t, int64(200), credit.Expected)
+	require.Equal(t, int64(250), credit.Actual)
+}
+
+func TestReconcileLedgerCurrencyImbalance(t *testing.T) {
+	store := testStore
+
+	usdTreasury, err := testQueries.GetTreasuryAccount(context.Background(), util.USD)
+	require.NoError(t, err)
+	eurTreasury, err := testQueries.GetTreasuryAccount(context.Background(), util.EUR)
+	require.NoError(t, err)
+
+	// Other tests leave unmatched entries behind, so compare totals before and after
+	currencyTotals := func() (usd int64, eur int64) {
+		report, err := store.ReportLedgerTx(context.Background(), ReportLedgerTxParams{
+			EndTime: time.Now().Add(time.Second),
+		})
+		require.NoError(t, err)
+		found := discrepancyKinds(report.Discrepancies)
+		return found[[2]int64{usdTreasury.ID, 0}][DiscrepancyCurrencyImbalance].Actual,
+			found[[2]int64{eurTreasury.ID, 0}][DiscrepancyCurrencyImbalance].Actual
+	}
+	usdBefore, eurBefore := currencyTotals()
+
+	// A cross-currency transfer balances in both currencies
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := createRandomAccountWithCurrency(t, util.EUR)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Currency:      util.USD,
+		ToCurrency:    util.EUR,
+		ToAmount:      94,
+		ExchangeRate:  "0.9500000000",
+		SpreadBps:     100,
+	})
+	require.NoError(t, err)
+
+	usd, eur := currencyTotals()
+	require.Equal(t, usdBefore, usd)
+	require.Equal(t, eurBefore, eur)
+
+	report, err := store.ReportLedgerTx(context.Background(), ReportLedgerTxParams{
+		StartTime: time.Now().Add(-time.Minute),
+		EndTime:   time.Now().Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.Empty(t, discrepancyKinds(report.Discrepancies)[[2]int64{0, result.Transfer.ID}])
+
+	// An entry without a matching one throws its currency out
+	entry := createRandomEntry(t, account1)
+	usd, eur = currencyTotals()
+	require.Equal(t, usdBefore+entry.Amount, usd)
+	require.Equal(t, eurBefore, eur)
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, user User) Session {
+	arg := CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: util.RandomString(32),
+		UserAgent:    "test-agent",
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	arg.FamilyID = arg.ID
+
+	session, err := testStore.CreateSession(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ID, session.ID)
+	require.False(t, session.IsBoolean)
+
+	return session
+}
+
+func TestBlockSession(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user)
+
+	// Another user's session is not touched
+	other := createRandomUser(t)
+	_, err := testStore.BlockSession(context.Background(), BlockSessionParams{
+		ID:       session.ID,
+		Username: other.Username,
+	})
+	require.Error(t, err)
+
+	blocked, err := testStore.BlockSession(context.Background(), BlockSessionParams{
+		ID:       session.ID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, blocked.IsBoolean)
+
+	sessions, err := testStore.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+}
+
+func TestBlockOtherSessions(t *testing.T) {
+	user := createRandomUser(t)
+	current := createRandomSession(t, user)
+	for i := 0; i < 3; i++ {
+		createRandomSession(t, user)
+	}
+
+	revoked, err := testStore.BlockOtherSessions(context.Background(), BlockOtherSessionsParams{
+		Username: user.Username,
+		ID:       current.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), revoked)
+
+	sessions, err := testStore.ListActiveSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, current.ID, sessions[0].ID)
+}
+
+func TestRotateSessionTx(t *testing.T) {
+	user := createRandomUser(t)
+	login := createRandomSession(t, user)
+
+	newSessionParams := func() CreateSessionParams {
+		return CreateSessionParams{
+			ID:           uuid.New(),
+			Username:     user.Username,
+			RefreshToken: util.RandomString(32),
+			ExpiresAt:    time.Now().Add(time.Hour),
+		}
+	}
+
+	result, err := testStore.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		OldSessionID: login.ID,
+		NewSession:   newSessionParams(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, login.FamilyID, result.NewSession.FamilyID)
+
+	old, err := testStore.GetSession(context.Background(), login.ID)
+	require.NoError(t, err)
+	require.True(t, old.ReplacedBy.Valid)
+	require.Equal(t, result.NewSession.ID, old.ReplacedBy.UUID)
+
+	// Replaying the first refresh token blocks the whole family
+	_, err = testStore.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		OldSessionID: login.ID,
+		NewSession:   newSessionParams(),
+	})
+	require.ErrorIs(t, err, ErrRefreshTokenReused)
+
+	latest, err := testStore.GetSession(context.Background(), result.NewSession.ID)
+	require.NoError(t, err)
+	require.True(t, latest.IsBoolean)
+
+	_, err = testStore.RotateSessionTx(context.Background(), RotateSessionTxParams{
+		OldSessionID: latest.ID,
+		NewSession:   newSessionParams(),
+	})
+	require.ErrorIs(t, err, ErrSessionBlocked)
+}
@@ -11,6 +11,25 @@ type Store interface {
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
 	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
 	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error)
+	RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error)
+	AdjustAccountBalanceTx(ctx context.Context, arg AdjustAccountBalanceTxParams) (AdjustAccountBalanceTxResult, error)
+	CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error)
+	UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusTxParams) (UpdateAccountStatusTxResult, error)
+	SetOverdraftLimitTx(ctx context.Context, arg SetOverdraftLimitTxParams) (SetOverdraftLimitTxResult, error)
+	RecordAuditEventTx(ctx context.Context, arg AuditEventParams) (AuditEvent, error)
+	EnableMFATx(ctx context.Context, arg EnableMFATxParams) (EnableMFATxResult, error)
+	ConfirmPendingTransferTx(ctx context.Context, arg ConfirmPendingTransferTxParams) (ConfirmPendingTransferTxResult, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error)
+	ConfirmEmailChangeTx(ctx context.Context, arg ConfirmEmailChangeTxParams) (ConfirmEmailChangeTxResult, error)
+	ReconcileLedgerTx(ctx context.Context, arg ReconcileLedgerTxParams) (ReconcileLedgerTxResult, error)
+	ReportLedgerTx(ctx context.Context, arg ReportLedgerTxParams) (ReportLedgerTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (CreateHoldTxResult, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	ReleaseHoldTx(ctx context.Context, arg ReleaseHoldTxParams) (ReleaseHoldTxResult, error)
+	ReserveLoginAttemptTx(ctx context.Context, arg ReserveLoginAttemptTxParams) (ReserveLoginAttemptTxResult, error)
 }
 
 // SQLStore provides all functions to execute SQL  queries and transactions
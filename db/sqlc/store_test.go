@@ -4,14 +4,16 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/OmSingh2003/nimbus/util"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTransferTx(t *testing.T) {
 	store := testStore
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
 	fmt.Println(">> before:", account1.Balance, account2.Balance)
 	 
 	n := 5
@@ -26,6 +28,7 @@ results := make(chan TransferTxResult, n)
 				FromAccountID: account1.ID,
 				ToAccountID:   account2.ID,
 				Amount:        amount,
+				Currency:      util.USD,
 			})
 			errs <- err
 			results <- result
@@ -45,6 +48,7 @@ results := make(chan TransferTxResult, n)
 		require.Equal(t, account1.ID, transfer.FromAccountID)
 		require.Equal(t, account2.ID, transfer.ToAccountID)
 		require.Equal(t, amount, transfer.Amount)
+		require.Equal(t, util.USD, transfer.Currency)
 		require.NotZero(t, transfer.ID)
 		require.NotZero(t, transfer.CreatedAt)
 
@@ -107,8 +111,8 @@ results := make(chan TransferTxResult, n)
 
 func TestTransferTxDeadlock(t *testing.T) {
 	store := testStore
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
 	fmt.Println(">>>> Deadlock test - before:", account1.Balance, account2.Balance)
 	
 	n := 10
@@ -133,6 +137,7 @@ func TestTransferTxDeadlock(t *testing.T) {
 				FromAccountID: fromID,
 				ToAccountID:   toID,
 				Amount:        amount,
+				Currency:      util.USD,
 			})
 			errs <- err
 		}(fromAccountID, toAccountID)
@@ -155,3 +160,858 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+// fundAccount sets the account balance so transfer tests don't depend on the random starting balance
+func fundAccount(t *testing.T, account Account, balance int64) Account {
+	err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: balance,
+	})
+	require.NoError(t, err)
+
+	account.Balance = balance
+	return account
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := testStore
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+
+	// Fund account1 with enough for exactly 2 of the 5 transfers below
+	n := 5
+	amount := int64(10)
+	account1 = fundAccount(t, account1, 2*amount+5)
+
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	}
+	require.Equal(t, 2, succeeded)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), updatedAccount1.Balance)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance+2*amount, updatedAccount2.Balance)
+}
+
+func TestTransferTxOverdraftLimit(t *testing.T) {
+	store := testStore
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+
+	// An empty account with a 30 overdraft can fund 3 transfers of 10
+	n := 5
+	amount := int64(10)
+	account1 = fundAccount(t, account1, 0)
+
+	account1, err := testQueries.UpdateAccountOverdraftLimit(context.Background(), UpdateAccountOverdraftLimitParams{
+		ID:             account1.ID,
+		OverdraftLimit: 3 * amount,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3*amount, account1.OverdraftLimit)
+
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	}
+	require.Equal(t, 3, succeeded)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, -account1.OverdraftLimit, updatedAccount1.Balance)
+}
+
+func TestTransferTxIdempotencyKey(t *testing.T) {
+	store := testStore
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	account1 = fundAccount(t, account1, 1000)
+
+	arg := TransferTxParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         10,
+		Currency:       util.USD,
+		IdempotencyKey: util.RandomString(32),
+	}
+
+	// Concurrent retries with the same key must produce a single transfer
+	n := 5
+	results := make(chan TransferTxResult, n)
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			result, err := store.TransferTx(context.Background(), arg)
+			errs <- err
+			results <- result
+		}()
+	}
+
+	// Every retry gets back the original result, entries and balances included
+	var original TransferTxResult
+	for i := 0; i < n; i++ {
+		require.NoError(t, <-errs)
+		result := <-results
+		require.NotZero(t, result.Transfer.ID)
+		require.NotZero(t, result.FromEntry.ID)
+		require.NotZero(t, result.ToEntry.ID)
+		if original.Transfer.ID == 0 {
+			original = result
+		}
+		requireSameTransferTxResult(t, original, result)
+	}
+
+	// A later retry, once other money has moved, still gets the original result
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        5,
+		Currency:      util.USD,
+	})
+	require.NoError(t, err)
+	replay, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	requireSameTransferTxResult(t, original, replay)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-5-arg.Amount, updatedAccount1.Balance)
+
+	// Reusing the key for a different transfer is a conflict
+	arg.Amount = 20
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyConflict)
+
+	updatedAccount1, err = testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-5-10, updatedAccount1.Balance)
+}
+
+// requireSameTransferTxResult compares two results field by field, with timestamps
+// compared as instants since a replayed result comes back out of JSON
+func requireSameTransferTxResult(t *testing.T, expected, actual TransferTxResult) {
+	require.Equal(t, expected.Transfer.ID, actual.Transfer.ID)
+	require.Equal(t, expected.FromEntry.ID, actual.FromEntry.ID)
+	require.Equal(t, expected.FromEntry.Amount, actual.FromEntry.Amount)
+	require.Equal(t, expected.ToEntry.ID, actual.ToEntry.ID)
+	require.Equal(t, expected.ToEntry.Amount, actual.ToEntry.Amount)
+	require.Equal(t, expected.FromAccount.Balance, actual.FromAccount.Balance)
+	require.Equal(t, expected.ToAccount.Balance, actual.ToAccount.Balance)
+	require.WithinDuration(t, expected.Transfer.CreatedAt, actual.Transfer.CreatedAt, 0)
+}
+
+func TestTransferTxCurrencyMismatch(t *testing.T) {
+	store := testStore
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := createRandomAccountWithCurrency(t, util.EUR)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      util.USD,
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestTransferTxCrossCurrency(t *testing.T) {
+	store := testStore
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := createRandomAccountWithCurrency(t, util.EUR)
+	usdTreasury, err := testQueries.GetTreasuryAccount(context.Background(), util.USD)
+	require.NoError(t, err)
+	eurTreasury, err := testQueries.GetTreasuryAccount(context.Background(), util.EUR)
+	require.NoError(t, err)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Currency:      util.USD,
+		ToCurrency:    util.EUR,
+		ToAmount:      94,
+		ExchangeRate:  "0.9500000000",
+		SpreadBps:     100,
+	})
+	require.NoError(t, err)
+
+	transfer := result.Transfer
+	require.Equal(t, int64(100), transfer.Amount)
+	require.Equal(t, util.USD, transfer.Currency)
+	require.Equal(t, int64(94), transfer.ToAmount)
+	require.Equal(t, util.EUR, transfer.ToCurrency)
+	require.Equal(t, "0.9500000000", transfer.ExchangeRate)
+	require.Equal(t, int64(100), transfer.SpreadBps)
+
+	require.Equal(t, int64(-100), result.FromEntry.Amount)
+	require.Equal(t, util.USD, result.FromEntry.Currency)
+	require.Equal(t, int64(94), result.ToEntry.Amount)
+	require.Equal(t, util.EUR, result.ToEntry.Currency)
+
+	require.Equal(t, account1.Balance-100, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+94, result.ToAccount.Balance)
+
+	// The treasury takes the dollars and pays out the euros, so each currency balances
+	updatedUSDTreasury, err := testQueries.GetTreasuryAccount(context.Background(), util.USD)
+	require.NoError(t, err)
+	require.Equal(t, usdTreasury.Balance+100, updatedUSDTreasury.Balance)
+	updatedEURTreasury, err := testQueries.GetTreasuryAccount(context.Background(), util.EUR)
+	require.NoError(t, err)
+	require.Equal(t, eurTreasury.Balance-94, updatedEURTreasury.Balance)
+
+	// The credit side must match the to account's currency
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Currency:      util.USD,
+		ToCurrency:    util.INR,
+		ToAmount:      8300,
+		ExchangeRate:  "83",
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestTransferTxFrozenAccount(t *testing.T) {
+	store := testStore
+	account1 := createRandomAccountWithCurrency(t, util.USD)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	account1 = fundAccount(t, account1, 100)
+
+	_, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account2.ID,
+		Status: util.AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+
+	// Neither side of a transfer may be frozen
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      util.USD,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+		Currency:      util.USD,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account2.ID,
+		Status: util.AccountStatusActive,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      util.USD,
+	})
+	require.NoError(t, err)
+}
+
+func TestAdjustAccountBalanceTx(t *testing.T) {
+	store := testStore
+	admin := createRandomUser(t)
+	account := createRandomAccountWithCurrency(t, util.USD)
+	account = fundAccount(t, account, 50)
+
+	result, err := store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    -20,
+		Reason:    "duplicate card refund",
+		CreatedBy: admin.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), result.Account.Balance)
+
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, int64(-20), result.Entry.Amount)
+	require.Equal(t, util.USD, result.Entry.Currency)
+	require.Equal(t, result.Transfer.ID, result.Entry.TransferID.Int64)
+
+	// The money goes back to the treasury, so the ledger still balances
+	treasury, err := testQueries.GetTreasuryAccount(context.Background(), util.USD)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, result.Transfer.FromAccountID)
+	require.Equal(t, treasury.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(20), result.Transfer.Amount)
+
+	require.Equal(t, result.Entry.ID, result.Adjustment.EntryID)
+	require.Equal(t, result.Transfer.ID, result.Adjustment.TransferID)
+	require.Equal(t, int64(-20), result.Adjustment.Amount)
+	require.Equal(t, "duplicate card refund", result.Adjustment.Reason)
+	require.Equal(t, admin.Username, result.Adjustment.CreatedBy)
+
+	adjustments, err := testQueries.ListAccountAdjustments(context.Background(), ListAccountAdjustmentsParams{
+		AccountID: account.ID,
+		Limit:     5,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Len(t, adjustments, 1)
+	require.Equal(t, result.Adjustment, adjustments[0])
+
+	// An adjustment can't take the balance past the overdraft limit
+	_, err = store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    -31,
+		Reason:    "too much",
+		CreatedBy: admin.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// A credit comes from the treasury
+	result, err = store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID: account.ID,
+		Amount:    15,
+		Reason:    "missing interest",
+		CreatedBy: admin.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(45), result.Account.Balance)
+	require.Equal(t, treasury.ID, result.Transfer.FromAccountID)
+	require.Equal(t, account.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(15), result.Entry.Amount)
+
+	// The treasury can't be adjusted against itself
+	_, err = store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID: treasury.ID,
+		Amount:    15,
+		Reason:    "missing interest",
+		CreatedBy: admin.Username,
+	})
+	require.ErrorIs(t, err, ErrTreasuryAccount)
+}
+
+func TestAdjustAccountBalanceTxWithHold(t *testing.T) {
+	store := testStore
+	admin := createRandomUser(t)
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+
+	created, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        80,
+		Currency:      util.USD,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Only the 20 that isn't held can be taken out
+	_, err = store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID: account1.ID,
+		Amount:    -21,
+		Reason:    "duplicate card refund",
+		CreatedBy: admin.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err := store.AdjustAccountBalanceTx(context.Background(), AdjustAccountBalanceTxParams{
+		AccountID: account1.ID,
+		Amount:    -20,
+		Reason:    "duplicate card refund",
+		CreatedBy: admin.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(80), result.Account.Balance)
+	require.Equal(t, int64(80), result.Account.HeldAmount)
+
+	// The hold can still be captured in full without overdrawing the account
+	captured, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{ID: created.Hold.ID})
+	require.NoError(t, err)
+	require.Zero(t, captured.FromAccount.Balance)
+	require.Zero(t, captured.FromAccount.HeldAmount)
+}
+
+func TestCloseAccountTx(t *testing.T) {
+	store := testStore
+	account := createRandomAccountWithCurrency(t, util.USD)
+
+	// A funded account can't be closed without somewhere to move the money
+	account = fundAccount(t, account, 100)
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrAccountBalanceNotZero)
+
+	// Nor can its balance be swept to someone else's account
+	otherAccount := createRandomAccountWithCurrency(t, util.USD)
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: otherAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrSweepAccountNotOwned)
+
+	sweepAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    account.Owner,
+		Balance:  0,
+		Currency: util.EUR,
+	})
+	require.NoError(t, err)
+
+	result, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: sweepAccount.ID,
+		QuoteSweep: func(sweep TransferTxParams) (TransferTxParams, error) {
+			sweep.ToAmount = sweep.Amount * 2
+			sweep.ExchangeRate = "2"
+			return sweep, nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.AccountStatusClosed, result.Account.Status)
+	require.True(t, result.Account.ClosedAt.Valid)
+	require.Zero(t, result.Account.Balance)
+
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, int64(100), result.Sweep.Transfer.Amount)
+	require.Equal(t, int64(200), result.Sweep.Transfer.ToAmount)
+	require.Equal(t, int64(200), result.Sweep.ToAccount.Balance)
+
+	// A closed account can't be closed again or take part in transfers
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: sweepAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        10,
+		Currency:      util.EUR,
+		ToCurrency:    util.USD,
+		ToAmount:      5,
+		ExchangeRate:  "0.5",
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+}
+
+func TestCloseAccountTxZeroBalance(t *testing.T) {
+	account := createRandomAccountWithCurrency(t, util.USD)
+	account = fundAccount(t, account, 0)
+
+	result, err := testStore.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.NoError(t, err)
+	require.Equal(t, util.AccountStatusClosed, result.Account.Status)
+	require.Nil(t, result.Sweep)
+}
+
+func TestCreateAccountTxWelcomeCredit(t *testing.T) {
+	store := testStore
+	user := createRandomUser(t)
+
+	treasury, err := testQueries.GetTreasuryAccount(context.Background(), util.EUR)
+	require.NoError(t, err)
+
+	result, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:         user.Username,
+		Currency:      util.EUR,
+		WelcomeCredit: 9500,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, result.Account.Owner)
+	require.Equal(t, int64(9500), result.Account.Balance)
+
+	// The credit is a transfer from the treasury, with an entry on each side
+	transfer := result.WelcomeTransfer
+	require.Equal(t, treasury.ID, transfer.FromAccountID)
+	require.Equal(t, result.Account.ID, transfer.ToAccountID)
+	require.Equal(t, int64(9500), transfer.Amount)
+	require.Equal(t, util.EUR, transfer.Currency)
+
+	entries, err := testQueries.ListEntries(context.Background(), ListEntriesParams{
+		AccountID: result.Account.ID,
+		Limit:     5,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int64(9500), entries[0].Amount)
+	require.Equal(t, transfer.ID, entries[0].TransferID.Int64)
+
+	// Without a welcome credit the account opens empty and no transfer is made
+	other, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:    user.Username,
+		Currency: util.INR,
+	})
+	require.NoError(t, err)
+	require.Zero(t, other.Account.Balance)
+	require.Zero(t, other.WelcomeTransfer.ID)
+}
+
+func TestCreateAccountTxNoTreasuryAccount(t *testing.T) {
+	store := testStore
+	user := createRandomUser(t)
+
+	_, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		Owner:         user.Username,
+		Currency:      "GBP",
+		WelcomeCredit: 100,
+	})
+	require.ErrorIs(t, err, ErrNoTreasuryAccount)
+
+	// The account is rolled back with the failed credit
+	accounts, err := testQueries.ListAccounts(context.Background(), ListAccountsParams{
+		Owner:  user.Username,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	store := testStore
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 0)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Currency:      util.USD,
+	})
+	require.NoError(t, err)
+
+	// A partial refund goes back to the sender as a transfer linked to the original
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     30,
+		Reason:     "partial refund",
+	})
+	require.NoError(t, err)
+
+	reversal := result.Reversal.Transfer
+	require.Equal(t, account2.ID, reversal.FromAccountID)
+	require.Equal(t, account1.ID, reversal.ToAccountID)
+	require.Equal(t, int64(30), reversal.Amount)
+	require.Equal(t, int64(30), reversal.ToAmount)
+	require.Equal(t, transfer.Transfer.ID, reversal.ReversesTransferID.Int64)
+	require.Equal(t, int64(30), result.Original.ReversedAmount)
+	require.Equal(t, TransferPosted, result.Original.Status)
+
+	require.Equal(t, int64(-30), result.Reversal.FromEntry.Amount)
+	require.Equal(t, int64(30), result.Reversal.ToEntry.Amount)
+	require.Equal(t, int64(70), result.Reversal.FromAccount.Balance)
+	require.Equal(t, int64(930), result.Reversal.ToAccount.Balance)
+
+	// A reversal can't itself be reversed
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: reversal.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+
+	// Nor can more than is left of the original
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     71,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	// 0 reverses the rest
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), result.Reversal.Transfer.Amount)
+	require.Equal(t, int64(100), result.Original.ReversedAmount)
+	require.Equal(t, TransferReversed, result.Original.Status)
+	require.Equal(t, TransferPosted, result.Reversal.Transfer.Status)
+	require.Zero(t, result.Reversal.FromAccount.Balance)
+	require.Equal(t, int64(1000), result.Reversal.ToAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+}
+
+func TestReverseTransferTxCrossCurrency(t *testing.T) {
+	store := testStore
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, util.EUR), 0)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Currency:      util.USD,
+		ToCurrency:    util.EUR,
+		ToAmount:      94,
+		ExchangeRate:  "0.9500000000",
+		SpreadBps:     100,
+	})
+	require.NoError(t, err)
+
+	// Refunds are made at the original rate, rounding down
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Amount:     47,
+	})
+	require.NoError(t, err)
+
+	reversal := result.Reversal.Transfer
+	require.Equal(t, int64(47), reversal.Amount)
+	require.Equal(t, util.EUR, reversal.Currency)
+	require.Equal(t, int64(50), reversal.ToAmount)
+	require.Equal(t, util.USD, reversal.ToCurrency)
+	require.Equal(t, "1.0638297872", reversal.ExchangeRate)
+
+	// Reversing the rest gives back exactly what was sent
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(47), result.Reversal.Transfer.Amount)
+	require.Equal(t, int64(50), result.Reversal.Transfer.ToAmount)
+	require.Equal(t, int64(1000), result.Reversal.ToAccount.Balance)
+}
+
+func TestReverseTransferTxForce(t *testing.T) {
+	store := testStore
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 0)
+
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Currency:      util.USD,
+	})
+	require.NoError(t, err)
+
+	// The recipient has already spent the money and been frozen
+	fundAccount(t, account2, 20)
+	_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     account2.ID,
+		Status: util.AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: transfer.Transfer.ID,
+		Force:      true,
+		Reason:     "fraudulent transfer",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-80), result.Reversal.FromAccount.Balance)
+	require.Equal(t, int64(1000), result.Reversal.ToAccount.Balance)
+}
+
+func TestHoldTxCapture(t *testing.T) {
+	store := testStore
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 0)
+
+	created, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        600,
+		Currency:      util.USD,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferPending, created.Hold.Status)
+	require.True(t, created.Hold.HoldExpiresAt.Valid)
+	require.Equal(t, int64(1000), created.FromAccount.Balance)
+	require.Equal(t, int64(600), created.FromAccount.HeldAmount)
+
+	// Held money can't be spent by other transfers
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        500,
+		Currency:      util.USD,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// Nor can the account be closed while the hold is active
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account1.ID})
+	require.ErrorIs(t, err, ErrAccountHasHolds)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		ID:     created.Hold.ID,
+		Amount: 601,
+	})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	// Capturing part of the hold releases the rest
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{
+		ID:     created.Hold.ID,
+		Amount: 250,
+	})
+	require.NoError(t, err)
+	require.Equal(t, created.Hold.ID, result.Transfer.ID)
+	require.Equal(t, TransferPosted, result.Transfer.Status)
+	require.Equal(t, int64(250), result.Transfer.Amount)
+	require.Equal(t, int64(250), result.Transfer.ToAmount)
+	require.Equal(t, int64(-250), result.FromEntry.Amount)
+	require.Equal(t, int64(250), result.ToEntry.Amount)
+	require.Equal(t, int64(750), result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldAmount)
+	require.Equal(t, int64(250), result.ToAccount.Balance)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{ID: created.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+	_, err = store.ReleaseHoldTx(context.Background(), ReleaseHoldTxParams{ID: created.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestHoldTxRelease(t *testing.T) {
+	store := testStore
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 0)
+
+	created, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1000,
+		Currency:      util.USD,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		Currency:      util.USD,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err := store.ReleaseHoldTx(context.Background(), ReleaseHoldTxParams{ID: created.Hold.ID})
+	require.NoError(t, err)
+	require.Equal(t, TransferFailed, result.Hold.Status)
+	require.Equal(t, int64(1000), result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldAmount)
+
+	// A released hold has no entries, so neither balance moved
+	account2, err = store.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Zero(t, account2.Balance)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{ID: created.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestHoldTxExpired(t *testing.T) {
+	store := testStore
+	account1 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := fundAccount(t, createRandomAccountWithCurrency(t, util.USD), 0)
+
+	created, err := store.CreateHoldTx(context.Background(), CreateHoldTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		Currency:      util.USD,
+		ExpiresAt:     time.Now().Add(-time.Second),
+	})
+	require.NoError(t, err)
+
+	// An expired hold can't be captured even before the expiry task releases it
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{ID: created.Hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	ids, err := store.ListExpiredHolds(context.Background(), ListExpiredHoldsParams{
+		ExpiredBefore: time.Now(),
+		Limit:         1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, ids, created.Hold.ID)
+
+	result, err := store.ReleaseHoldTx(context.Background(), ReleaseHoldTxParams{
+		ID:      created.Hold.ID,
+		Expired: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferFailed, result.Hold.Status)
+	require.Zero(t, result.FromAccount.HeldAmount)
+
+	ids, err = store.ListExpiredHolds(context.Background(), ListExpiredHoldsParams{
+		ExpiredBefore: time.Now(),
+		Limit:         1000,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, ids, created.Hold.ID)
+}
+
+func TestSetOverdraftLimitTx(t *testing.T) {
+	account := createRandomAccount(t)
+
+	result, err := testStore.SetOverdraftLimitTx(context.Background(), SetOverdraftLimitTxParams{
+		UpdateAccountOverdraftLimitParams: UpdateAccountOverdraftLimitParams{
+			ID:             account.ID,
+			OverdraftLimit: 500,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(500), result.Account.OverdraftLimit)
+	require.Equal(t, account.Balance, result.Account.Balance)
+
+	// Closed accounts keep their limit
+	_, err = testQueries.CloseAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+
+	_, err = testStore.SetOverdraftLimitTx(context.Background(), SetOverdraftLimitTxParams{
+		UpdateAccountOverdraftLimitParams: UpdateAccountOverdraftLimitParams{
+			ID:             account.ID,
+			OverdraftLimit: 1000,
+		},
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -14,6 +15,8 @@ func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Amount:        util.RandomMoney(),
+		Currency:      account1.Currency,
+		Status:        TransferPosted,
 	}
 
 	transfer, err := testStore.CreateTransfer(context.Background(), arg)
@@ -23,6 +26,8 @@ func createRandomTransfer(t *testing.T, account1, account2 Account) Transfer {
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.Currency, transfer.Currency)
+	require.Equal(t, TransferPosted, transfer.Status)
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -62,10 +67,9 @@ func TestListTransfer(t *testing.T) {
 	}
 
 	arg := ListTransfersParams{
-		FromAccountID: account1.ID,
-		ToAccountID:   account1.ID,
-		Limit:         5,
-		Offset:        5,
+		AccountID: account1.ID,
+		Limit:     5,
+		Offset:    5,
 	}
 
 	transfers, err := testStore.ListTransfers(context.Background(), arg)
@@ -77,3 +81,130 @@ func TestListTransfer(t *testing.T) {
 		require.True(t, transfer.FromAccountID == account1.ID || transfer.ToAccountID == account1.ID)
 	}
 }
+
+func TestListUserTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	var amounts []int64
+	for i := 0; i < 5; i++ {
+		amounts = append(amounts, createRandomTransfer(t, account1, account2).Amount)
+		createRandomTransfer(t, account2, account1)
+	}
+
+	arg := ListUserTransfersParams{
+		Owner:  account1.Owner,
+		Limit:  20,
+		Offset: 0,
+	}
+
+	transfers, err := testStore.ListUserTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 10)
+
+	// Newest first
+	for i := 1; i < len(transfers); i++ {
+		require.False(t, transfers[i].CreatedAt.After(transfers[i-1].CreatedAt))
+	}
+
+	count, err := testStore.CountUserTransfers(context.Background(), CountUserTransfersParams{
+		Owner: account1.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(10), count)
+
+	// Only transfers sent from the user's accounts
+	arg.Direction = sql.NullString{String: "out", Valid: true}
+	transfers, err = testStore.ListUserTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 5)
+	for _, transfer := range transfers {
+		require.Equal(t, account1.ID, transfer.FromAccountID)
+	}
+
+	// Amount range on outgoing transfers
+	minAmount := amounts[0]
+	arg.MinAmount = sql.NullInt64{Int64: minAmount, Valid: true}
+	transfers, err = testStore.ListUserTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	for _, transfer := range transfers {
+		require.GreaterOrEqual(t, transfer.Amount, minAmount)
+	}
+
+	// Status filter
+	transfers, err = testStore.ListUserTransfers(context.Background(), ListUserTransfersParams{
+		Owner:  account1.Owner,
+		Status: sql.NullString{String: TransferPending, Valid: true},
+		Limit:  20,
+	})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+
+	// Nothing before the transfers were created
+	transfers, err = testStore.ListUserTransfers(context.Background(), ListUserTransfersParams{
+		Owner:   account1.Owner,
+		EndTime: sql.NullTime{Time: account1.CreatedAt, Valid: true},
+		Limit:   20,
+	})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+
+	// Another user's account filter returns nothing
+	transfers, err = testStore.ListUserTransfers(context.Background(), ListUserTransfersParams{
+		Owner:     account1.Owner,
+		AccountID: sql.NullInt64{Int64: account2.ID, Valid: true},
+		Limit:     20,
+	})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestSetTransferStatus(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	transfer, err := testStore.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Currency:      account1.Currency,
+		ToAmount:      10,
+		ToCurrency:    account1.Currency,
+		ExchangeRate:  "1",
+		Status:        TransferPending,
+	})
+	require.NoError(t, err)
+
+	posted, err := setTransferStatus(context.Background(), testQueries, transfer, TransferPosted)
+	require.NoError(t, err)
+	require.Equal(t, TransferPosted, posted.Status)
+
+	// Posted transfers can't go back to pending or fail
+	_, err = setTransferStatus(context.Background(), testQueries, posted, TransferPending)
+	require.ErrorIs(t, err, ErrInvalidTransferStatus)
+	_, err = setTransferStatus(context.Background(), testQueries, posted, TransferFailed)
+	require.ErrorIs(t, err, ErrInvalidTransferStatus)
+
+	// A stale read doesn't overwrite the newer status
+	_, err = setTransferStatus(context.Background(), testQueries, transfer, TransferFailed)
+	require.ErrorIs(t, err, ErrInvalidTransferStatus)
+
+	// The database refuses the transition even when the query is run directly
+	_, err = testQueries.UpdateTransferStatus(context.Background(), UpdateTransferStatusParams{
+		Status:     TransferPending,
+		ID:         posted.ID,
+		FromStatus: TransferPosted,
+	})
+	require.Error(t, err)
+}
+
+func TestCanTransitionTransferStatus(t *testing.T) {
+	require.True(t, CanTransitionTransferStatus(TransferPending, TransferPosted))
+	require.True(t, CanTransitionTransferStatus(TransferPending, TransferFailed))
+	require.True(t, CanTransitionTransferStatus(TransferPosted, TransferReversed))
+
+	require.False(t, CanTransitionTransferStatus(TransferPending, TransferReversed))
+	require.False(t, CanTransitionTransferStatus(TransferPosted, TransferFailed))
+	require.False(t, CanTransitionTransferStatus(TransferFailed, TransferPosted))
+	require.False(t, CanTransitionTransferStatus(TransferReversed, TransferPosted))
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Statuses of a transfer
+const (
+	// TransferPending has been recorded but hasn't moved any money yet
+	TransferPending = "pending"
+	// TransferPosted has its entries and has moved the money
+	TransferPosted = "posted"
+	// TransferFailed was never posted and never will be
+	TransferFailed = "failed"
+	// TransferReversed was posted and has since been reversed in full
+	TransferReversed = "reversed"
+)
+
+// ErrInvalidTransferStatus is returned when a transfer can't move from its current
+// status to the one asked for.
+var ErrInvalidTransferStatus = errors.New("invalid transfer status transition")
+
+// transferStatusTransitions lists the statuses each status can move to; failed and
+// reversed are final. The database enforces the same transitions with a trigger.
+var transferStatusTransitions = map[string][]string{
+	TransferPending: {TransferPosted, TransferFailed},
+	TransferPosted:  {TransferReversed},
+}
+
+// CanTransitionTransferStatus reports whether a transfer in status from can move to status to
+func CanTransitionTransferStatus(from string, to string) bool {
+	for _, status := range transferStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transferMovesMoney reports whether a transfer in status should have its entries
+func transferMovesMoney(status string) bool {
+	return status == TransferPosted || status == TransferReversed
+}
+
+// setTransferStatus moves a transfer to a new status. The update only applies if the
+// transfer is still in the status it was read with, so a concurrent change is reported
+// as ErrInvalidTransferStatus rather than overwritten.
+func setTransferStatus(ctx context.Context, q *Queries, transfer Transfer, status string) (Transfer, error) {
+	if !CanTransitionTransferStatus(transfer.Status, status) {
+		return transfer, fmt.Errorf("%w: %s to %s", ErrInvalidTransferStatus, transfer.Status, status)
+	}
+
+	updated, err := q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+		Status:     status,
+		ID:         transfer.ID,
+		FromStatus: transfer.Status,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return transfer, fmt.Errorf("%w: transfer %d is no longer %s", ErrInvalidTransferStatus, transfer.ID, transfer.Status)
+	}
+	return updated, err
+}
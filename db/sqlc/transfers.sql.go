@@ -7,26 +7,165 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const addTransferReversedAmount = `-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, exchange_rate, spread_bps, reverses_transfer_id, reversed_amount, status, hold_expires_at, status_changed_at
+`
+
+type AddTransferReversedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, addTransferReversedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversesTransferID,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.HoldExpiresAt,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const countUserTransfers = `-- name: CountUserTransfers :one
+SELECT COUNT(*) FROM transfers AS t
+WHERE EXISTS (
+    SELECT 1 FROM account AS a
+    WHERE a.owner = $1
+      AND ($2::bigint IS NULL OR a.id = $2)
+      AND (
+        (a.id = t.from_account_id AND COALESCE($3::varchar, 'out') = 'out') OR
+        (a.id = t.to_account_id AND COALESCE($3::varchar, 'in') = 'in')
+      )
+  )
+  AND ($4::timestamptz IS NULL OR t.created_at >= $4)
+  AND ($5::timestamptz IS NULL OR t.created_at < $5)
+  AND ($6::bigint IS NULL OR t.amount >= $6)
+  AND ($7::bigint IS NULL OR t.amount <= $7)
+  AND ($8::varchar IS NULL OR t.status = $8)
+`
+
+type CountUserTransfersParams struct {
+	Owner     string         `json:"owner"`
+	AccountID sql.NullInt64  `json:"account_id"`
+	Direction sql.NullString `json:"direction"`
+	StartTime sql.NullTime   `json:"start_time"`
+	EndTime   sql.NullTime   `json:"end_time"`
+	MinAmount sql.NullInt64  `json:"min_amount"`
+	MaxAmount sql.NullInt64  `json:"max_amount"`
+	Status    sql.NullString `json:"status"`
+}
+
+func (q *Queries) CountUserTransfers(ctx context.Context, arg CountUserTransfersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserTransfers,
+		arg.Owner,
+		arg.AccountID,
+		arg.Direction,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Status,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  currency,
+  to_amount,
+  to_currency,
+  exchange_rate,
+  spread_bps,
+  reverses_transfer_id,
+  status,
+  hold_expires_at
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, exchange_rate, spread_bps, reverses_transfer_id, reversed_amount, status, hold_expires_at, status_changed_at
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID      int64         `json:"from_account_id"`
+	ToAccountID        int64         `json:"to_account_id"`
+	Amount             int64         `json:"amount"`
+	Currency           string        `json:"currency"`
+	ToAmount           int64         `json:"to_amount"`
+	ToCurrency         string        `json:"to_currency"`
+	ExchangeRate       string        `json:"exchange_rate"`
+	SpreadBps          int64         `json:"spread_bps"`
+	ReversesTransferID sql.NullInt64 `json:"reverses_transfer_id"`
+	Status             string        `json:"status"`
+	HoldExpiresAt      sql.NullTime  `json:"hold_expires_at"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Currency,
+		arg.ToAmount,
+		arg.ToCurrency,
+		arg.ExchangeRate,
+		arg.SpreadBps,
+		arg.ReversesTransferID,
+		arg.Status,
+		arg.HoldExpiresAt,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversesTransferID,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.HoldExpiresAt,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const getHoldForUpdate = `-- name: GetHoldForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, exchange_rate, spread_bps, reverses_transfer_id, reversed_amount, status, hold_expires_at, status_changed_at FROM transfers
+WHERE id = $1 AND hold_expires_at IS NOT NULL LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetHoldForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getHoldForUpdate, id)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +173,22 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversesTransferID,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.HoldExpiresAt,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, exchange_rate, spread_bps, reverses_transfer_id, reversed_amount, status, hold_expires_at, status_changed_at FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,31 +201,188 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversesTransferID,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.HoldExpiresAt,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, exchange_rate, spread_bps, reverses_transfer_id, reversed_amount, status, hold_expires_at, status_changed_at FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversesTransferID,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.HoldExpiresAt,
+		&i.StatusChangedAt,
 	)
 	return i, err
 }
 
+const listExpiredHolds = `-- name: ListExpiredHolds :many
+SELECT id FROM transfers
+WHERE status = 'pending' AND hold_expires_at <= $1
+ORDER BY hold_expires_at
+LIMIT $2
+`
+
+type ListExpiredHoldsParams struct {
+	ExpiredBefore time.Time `json:"expired_before"`
+	Limit         int32     `json:"limit"`
+}
+
+func (q *Queries) ListExpiredHolds(ctx context.Context, arg ListExpiredHoldsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredHolds, arg.ExpiredBefore, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
-WHERE 
+SELECT id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, exchange_rate, spread_bps, reverses_transfer_id, reversed_amount, status, hold_expires_at, status_changed_at FROM transfers
+WHERE
     from_account_id = $1 OR
-    to_account_id = $2
+    to_account_id = $1
 ORDER BY id
-LIMIT $3
-OFFSET $4
+LIMIT $2
+OFFSET $3
 `
 
 type ListTransfersParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Limit         int32 `json:"limit"`
-	Offset        int32 `json:"offset"`
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
 }
 
 func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error) {
-	rows, err := q.db.QueryContext(ctx, listTransfers,
-		arg.FromAccountID,
-		arg.ToAccountID,
+	rows, err := q.db.QueryContext(ctx, listTransfers, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transfer
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Currency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+			&i.ReversesTransferID,
+			&i.ReversedAmount,
+			&i.Status,
+			&i.HoldExpiresAt,
+			&i.StatusChangedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserTransfers = `-- name: ListUserTransfers :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.currency, t.to_amount, t.to_currency, t.exchange_rate, t.spread_bps, t.reverses_transfer_id, t.reversed_amount, t.status, t.hold_expires_at, t.status_changed_at FROM transfers AS t
+WHERE EXISTS (
+    SELECT 1 FROM account AS a
+    WHERE a.owner = $1
+      AND ($2::bigint IS NULL OR a.id = $2)
+      AND (
+        (a.id = t.from_account_id AND COALESCE($3::varchar, 'out') = 'out') OR
+        (a.id = t.to_account_id AND COALESCE($3::varchar, 'in') = 'in')
+      )
+  )
+  AND ($4::timestamptz IS NULL OR t.created_at >= $4)
+  AND ($5::timestamptz IS NULL OR t.created_at < $5)
+  AND ($6::bigint IS NULL OR t.amount >= $6)
+  AND ($7::bigint IS NULL OR t.amount <= $7)
+  AND ($8::varchar IS NULL OR t.status = $8)
+  AND ($9::timestamptz IS NULL OR (t.created_at, t.id) < ($9, $10::bigint))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $11
+OFFSET $12
+`
+
+type ListUserTransfersParams struct {
+	Owner           string         `json:"owner"`
+	AccountID       sql.NullInt64  `json:"account_id"`
+	Direction       sql.NullString `json:"direction"`
+	StartTime       sql.NullTime   `json:"start_time"`
+	EndTime         sql.NullTime   `json:"end_time"`
+	MinAmount       sql.NullInt64  `json:"min_amount"`
+	MaxAmount       sql.NullInt64  `json:"max_amount"`
+	Status          sql.NullString `json:"status"`
+	BeforeCreatedAt sql.NullTime   `json:"before_created_at"`
+	BeforeID        sql.NullInt64  `json:"before_id"`
+	Limit           int32          `json:"limit"`
+	Offset          int32          `json:"offset"`
+}
+
+func (q *Queries) ListUserTransfers(ctx context.Context, arg ListUserTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listUserTransfers,
+		arg.Owner,
+		arg.AccountID,
+		arg.Direction,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Status,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
 		arg.Limit,
 		arg.Offset,
 	)
@@ -93,6 +399,16 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Currency,
+			&i.ToAmount,
+			&i.ToCurrency,
+			&i.ExchangeRate,
+			&i.SpreadBps,
+			&i.ReversesTransferID,
+			&i.ReversedAmount,
+			&i.Status,
+			&i.HoldExpiresAt,
+			&i.StatusChangedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -106,3 +422,75 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const updateHoldAmount = `-- name: UpdateHoldAmount :one
+UPDATE transfers
+SET amount = $2, to_amount = $3
+WHERE id = $1 AND status = 'pending'
+RETURNING id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, exchange_rate, spread_bps, reverses_transfer_id, reversed_amount, status, hold_expires_at, status_changed_at
+`
+
+type UpdateHoldAmountParams struct {
+	ID       int64 `json:"id"`
+	Amount   int64 `json:"amount"`
+	ToAmount int64 `json:"to_amount"`
+}
+
+func (q *Queries) UpdateHoldAmount(ctx context.Context, arg UpdateHoldAmountParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateHoldAmount, arg.ID, arg.Amount, arg.ToAmount)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversesTransferID,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.HoldExpiresAt,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE transfers
+SET status = $1, status_changed_at = now()
+WHERE id = $2 AND status = $3
+RETURNING id, from_account_id, to_account_id, amount, created_at, currency, to_amount, to_currency, exchange_rate, spread_bps, reverses_transfer_id, reversed_amount, status, hold_expires_at, status_changed_at
+`
+
+type UpdateTransferStatusParams struct {
+	Status     string `json:"status"`
+	ID         int64  `json:"id"`
+	FromStatus string `json:"from_status"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferStatus, arg.Status, arg.ID, arg.FromStatus)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Currency,
+		&i.ToAmount,
+		&i.ToCurrency,
+		&i.ExchangeRate,
+		&i.SpreadBps,
+		&i.ReversesTransferID,
+		&i.ReversedAmount,
+		&i.Status,
+		&i.HoldExpiresAt,
+		&i.StatusChangedAt,
+	)
+	return i, err
+}
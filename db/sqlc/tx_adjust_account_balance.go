@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+
+	"github.com/OmSingh2003/nimbus/util"
+)
+
+// AdjustAccountBalanceTxParams describes a balance correction posted by an admin
+type AdjustAccountBalanceTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Amount is added to the balance; negative to take money out
+	Amount    int64  `json:"amount"`
+	Reason    string `json:"reason"`
+	CreatedBy string `json:"created_by"`
+	// Audit.Actor is ignored; the adjustment is audited as made by CreatedBy
+	Audit AuditContext `json:"-"`
+}
+
+// AdjustAccountBalanceTxResult is the result of AdjustAccountBalanceTx
+type AdjustAccountBalanceTxResult struct {
+	Account Account `json:"account"`
+	// Entry is the adjusted account's side of Transfer
+	Entry      Entry             `json:"entry"`
+	Transfer   Transfer          `json:"transfer"`
+	Adjustment AccountAdjustment `json:"adjustment"`
+}
+
+// AdjustAccountBalanceTx corrects an account balance with a transfer from the treasury account
+// in its currency, or back to it for a negative amount, recording who posted it and why.
+// Frozen accounts can still be adjusted, closed ones can't.
+// It returns ErrAccountClosed if the account is closed, ErrInsufficientFunds if the adjustment
+// would take the balance less its holds below the overdraft limit, ErrNoTreasuryAccount if there is no treasury
+// account in its currency and ErrTreasuryAccount if it is a treasury account itself.
+func (store *SQLStore) AdjustAccountBalanceTx(ctx context.Context, arg AdjustAccountBalanceTxParams) (AdjustAccountBalanceTxResult, error) {
+	var result AdjustAccountBalanceTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		treasury, account, err := lockWithTreasury(ctx, q, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Status == util.AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		// Money reserved by holds must still be there when they are captured
+		if availableBalance(account)+arg.Amount < -account.OverdraftLimit {
+			return ErrInsufficientFunds
+		}
+
+		var posted TransferTxResult
+		err = postTreasuryTransfer(ctx, q, treasury, account, arg.Amount, &posted)
+		if err != nil {
+			return err
+		}
+
+		result.Transfer = posted.Transfer
+		result.Account, result.Entry = posted.ToAccount, posted.ToEntry
+		if arg.Amount < 0 {
+			result.Account, result.Entry = posted.FromAccount, posted.FromEntry
+		}
+
+		result.Adjustment, err = q.CreateAccountAdjustment(ctx, CreateAccountAdjustmentParams{
+			AccountID:  arg.AccountID,
+			EntryID:    result.Entry.ID,
+			TransferID: result.Transfer.ID,
+			Amount:     arg.Amount,
+			Reason:     arg.Reason,
+			CreatedBy:  arg.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		audit := arg.Audit
+		audit.Actor = arg.CreatedBy
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  account.Owner,
+			EventType: AuditBalanceAdjusted,
+			Details: map[string]any{
+				"account_id":    account.ID,
+				"entry_id":      result.Entry.ID,
+				"transfer_id":   result.Transfer.ID,
+				"adjustment_id": result.Adjustment.ID,
+				"amount":        arg.Amount,
+				"reason":        arg.Reason,
+			},
+			Audit: audit,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAccountBalanceNotZero is returned by CloseAccountTx when the account still holds
+// money and no account to sweep it to was given, or when the account is overdrawn.
+var ErrAccountBalanceNotZero = errors.New("account balance is not zero")
+
+// ErrAccountHasHolds is returned by CloseAccountTx when money on the account is still
+// reserved by holds that haven't been captured or released.
+var ErrAccountHasHolds = errors.New("account has active holds")
+
+// ErrSweepAccountNotOwned is returned by CloseAccountTx when the account to sweep the
+// balance to belongs to someone else.
+var ErrSweepAccountNotOwned = errors.New("sweep account belongs to another user")
+
+// CloseAccountTxParams contains the input parameters of CloseAccountTx
+type CloseAccountTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// SweepToAccountID is optional; the remaining balance is transferred there before closing
+	SweepToAccountID int64 `json:"sweep_to_account_id"`
+	// QuoteSweep fills in the credit side of the sweep (ToAmount, ExchangeRate and SpreadBps)
+	// once the balance is known. It is only called when the sweep account is in another currency.
+	QuoteSweep func(sweep TransferTxParams) (TransferTxParams, error) `json:"-"`
+	Audit      AuditContext                                           `json:"-"`
+}
+
+// CloseAccountTxResult is the result of CloseAccountTx
+type CloseAccountTxResult struct {
+	Account Account `json:"account"`
+	// Sweep is nil if there was no balance to move
+	Sweep *TransferTxResult `json:"sweep"`
+}
+
+// CloseAccountTx closes an active account. An account holding money can only be closed
+// by sweeping its balance to another active account of the same owner, which is recorded
+// as a regular transfer, converted with QuoteSweep if the currencies differ.
+// It returns ErrAccountBalanceNotZero, ErrAccountHasHolds, ErrSweepAccountNotOwned,
+// ErrAccountFrozen or ErrAccountClosed when the account can't be closed, and ErrCurrencyMismatch when a
+// sweep across currencies has no QuoteSweep.
+func (store *SQLStore) CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error) {
+	var result CloseAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var account, sweepAccount Account
+		var err error
+
+		if arg.SweepToAccountID == 0 || arg.SweepToAccountID == arg.AccountID {
+			account, err = q.GetAccountForUpdate(ctx, arg.AccountID)
+		} else {
+			account, sweepAccount, err = lockAccountPair(ctx, q, arg.AccountID, arg.SweepToAccountID)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := checkAccountActive(account); err != nil {
+			return err
+		}
+
+		if account.HeldAmount != 0 {
+			return ErrAccountHasHolds
+		}
+
+		if account.Balance != 0 {
+			if account.Balance < 0 || sweepAccount.ID == 0 {
+				return ErrAccountBalanceNotZero
+			}
+			if sweepAccount.Owner != account.Owner {
+				return ErrSweepAccountNotOwned
+			}
+			if err := checkAccountActive(sweepAccount); err != nil {
+				return err
+			}
+
+			sweep := TransferTxParams{
+				FromAccountID: account.ID,
+				ToAccountID:   sweepAccount.ID,
+				Amount:        account.Balance,
+				Currency:      account.Currency,
+				ToCurrency:    sweepAccount.Currency,
+			}
+			if sweep.ToCurrency != sweep.Currency {
+				if arg.QuoteSweep == nil {
+					return ErrCurrencyMismatch
+				}
+				sweep, err = arg.QuoteSweep(sweep)
+				if err != nil {
+					return err
+				}
+			}
+			sweep, err = normalizeTransferTxParams(sweep)
+			if err != nil {
+				return err
+			}
+			if sweep.ToCurrency != sweepAccount.Currency {
+				return ErrCurrencyMismatch
+			}
+
+			result.Sweep = &TransferTxResult{}
+			err = postTransfer(ctx, q, sweep, result.Sweep)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Account, err = q.CloseAccount(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		details := map[string]any{"account_id": account.ID}
+		if result.Sweep != nil {
+			details["sweep_transfer"] = transferAuditDetails(result.Sweep.Transfer)
+		}
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  account.Owner,
+			EventType: AuditAccountClosed,
+			Details:   details,
+			Audit:     arg.Audit,
+		})
+		return err
+	})
+
+	return result, err
+}
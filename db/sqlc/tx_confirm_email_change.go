@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var (
+	// ErrEmailChangeSuperseded is returned by ConfirmEmailChangeTx when the user's
+	// email changed after the link was sent
+	ErrEmailChangeSuperseded = errors.New("email has changed since this link was sent")
+	// ErrEmailTaken is returned by ConfirmEmailChangeTx when another user has the new email
+	ErrEmailTaken = errors.New("email is already in use")
+)
+
+// ConfirmEmailChangeTxParams contains the input parameters of ConfirmEmailChangeTx
+type ConfirmEmailChangeTxParams struct {
+	ChangeID int64 `json:"change_id"`
+	// SecretHash is the hash of the code from the verification link
+	SecretHash string       `json:"-"`
+	Audit      AuditContext `json:"-"`
+}
+
+// ConfirmEmailChangeTxResult is the result of ConfirmEmailChangeTx
+type ConfirmEmailChangeTxResult struct {
+	User        User        `json:"user"`
+	EmailChange EmailChange `json:"email_change"`
+}
+
+// ConfirmEmailChangeTx uses up an email change link and switches the user to the
+// new, now verified, address. It returns sql.ErrNoRows if the link is unknown,
+// used or expired.
+func (store *SQLStore) ConfirmEmailChangeTx(ctx context.Context, arg ConfirmEmailChangeTxParams) (ConfirmEmailChangeTxResult, error) {
+	var result ConfirmEmailChangeTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.EmailChange, err = q.UseEmailChange(ctx, UseEmailChangeParams{
+			ID:         arg.ChangeID,
+			SecretHash: arg.SecretHash,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Only the latest change can apply; confirming any other would undo it
+		user, err := q.GetUser(ctx, result.EmailChange.Username)
+		if err != nil {
+			return err
+		}
+		if user.Email != result.EmailChange.OldEmail {
+			return ErrEmailChangeSuperseded
+		}
+
+		_, err = q.GetUserByEmail(ctx, result.EmailChange.NewEmail)
+		if err == nil {
+			return ErrEmailTaken
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+			Username: user.Username,
+			Email: sql.NullString{
+				String: result.EmailChange.NewEmail,
+				Valid:  true,
+			},
+			IsEmailVerified: sql.NullBool{
+				Bool:  true,
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  result.User.Username,
+			EventType: AuditEmailChanged,
+			Audit:     arg.Audit,
+		})
+		return err
+	})
+
+	return result, err
+}
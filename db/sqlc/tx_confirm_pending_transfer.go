@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Statuses of a pending transfer
+const (
+	PendingTransferPending   = "pending"
+	PendingTransferConfirmed = "confirmed"
+	PendingTransferExpired   = "expired"
+)
+
+// ErrPendingTransferNotPending is returned by ConfirmPendingTransferTx when the
+// transfer was already confirmed or has expired.
+var ErrPendingTransferNotPending = errors.New("pending transfer is no longer pending")
+
+// ConfirmPendingTransferTxParams contains the input parameters of ConfirmPendingTransferTx
+type ConfirmPendingTransferTxParams struct {
+	ID    int64        `json:"id"`
+	Audit AuditContext `json:"-"`
+}
+
+// ConfirmPendingTransferTxResult is the result of ConfirmPendingTransferTx
+type ConfirmPendingTransferTxResult struct {
+	PendingTransfer PendingTransfer `json:"pending_transfer"`
+	TransferTxResult
+}
+
+// ConfirmPendingTransferTx makes a held transfer once the user has re-authenticated,
+// at the amounts they were quoted when they asked for it.
+// It returns ErrPendingTransferNotPending if the transfer was already confirmed or
+// has expired, and the errors of TransferTx if the transfer can't be made.
+func (store *SQLStore) ConfirmPendingTransferTx(ctx context.Context, arg ConfirmPendingTransferTxParams) (ConfirmPendingTransferTxResult, error) {
+	var result ConfirmPendingTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Lock the pending transfer so it can only be confirmed once
+		pending, err := q.GetPendingTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if pending.Status != PendingTransferPending || !time.Now().Before(pending.ExpiresAt) {
+			return ErrPendingTransferNotPending
+		}
+
+		transferArg, err := normalizeTransferTxParams(TransferTxParams{
+			FromAccountID:  pending.FromAccountID,
+			ToAccountID:    pending.ToAccountID,
+			Amount:         pending.Amount,
+			Currency:       pending.Currency,
+			ToCurrency:     pending.ToCurrency,
+			ToAmount:       pending.ToAmount,
+			ExchangeRate:   pending.ExchangeRate,
+			SpreadBps:      pending.SpreadBps,
+			IdempotencyKey: pending.IdempotencyKey,
+			Audit:          arg.Audit,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = transferTx(ctx, q, transferArg, &result.TransferTxResult)
+		if err != nil {
+			return err
+		}
+
+		result.PendingTransfer, err = q.ConfirmPendingTransfer(ctx, ConfirmPendingTransferParams{
+			ID:         pending.ID,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
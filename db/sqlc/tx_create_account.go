@@ -0,0 +1,82 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// CreateAccountTxParams contains the input parameters of CreateAccountTx
+type CreateAccountTxParams struct {
+	Owner         string         `json:"owner"`
+	Currency      string         `json:"currency"`
+	AccountNumber sql.NullString `json:"account_number"`
+	// WelcomeCredit is transferred in from the treasury account in Currency; zero opens the account empty
+	WelcomeCredit int64        `json:"welcome_credit"`
+	Audit         AuditContext `json:"-"`
+}
+
+// CreateAccountTxResult is the result of CreateAccountTx
+type CreateAccountTxResult struct {
+	Account Account `json:"account"`
+	// WelcomeTransfer is the transfer from the treasury; empty when there was no welcome credit
+	WelcomeTransfer Transfer `json:"welcome_transfer"`
+}
+
+// CreateAccountTx opens an account, funds its welcome credit from the treasury and
+// records it in the owner's audit log.
+// It returns ErrNoTreasuryAccount if there is a welcome credit but no treasury account in the currency.
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error) {
+	var result CreateAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		return createAccountTx(ctx, q, arg, &result)
+	})
+
+	return result, err
+}
+
+// createAccountTx runs the steps of CreateAccountTx in an open transaction, filling in result.
+func createAccountTx(ctx context.Context, q *Queries, arg CreateAccountTxParams, result *CreateAccountTxResult) error {
+	var err error
+
+	// Accounts always open empty so every unit in them has an entry behind it
+	result.Account, err = q.CreateAccount(ctx, CreateAccountParams{
+		Owner:         arg.Owner,
+		Balance:       0,
+		Currency:      arg.Currency,
+		AccountNumber: arg.AccountNumber,
+	})
+	if err != nil {
+		return err
+	}
+
+	details := accountAuditDetails(result.Account)
+	if arg.WelcomeCredit > 0 {
+		credit, err := creditFromTreasury(ctx, q, result.Account.ID, arg.WelcomeCredit)
+		if err != nil {
+			return err
+		}
+		result.Account = credit.ToAccount
+		result.WelcomeTransfer = credit.Transfer
+
+		details = accountAuditDetails(result.Account)
+		details["welcome_transfer_id"] = credit.Transfer.ID
+	}
+
+	_, err = appendAuditEvent(ctx, q, AuditEventParams{
+		Username:  result.Account.Owner,
+		EventType: AuditAccountCreated,
+		Details:   details,
+		Audit:     arg.Audit,
+	})
+	return err
+}
+
+// accountAuditDetails describes an account for the audit log
+func accountAuditDetails(account Account) map[string]any {
+	return map[string]any{
+		"account_id": account.ID,
+		"currency":   account.Currency,
+		"balance":    account.Balance,
+	}
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+)
+
+// EnableMFATxParams contains the input parameters of EnableMFATx
+type EnableMFATxParams struct {
+	EnableUserMFAParams
+	// RecoveryCodeHashes replace any recovery codes the user had before
+	RecoveryCodeHashes []string     `json:"-"`
+	Audit              AuditContext `json:"-"`
+}
+
+// EnableMFATxResult is the result of EnableMFATx
+type EnableMFATxResult struct {
+	UserMFA UserMfa `json:"user_mfa"`
+}
+
+// EnableMFATx finishes two-factor enrollment once the user has confirmed a code,
+// stores their recovery codes and records it in their audit log.
+// It returns sql.ErrNoRows if there is no unconfirmed enrollment.
+func (store *SQLStore) EnableMFATx(ctx context.Context, arg EnableMFATxParams) (EnableMFATxResult, error) {
+	var result EnableMFATxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.UserMFA, err = q.EnableUserMFA(ctx, arg.EnableUserMFAParams)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteMFARecoveryCodes(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		for _, codeHash := range arg.RecoveryCodeHashes {
+			_, err = q.CreateMFARecoveryCode(ctx, CreateMFARecoveryCodeParams{
+				Username: arg.Username,
+				CodeHash: codeHash,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  arg.Username,
+			EventType: AuditMFAEnabled,
+			Details:   map[string]any{"recovery_codes": len(arg.RecoveryCodeHashes)},
+			Audit:     arg.Audit,
+		})
+		return err
+	})
+
+	return result, err
+}
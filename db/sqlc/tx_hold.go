@@ -0,0 +1,294 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// ErrHoldNotActive is returned by CaptureHoldTx and ReleaseHoldTx when the hold was
+// already captured, released or has expired.
+var ErrHoldNotActive = errors.New("hold is no longer active")
+
+// ErrCaptureExceedsHold is returned by CaptureHoldTx when the amount is more than was held.
+var ErrCaptureExceedsHold = errors.New("capture exceeds the held amount")
+
+// ErrCaptureTooSmall is returned by CaptureHoldTx when a partial capture of a
+// cross-currency hold would credit nothing once converted.
+var ErrCaptureTooSmall = errors.New("capture is too small to convert")
+
+// CreateHoldTxParams contains the input parameters of CreateHoldTx
+type CreateHoldTxParams struct {
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// ToCurrency, ToAmount, ExchangeRate and SpreadBps describe the credit side of a
+	// cross-currency hold, as for TransferTx
+	ToCurrency   string `json:"to_currency"`
+	ToAmount     int64  `json:"to_amount"`
+	ExchangeRate string `json:"exchange_rate"`
+	SpreadBps    int64  `json:"spread_bps"`
+	// ExpiresAt is when the hold is released if it hasn't been captured
+	ExpiresAt time.Time `json:"expires_at"`
+	// Audit describes who placed the hold; Actor defaults to the from account owner
+	Audit AuditContext `json:"-"`
+}
+
+// CreateHoldTxResult is the result of CreateHoldTx
+type CreateHoldTxResult struct {
+	// Hold is the pending transfer that is made when the hold is captured
+	Hold        Transfer `json:"hold"`
+	FromAccount Account  `json:"from_account"`
+}
+
+// CreateHoldTx reserves money on the from account for a transfer to the to account that
+// is made later by CaptureHoldTx, or dropped by ReleaseHoldTx. The hold is recorded as a
+// pending transfer and its amount can't be spent by other transfers in the meantime.
+// It returns the same errors as TransferTx when the transfer couldn't be made now.
+func (store *SQLStore) CreateHoldTx(ctx context.Context, arg CreateHoldTxParams) (CreateHoldTxResult, error) {
+	var result CreateHoldTxResult
+
+	transfer, err := normalizeTransferTxParams(TransferTxParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Currency:      arg.Currency,
+		ToCurrency:    arg.ToCurrency,
+		ToAmount:      arg.ToAmount,
+		ExchangeRate:  arg.ExchangeRate,
+		SpreadBps:     arg.SpreadBps,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		fromAccount, toAccount, err := lockAccountPair(ctx, q, transfer.FromAccountID, transfer.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		if fromAccount.Currency != transfer.Currency || toAccount.Currency != transfer.ToCurrency {
+			return ErrCurrencyMismatch
+		}
+		if err := checkAccountActive(fromAccount); err != nil {
+			return err
+		}
+		if err := checkAccountActive(toAccount); err != nil {
+			return err
+		}
+		if availableBalance(fromAccount)-transfer.Amount < -fromAccount.OverdraftLimit {
+			return ErrInsufficientFunds
+		}
+
+		result.Hold, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: transfer.FromAccountID,
+			ToAccountID:   transfer.ToAccountID,
+			Amount:        transfer.Amount,
+			Currency:      transfer.Currency,
+			ToAmount:      transfer.ToAmount,
+			ToCurrency:    transfer.ToCurrency,
+			ExchangeRate:  transfer.ExchangeRate,
+			SpreadBps:     transfer.SpreadBps,
+			Status:        TransferPending,
+			HoldExpiresAt: sql.NullTime{Time: arg.ExpiresAt, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:         fromAccount.ID,
+			HeldAmount: transfer.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		details := transferAuditDetails(result.Hold)
+		details["expires_at"] = arg.ExpiresAt
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  fromAccount.Owner,
+			EventType: AuditHoldCreated,
+			Details:   details,
+			Audit:     arg.Audit,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// CaptureHoldTxParams contains the input parameters of CaptureHoldTx
+type CaptureHoldTxParams struct {
+	ID int64 `json:"id"`
+	// Amount is how much of the hold to transfer, in its currency; 0 captures all of it.
+	// Whatever isn't captured is released.
+	Amount int64 `json:"amount"`
+	// Audit describes who captured the hold; Actor defaults to the from account owner
+	Audit AuditContext `json:"-"`
+}
+
+// CaptureHoldTxResult is the result of CaptureHoldTx; Transfer is the posted hold
+type CaptureHoldTxResult struct {
+	TransferTxResult
+}
+
+// CaptureHoldTx settles an active hold by posting its transfer, for all or part of the
+// held amount. The rest of the reservation is released. A partial capture of a
+// cross-currency hold is converted at the hold's rate.
+// It returns sql.ErrNoRows if there is no such hold, ErrHoldNotActive if it has been
+// captured, released or has expired, ErrCaptureExceedsHold if the amount is more than
+// was held and ErrAccountFrozen or ErrAccountClosed if either account isn't active.
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		// The expiry task may not have released an expired hold yet
+		if hold.Status != TransferPending || !time.Now().Before(hold.HoldExpiresAt.Time) {
+			return ErrHoldNotActive
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount < 0 || amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		fromAccount, toAccount, err := lockAccountPair(ctx, q, hold.FromAccountID, hold.ToAccountID)
+		if err != nil {
+			return err
+		}
+		if err := checkAccountActive(fromAccount); err != nil {
+			return err
+		}
+		if err := checkAccountActive(toAccount); err != nil {
+			return err
+		}
+
+		heldAmount := hold.Amount
+		if amount < hold.Amount {
+			toAmount := new(big.Int).Mul(big.NewInt(amount), big.NewInt(hold.ToAmount))
+			toAmount.Quo(toAmount, big.NewInt(hold.Amount))
+			if toAmount.Sign() <= 0 {
+				return ErrCaptureTooSmall
+			}
+
+			hold, err = q.UpdateHoldAmount(ctx, UpdateHoldAmountParams{
+				ID:       hold.ID,
+				Amount:   amount,
+				ToAmount: toAmount.Int64(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// Free the whole reservation; the captured part is then taken from the balance
+		_, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:         fromAccount.ID,
+			HeldAmount: -heldAmount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = setTransferStatus(ctx, q, hold, TransferPosted)
+		if err != nil {
+			return err
+		}
+
+		err = postTransferEntries(ctx, q, result.Transfer, &result.TransferTxResult)
+		if err != nil {
+			return err
+		}
+
+		details := transferAuditDetails(result.Transfer)
+		details["held_amount"] = heldAmount
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  fromAccount.Owner,
+			EventType: AuditHoldCaptured,
+			Details:   details,
+			Audit:     arg.Audit,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ReleaseHoldTxParams contains the input parameters of ReleaseHoldTx
+type ReleaseHoldTxParams struct {
+	ID int64 `json:"id"`
+	// Expired records the release as the hold running out rather than being released
+	Expired bool `json:"expired"`
+	// Audit describes who released the hold; Actor defaults to the from account owner
+	Audit AuditContext `json:"-"`
+}
+
+// ReleaseHoldTxResult is the result of ReleaseHoldTx
+type ReleaseHoldTxResult struct {
+	// Hold is the failed transfer
+	Hold        Transfer `json:"hold"`
+	FromAccount Account  `json:"from_account"`
+}
+
+// ReleaseHoldTx drops an active hold without moving any money, making the held amount
+// available to spend again. Its transfer is marked failed.
+// It returns sql.ErrNoRows if there is no such hold and ErrHoldNotActive if it was
+// already captured or released.
+func (store *SQLStore) ReleaseHoldTx(ctx context.Context, arg ReleaseHoldTxParams) (ReleaseHoldTxResult, error) {
+	var result ReleaseHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if hold.Status != TransferPending {
+			return ErrHoldNotActive
+		}
+
+		fromAccount, err := q.GetAccountForUpdate(ctx, hold.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeldAmount(ctx, AddAccountHeldAmountParams{
+			ID:         fromAccount.ID,
+			HeldAmount: -hold.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = setTransferStatus(ctx, q, hold, TransferFailed)
+		if err != nil {
+			return err
+		}
+
+		eventType := AuditHoldReleased
+		if arg.Expired {
+			eventType = AuditHoldExpired
+		}
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  fromAccount.Owner,
+			EventType: eventType,
+			Details:   transferAuditDetails(result.Hold),
+			Audit:     arg.Audit,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,268 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Kinds of ledger discrepancy found by reconciliation
+const (
+	// DiscrepancyAccountBalance is an account whose balance isn't the sum of its entries
+	DiscrepancyAccountBalance = "account_balance"
+	// DiscrepancyTransferEntryCount is a posted transfer without exactly two entries,
+	// or four with the treasury's when it converts, or a pending or failed one with any
+	DiscrepancyTransferEntryCount = "transfer_entry_count"
+	// DiscrepancyTransferDebit is a transfer whose from account entries don't debit its amount
+	DiscrepancyTransferDebit = "transfer_debit"
+	// DiscrepancyTransferCredit is a transfer whose to account entries don't credit its to_amount
+	DiscrepancyTransferCredit = "transfer_credit"
+	// DiscrepancyCurrencyImbalance is a currency whose entries don't sum to zero; it is
+	// recorded against the treasury account in that currency
+	DiscrepancyCurrencyImbalance = "currency_imbalance"
+)
+
+// ReconcileLedgerTxParams contains the input parameters of ReconcileLedgerTx
+type ReconcileLedgerTxParams struct {
+	// TriggeredBy is the admin who asked for the run, or "scheduler"
+	TriggeredBy string `json:"triggered_by"`
+	// Until bounds the run to rows created before it, so transactions still in
+	// flight get a chance to commit before the run moves past their rows
+	Until time.Time `json:"until"`
+}
+
+// ReconcileLedgerTxResult is the result of ReconcileLedgerTx
+type ReconcileLedgerTxResult struct {
+	Run           ReconciliationRun   `json:"run"`
+	Discrepancies []LedgerDiscrepancy `json:"discrepancies"`
+}
+
+// ReconcileLedgerTx checks the entries, transfers and accounts added since the previous run
+// and records what it finds wrong. Accounts are checked when they are opened and whenever
+// they get a new entry; transfers when they are made and again whenever they get a new
+// entry or change status, so captured holds and reversals are checked too. The currency
+// totals cover the whole ledger, so an imbalance is recorded by every run until it is corrected.
+func (store *SQLStore) ReconcileLedgerTx(ctx context.Context, arg ReconcileLedgerTxParams) (ReconcileLedgerTxResult, error) {
+	var result ReconcileLedgerTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := q.LockLedgerReconciliation(ctx)
+		if err != nil {
+			return err
+		}
+
+		// The first run checks the whole ledger
+		last, err := q.GetLastReconciliationRun(ctx)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		highWater, err := q.GetLedgerHighWater(ctx, arg.Until)
+		if err != nil {
+			return err
+		}
+
+		discrepancies, err := findLedgerDiscrepancies(ctx, q, ledgerScan{
+			AfterEntryID:    last.LastEntryID,
+			AfterTransferID: last.LastTransferID,
+			AfterAccountID:  last.LastAccountID,
+			HighWater:       highWater,
+			CreatedBefore:   arg.Until,
+			// Status changes before the previous run's Until have already been checked
+			StatusChangedFrom: last.CheckedUntil,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Don't move backwards if Until is earlier than the previous run's
+		result.Run, err = q.CreateReconciliationRun(ctx, CreateReconciliationRunParams{
+			TriggeredBy:      arg.TriggeredBy,
+			LastEntryID:      max(last.LastEntryID, highWater.LastEntryID),
+			LastTransferID:   max(last.LastTransferID, highWater.LastTransferID),
+			LastAccountID:    max(last.LastAccountID, highWater.LastAccountID),
+			DiscrepancyCount: int64(len(discrepancies)),
+			CheckedUntil:     latestTime(last.CheckedUntil, arg.Until),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Discrepancies = make([]LedgerDiscrepancy, 0, len(discrepancies))
+		for _, discrepancy := range discrepancies {
+			recorded, err := q.CreateLedgerDiscrepancy(ctx, CreateLedgerDiscrepancyParams{
+				RunID:      result.Run.ID,
+				Kind:       discrepancy.Kind,
+				AccountID:  discrepancy.AccountID,
+				TransferID: discrepancy.TransferID,
+				Expected:   discrepancy.Expected,
+				Actual:     discrepancy.Actual,
+			})
+			if err != nil {
+				return err
+			}
+			result.Discrepancies = append(result.Discrepancies, recorded)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// ReportLedgerTxParams contains the input parameters of ReportLedgerTx
+type ReportLedgerTxParams struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// ReportLedgerTxResult is the result of ReportLedgerTx
+type ReportLedgerTxResult struct {
+	// Discrepancies aren't recorded, so their ID, RunID and CreatedAt are empty
+	Discrepancies []LedgerDiscrepancy `json:"discrepancies"`
+}
+
+// ReportLedgerTx runs the reconciliation checks over the transfers, entries and accounts
+// created between StartTime and EndTime, and the transfers that got an entry or changed
+// status then, without recording anything or moving the incremental runs along. Balances are checked against all of an account's entries,
+// and currency totals against all entries before EndTime.
+func (store *SQLStore) ReportLedgerTx(ctx context.Context, arg ReportLedgerTxParams) (ReportLedgerTxResult, error) {
+	var result ReportLedgerTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		highWater, err := q.GetLedgerHighWater(ctx, arg.EndTime)
+		if err != nil {
+			return err
+		}
+
+		result.Discrepancies, err = findLedgerDiscrepancies(ctx, q, ledgerScan{
+			HighWater:         highWater,
+			CreatedFrom:       arg.StartTime,
+			CreatedBefore:     arg.EndTime,
+			StatusChangedFrom: arg.StartTime,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// ledgerScan is the part of the ledger a reconciliation looks at: rows after the
+// After IDs up to the high water mark, created in [CreatedFrom, CreatedBefore), and
+// transfers whose status changed in [StatusChangedFrom, CreatedBefore)
+type ledgerScan struct {
+	AfterEntryID      int64
+	AfterTransferID   int64
+	AfterAccountID    int64
+	HighWater         GetLedgerHighWaterRow
+	CreatedFrom       time.Time
+	CreatedBefore     time.Time
+	StatusChangedFrom time.Time
+}
+
+// findLedgerDiscrepancies lists what is wrong in the scanned part of the ledger,
+// without recording it
+func findLedgerDiscrepancies(ctx context.Context, q *Queries, scan ledgerScan) ([]LedgerDiscrepancy, error) {
+	var discrepancies []LedgerDiscrepancy
+
+	accounts, err := q.ListAccountBalanceMismatches(ctx, ListAccountBalanceMismatchesParams{
+		AfterEntryID:   scan.AfterEntryID,
+		LastEntryID:    scan.HighWater.LastEntryID,
+		CreatedFrom:    scan.CreatedFrom,
+		CreatedBefore:  scan.CreatedBefore,
+		AfterAccountID: scan.AfterAccountID,
+		LastAccountID:  scan.HighWater.LastAccountID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		discrepancies = append(discrepancies, LedgerDiscrepancy{
+			Kind:      DiscrepancyAccountBalance,
+			AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+			Expected:  account.EntriesTotal,
+			Actual:    account.Balance,
+		})
+	}
+
+	transfers, err := q.ListTransferEntryMismatches(ctx, ListTransferEntryMismatchesParams{
+		AfterTransferID:   scan.AfterTransferID,
+		LastTransferID:    scan.HighWater.LastTransferID,
+		CreatedFrom:       scan.CreatedFrom,
+		CreatedBefore:     scan.CreatedBefore,
+		AfterEntryID:      scan.AfterEntryID,
+		LastEntryID:       scan.HighWater.LastEntryID,
+		StatusChangedFrom: scan.StatusChangedFrom,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transfer := range transfers {
+		transferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+
+		// Pending and failed transfers haven't moved any money, so they should have no entries
+		var entryCount, debited, credited int64
+		if transferMovesMoney(transfer.Status) {
+			entryCount, debited, credited = 2, -transfer.Amount, transfer.ToAmount
+			if transfer.Currency != transfer.ToCurrency {
+				entryCount = 4
+			}
+		}
+
+		if transfer.EntryCount != entryCount {
+			discrepancies = append(discrepancies, LedgerDiscrepancy{
+				Kind:       DiscrepancyTransferEntryCount,
+				TransferID: transferID,
+				Expected:   entryCount,
+				Actual:     transfer.EntryCount,
+			})
+		}
+		if transfer.Debited != debited {
+			discrepancies = append(discrepancies, LedgerDiscrepancy{
+				Kind:       DiscrepancyTransferDebit,
+				AccountID:  sql.NullInt64{Int64: transfer.FromAccountID, Valid: true},
+				TransferID: transferID,
+				Expected:   debited,
+				Actual:     transfer.Debited,
+			})
+		}
+		if transfer.Credited != credited {
+			discrepancies = append(discrepancies, LedgerDiscrepancy{
+				Kind:       DiscrepancyTransferCredit,
+				AccountID:  sql.NullInt64{Int64: transfer.ToAccountID, Valid: true},
+				TransferID: transferID,
+				Expected:   credited,
+				Actual:     transfer.Credited,
+			})
+		}
+	}
+
+	currencies, err := q.ListCurrencyImbalances(ctx, ListCurrencyImbalancesParams{
+		LastEntryID:   scan.HighWater.LastEntryID,
+		CreatedBefore: scan.CreatedBefore,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, currency := range currencies {
+		discrepancies = append(discrepancies, LedgerDiscrepancy{
+			Kind:      DiscrepancyCurrencyImbalance,
+			AccountID: currency.TreasuryAccountID,
+			Expected:  0,
+			Actual:    currency.Total,
+		})
+	}
+
+	return discrepancies, nil
+}
+
+// latestTime returns the later of a and b
+func latestTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
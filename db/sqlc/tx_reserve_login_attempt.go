@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLoginLocked is returned when a login attempt is reserved against a key that
+// is still backing off or locked out.
+var ErrLoginLocked = errors.New("login is locked")
+
+// LoginFailureKey is a key that failed logins are counted under
+type LoginFailureKey struct {
+	Scope string `json:"scope"`
+	Key   string `json:"key"`
+}
+
+// ReserveLoginAttemptTxParams contains the input parameters of the reserve login attempt transaction
+type ReserveLoginAttemptTxParams struct {
+	Keys []LoginFailureKey `json:"keys"`
+	// Failures before ResetBefore no longer count
+	ResetBefore time.Time `json:"reset_before"`
+	// LockFor is how long a key is locked after its nth failure in a row
+	LockFor func(failures int64) time.Duration `json:"-"`
+}
+
+// ReserveLoginAttemptTxResult is the result of the reserve login attempt transaction
+type ReserveLoginAttemptTxResult struct {
+	// Previous holds each key's failures from before the attempt
+	Previous []LoginFailure `json:"previous"`
+	// Reserved holds each key's failures counting the attempt
+	Reserved []LoginFailure `json:"reserved"`
+}
+
+// ReserveLoginAttemptTx counts a login attempt as failed against each of the keys
+// before it is checked, and locks the keys for as long as that failure calls for.
+// Concurrent attempts are counted one after another, so they can't all get past
+// the backoff. An attempt that turns out to succeed is put back with ReleaseLoginAttempt.
+func (store *SQLStore) ReserveLoginAttemptTx(ctx context.Context, arg ReserveLoginAttemptTxParams) (ReserveLoginAttemptTxResult, error) {
+	var result ReserveLoginAttemptTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		now := time.Now()
+
+		for _, key := range arg.Keys {
+			err := q.CreateLoginFailure(ctx, CreateLoginFailureParams{
+				Scope: key.Scope,
+				Key:   key.Key,
+			})
+			if err != nil {
+				return err
+			}
+
+			failure, err := q.GetLoginFailureForUpdate(ctx, GetLoginFailureForUpdateParams{
+				Scope: key.Scope,
+				Key:   key.Key,
+			})
+			if err != nil {
+				return err
+			}
+			// A key without failures is never locked, whatever the database clock says
+			if failure.FailureCount > 0 && failure.LockedUntil.After(now) {
+				return ErrLoginLocked
+			}
+			result.Previous = append(result.Previous, failure)
+		}
+
+		for _, failure := range result.Previous {
+			failureCount := failure.FailureCount + 1
+			if failure.LastFailedAt.Before(arg.ResetBefore) {
+				failureCount = 1
+			}
+
+			reserved, err := q.UpdateLoginFailure(ctx, UpdateLoginFailureParams{
+				Scope:        failure.Scope,
+				Key:          failure.Key,
+				FailureCount: failureCount,
+				LastFailedAt: now,
+				LockedUntil:  now.Add(arg.LockFor(failureCount)),
+			})
+			if err != nil {
+				return err
+			}
+			result.Reserved = append(result.Reserved, reserved)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ResetPasswordTxParams contains the input parameters of ResetPasswordTx
+type ResetPasswordTxParams struct {
+	ResetID int64 `json:"reset_id"`
+	// SecretHash is the hash of the code from the reset link
+	SecretHash     string       `json:"-"`
+	HashedPassword string       `json:"-"`
+	Audit          AuditContext `json:"-"`
+}
+
+// ResetPasswordTxResult is the result of ResetPasswordTx
+type ResetPasswordTxResult struct {
+	User            User          `json:"user"`
+	PasswordReset   PasswordReset `json:"password_reset"`
+	RevokedSessions int64         `json:"revoked_sessions"`
+}
+
+// ResetPasswordTx uses up a password reset link, sets the new password and
+// revokes all of the user's sessions, so anyone who knew the old password is
+// logged out. It returns sql.ErrNoRows if the link is unknown, used or expired.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (ResetPasswordTxResult, error) {
+	var result ResetPasswordTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.PasswordReset, err = q.UsePasswordReset(ctx, UsePasswordResetParams{
+			ID:         arg.ResetID,
+			SecretHash: arg.SecretHash,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUser(ctx, UpdateUserParams{
+			Username: result.PasswordReset.Username,
+			HashedPassword: sql.NullString{
+				String: arg.HashedPassword,
+				Valid:  true,
+			},
+			PasswordChangedAt: sql.NullTime{
+				Time:  time.Now(),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.RevokedSessions, err = q.BlockUserSessions(ctx, result.User.Username)
+		if err != nil {
+			return err
+		}
+
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  result.User.Username,
+			EventType: AuditPasswordReset,
+			Details:   map[string]any{"revoked_sessions": result.RevokedSessions},
+			Audit:     arg.Audit,
+		})
+		return err
+	})
+
+	return result, err
+}
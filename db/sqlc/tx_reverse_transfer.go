@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/big"
+
+	"github.com/OmSingh2003/nimbus/util"
+)
+
+// ErrTransferNotReversible is returned by ReverseTransferTx for a transfer that is
+// itself a reversal or that was never posted.
+var ErrTransferNotReversible = errors.New("transfer can't be reversed")
+
+// ErrReversalExceedsTransfer is returned by ReverseTransferTx when the amount is more
+// than what is left to reverse of the transfer.
+var ErrReversalExceedsTransfer = errors.New("reversal exceeds the amount left to reverse")
+
+// ErrReversalTooSmall is returned by ReverseTransferTx when a partial reversal of a
+// cross-currency transfer would give back nothing once converted.
+var ErrReversalTooSmall = errors.New("reversal is too small to convert")
+
+// ReverseTransferTxParams contains the input parameters of ReverseTransferTx
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is how much of the original to_amount to send back, in to_currency;
+	// 0 reverses whatever is left
+	Amount int64 `json:"amount"`
+	// Force lets an admin reverse out of a frozen account or past its overdraft limit
+	Force  bool   `json:"force"`
+	Reason string `json:"reason"`
+	// Audit describes who asked for the reversal; Actor defaults to the recipient
+	Audit AuditContext `json:"-"`
+}
+
+// ReverseTransferTxResult is the result of ReverseTransferTx
+type ReverseTransferTxResult struct {
+	// Original is the reversed transfer with its updated ReversedAmount
+	Original Transfer `json:"original"`
+	// Reversal is the compensating transfer from the recipient back to the sender
+	Reversal TransferTxResult `json:"reversal"`
+}
+
+// ReverseTransferTx sends all or part of a transfer back from its recipient to its sender
+// with a compensating transfer that references the original. A cross-currency transfer is
+// refunded at its original rate, so reversing all of it gives back exactly what was sent.
+// Once all of it has been reversed the transfer moves to TransferReversed.
+// It returns ErrTransferNotReversible for a reversal or a transfer that isn't posted,
+// ErrReversalExceedsTransfer if the amount is more than is left to reverse and
+// ErrAccountClosed if either account is closed.
+// Unless Force is set it also returns ErrAccountFrozen or ErrInsufficientFunds.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// Lock the transfer first so concurrent reversals can't both pass the limit
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		// Only posted transfers have moved money that can be sent back
+		if original.Status == TransferReversed {
+			return ErrReversalExceedsTransfer
+		}
+		if original.ReversesTransferID.Valid || original.Status != TransferPosted {
+			return ErrTransferNotReversible
+		}
+
+		remaining := original.ToAmount - original.ReversedAmount
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount <= 0 || amount > remaining {
+			return ErrReversalExceedsTransfer
+		}
+
+		refund := reversalRefund(original, amount)
+		if refund <= 0 {
+			return ErrReversalTooSmall
+		}
+
+		recipient, sender, err := lockAccountPair(ctx, q, original.ToAccountID, original.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		// Closed accounts can't be reopened by a reversal, even a forced one
+		if recipient.Status == util.AccountStatusClosed || sender.Status == util.AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		if !arg.Force {
+			if err := checkAccountActive(recipient); err != nil {
+				return err
+			}
+			if err := checkAccountActive(sender); err != nil {
+				return err
+			}
+			if availableBalance(recipient)-amount < -recipient.OverdraftLimit {
+				return ErrInsufficientFunds
+			}
+		}
+
+		reversal := TransferTxParams{
+			FromAccountID: recipient.ID,
+			ToAccountID:   sender.ID,
+			Amount:        amount,
+			Currency:      original.ToCurrency,
+			ToAmount:      refund,
+			ToCurrency:    original.Currency,
+			ExchangeRate:  "1",
+		}
+		if original.Currency != original.ToCurrency {
+			reversal.ExchangeRate = new(big.Rat).SetFrac64(original.Amount, original.ToAmount).FloatString(10)
+		}
+
+		err = postLinkedTransfer(ctx, q, reversal, sql.NullInt64{Int64: original.ID, Valid: true}, &result.Reversal)
+		if err != nil {
+			return err
+		}
+
+		result.Original, err = q.AddTransferReversedAmount(ctx, AddTransferReversedAmountParams{
+			Amount: amount,
+			ID:     original.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The transfer only counts as reversed once all of it has been sent back
+		if result.Original.ReversedAmount == result.Original.ToAmount {
+			result.Original, err = setTransferStatus(ctx, q, result.Original, TransferReversed)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Audit the reversal in the recipient's log, since it is their money going back
+		details := transferAuditDetails(result.Reversal.Transfer)
+		details["reverses_transfer_id"] = original.ID
+		details["reason"] = arg.Reason
+		details["forced"] = arg.Force
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  recipient.Owner,
+			EventType: AuditTransferReversed,
+			Details:   details,
+			Audit:     arg.Audit,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// reversalRefund is how much of the original amount goes back to the sender when amount
+// more of its to_amount is reversed. It works on the running total, rounding down, so
+// the partial refunds of a transfer always add up to its amount once all of it is reversed.
+func reversalRefund(original Transfer, amount int64) int64 {
+	refunded := func(reversed int64) *big.Int {
+		total := new(big.Int).Mul(big.NewInt(reversed), big.NewInt(original.Amount))
+		return total.Quo(total, big.NewInt(original.ToAmount))
+	}
+
+	refund := new(big.Int).Sub(refunded(original.ReversedAmount+amount), refunded(original.ReversedAmount))
+	return refund.Int64()
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrRefreshTokenReused is returned by RotateSessionTx when the session was already
+// rotated, meaning an old refresh token was replayed. The whole session family is
+// blocked before it is returned.
+var ErrRefreshTokenReused = errors.New("refresh token already used")
+
+// ErrSessionBlocked is returned by RotateSessionTx when the session was revoked
+var ErrSessionBlocked = errors.New("blocked session")
+
+type RotateSessionTxParams struct {
+	OldSessionID uuid.UUID
+	// NewSession is created in the family of the old session, so its FamilyID is ignored
+	NewSession CreateSessionParams
+}
+
+type RotateSessionTxResult struct {
+	OldSession Session
+	NewSession Session
+}
+
+// RotateSessionTx replaces a session with a new one in the same family, so each
+// refresh token can be used only once.
+func (store *SQLStore) RotateSessionTx(ctx context.Context, arg RotateSessionTxParams) (RotateSessionTxResult, error) {
+	var result RotateSessionTxResult
+	reused := false
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		// Lock the old session so two renewals with the same token can't both rotate it
+		result.OldSession, err = q.GetSessionForUpdate(ctx, arg.OldSessionID)
+		if err != nil {
+			return err
+		}
+
+		// The token was already exchanged, so whoever holds it may have stolen it:
+		// block every session descended from the same login. This must commit.
+		if result.OldSession.ReplacedBy.Valid {
+			reused = true
+			_, err = q.BlockSessionFamily(ctx, result.OldSession.FamilyID)
+			return err
+		}
+
+		if result.OldSession.IsBoolean {
+			return ErrSessionBlocked
+		}
+
+		newSession := arg.NewSession
+		newSession.FamilyID = result.OldSession.FamilyID
+		result.NewSession, err = q.CreateSession(ctx, newSession)
+		if err != nil {
+			return err
+		}
+
+		return q.SetSessionReplacedBy(ctx, SetSessionReplacedByParams{
+			ID:         result.OldSession.ID,
+			ReplacedBy: uuid.NullUUID{UUID: result.NewSession.ID, Valid: true},
+		})
+	})
+	if err == nil && reused {
+		return result, ErrRefreshTokenReused
+	}
+
+	return result, err
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+
+	"github.com/OmSingh2003/nimbus/util"
+)
+
+// SetOverdraftLimitTxParams contains the input parameters of SetOverdraftLimitTx
+type SetOverdraftLimitTxParams struct {
+	UpdateAccountOverdraftLimitParams
+	Audit AuditContext `json:"-"`
+}
+
+// SetOverdraftLimitTxResult is the result of SetOverdraftLimitTx
+type SetOverdraftLimitTxResult struct {
+	Account Account `json:"account"`
+}
+
+// SetOverdraftLimitTx changes how far below zero an account may go on behalf of an
+// admin and records it in the owner's audit log. Lowering the limit under what is
+// already overdrawn only stops further debits; the balance is left as it is.
+// It returns ErrAccountClosed if the account is closed.
+func (store *SQLStore) SetOverdraftLimitTx(ctx context.Context, arg SetOverdraftLimitTxParams) (SetOverdraftLimitTxResult, error) {
+	var result SetOverdraftLimitTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if account.Status == util.AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		result.Account, err = q.UpdateAccountOverdraftLimit(ctx, arg.UpdateAccountOverdraftLimitParams)
+		if err != nil {
+			return err
+		}
+
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  account.Owner,
+			EventType: AuditOverdraftChanged,
+			Details: map[string]any{
+				"account_id":               account.ID,
+				"previous_overdraft_limit": account.OverdraftLimit,
+				"overdraft_limit":          arg.OverdraftLimit,
+			},
+			Audit: arg.Audit,
+		})
+		return err
+	})
+
+	return result, err
+}
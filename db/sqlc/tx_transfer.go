@@ -1,12 +1,51 @@
 package db
 
-import "context"
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/OmSingh2003/nimbus/util"
+)
+
+// ErrInsufficientFunds is returned by TransferTx when the transfer would take the
+// from account below its overdraft limit.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrCurrencyMismatch is returned by TransferTx when the from account is not in the
+// transfer currency or the to account is not in the destination currency.
+var ErrCurrencyMismatch = errors.New("account currency mismatch")
+
+// ErrAccountFrozen is returned by TransferTx when either account has been frozen by an admin.
+var ErrAccountFrozen = errors.New("account is frozen")
+
+// ErrAccountClosed is returned by TransferTx when either account has been closed.
+var ErrAccountClosed = errors.New("account is closed")
+
+// ErrIdempotencyKeyConflict is returned by TransferTx when the idempotency key was
+// already used by the same user for a transfer with different parameters.
+var ErrIdempotencyKeyConflict = errors.New("idempotency key already used for a different request")
 
 // Input parameters for transfer transaction
 type TransferTxParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	// ToCurrency, ToAmount, ExchangeRate and SpreadBps describe the credit side of a
+	// cross-currency transfer; leave them empty when both accounts share Currency
+	ToCurrency   string `json:"to_currency"`
+	ToAmount     int64  `json:"to_amount"`
+	ExchangeRate string `json:"exchange_rate"`
+	SpreadBps    int64  `json:"spread_bps"`
+	// IdempotencyKey is optional; retries with the same key return the original result
+	IdempotencyKey string `json:"idempotency_key"`
+	// Audit describes who asked for the transfer; Actor defaults to the from account owner
+	Audit AuditContext `json:"-"`
 }
 
 // Result of the transfer transaction
@@ -20,71 +59,298 @@ type TransferTxResult struct {
 
 // TransferTx performs a money transfer from one account to another.
 // It creates a transfer record, account entries, and updates account balances within a transaction.
+// The from account is debited Amount in Currency and the to account is credited ToAmount in ToCurrency.
+// It returns ErrCurrencyMismatch if the accounts are not in those currencies and
+// ErrInsufficientFunds if the from account cannot cover the amount.
+// It returns ErrAccountFrozen or ErrAccountClosed if either account is not active.
+// If an idempotency key is given and was already used with the same parameters, the
+// original result is returned unchanged and nothing is written.
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
-		var err error
+	arg, err := normalizeTransferTxParams(arg)
+	if err != nil {
+		return result, err
+	}
 
-		// 1. Create Transfer record
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+	err = store.execTx(ctx, func(q *Queries) error {
+		return transferTx(ctx, q, arg, &result)
+	})
+
+	return result, err
+}
+
+// transferTx runs the steps of TransferTx in an open transaction, filling in result.
+// arg must already be normalized.
+func transferTx(ctx context.Context, q *Queries, arg TransferTxParams, result *TransferTxResult) error {
+	// 1. Lock both accounts so the balance check can't race with another transfer
+	fromAccount, toAccount, err := lockAccountPair(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	if err != nil {
+		return err
+	}
+
+	// Each side must be in the currency it is debited or credited in, which is what gets recorded
+	if fromAccount.Currency != arg.Currency || toAccount.Currency != arg.ToCurrency {
+		return ErrCurrencyMismatch
+	}
+
+	// Frozen and closed accounts can neither send nor receive money
+	if err := checkAccountActive(fromAccount); err != nil {
+		return err
+	}
+	if err := checkAccountActive(toAccount); err != nil {
+		return err
+	}
+
+	// Claim the idempotency key before anything is written, replaying the original transfer on a retry
+	if arg.IdempotencyKey != "" {
+		replayed, err := claimIdempotencyKey(ctx, q, fromAccount, toAccount, arg, result)
 		if err != nil {
 			return err
 		}
+		if replayed {
+			return nil
+		}
+	}
 
-		// 2. Create entries
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+	// 2. Check funds against the locked balance so concurrent transfers can't overdraw.
+	// Money reserved by holds can't be spent.
+	if availableBalance(fromAccount)-arg.Amount < -fromAccount.OverdraftLimit {
+		return ErrInsufficientFunds
+	}
+
+	// 3. Create the transfer record and entries and update balances
+	err = postTransfer(ctx, q, arg, result)
+	if err != nil {
+		return err
+	}
+
+	// 4. Record which transfer the idempotency key produced and the result to replay
+	if arg.IdempotencyKey != "" {
+		response, err := json.Marshal(result)
 		if err != nil {
 			return err
 		}
-
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+		err = q.SetIdempotencyKeyTransfer(ctx, SetIdempotencyKeyTransferParams{
+			Username:       fromAccount.Owner,
+			IdempotencyKey: arg.IdempotencyKey,
+			TransferID:     sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+			Response:       response,
 		})
 		if err != nil {
 			return err
 		}
+	}
 
-		// 3. Update balances
-		// To avoid deadlock, we need to acquire locks in consistent order
-		// Always lock the account with smaller ID first
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-				ID:      arg.FromAccountID,
-				Balance: -arg.Amount,
-			})
-			if err != nil {
-				return err
-			}
-
-			result.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-				ID:      arg.ToAccountID,
-				Balance: arg.Amount,
-			})
-		} else {
-			result.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-				ID:      arg.ToAccountID,
-				Balance: arg.Amount,
-			})
-			if err != nil {
-				return err
-			}
-
-			result.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-				ID:      arg.FromAccountID,
-				Balance: -arg.Amount,
-			})
-		}
+	// 5. Audit the transfer in the sender's log
+	_, err = appendAuditEvent(ctx, q, AuditEventParams{
+		Username:  fromAccount.Owner,
+		EventType: AuditTransferCreated,
+		Details:   transferAuditDetails(result.Transfer),
+		Audit:     arg.Audit,
+	})
+	return err
+}
+
+// checkAccountActive returns ErrAccountFrozen or ErrAccountClosed unless the account is active
+func checkAccountActive(account Account) error {
+	switch account.Status {
+	case util.AccountStatusFrozen:
+		return ErrAccountFrozen
+	case util.AccountStatusClosed:
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// availableBalance is the part of an account's balance that isn't reserved by holds
+func availableBalance(account Account) int64 {
+	return account.Balance - account.HeldAmount
+}
+
+// postTransfer records a transfer between two locked accounts that have already been
+// checked, with its entries, and moves the money, filling in result.
+func postTransfer(ctx context.Context, q *Queries, arg TransferTxParams, result *TransferTxResult) error {
+	return postLinkedTransfer(ctx, q, arg, sql.NullInt64{}, result)
+}
+
+// postLinkedTransfer is postTransfer for a transfer that reverses another one
+func postLinkedTransfer(ctx context.Context, q *Queries, arg TransferTxParams, reversesTransferID sql.NullInt64, result *TransferTxResult) error {
+	var err error
+
+	// Create Transfer record
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID:      arg.FromAccountID,
+		ToAccountID:        arg.ToAccountID,
+		Amount:             arg.Amount,
+		Currency:           arg.Currency,
+		ToAmount:           arg.ToAmount,
+		ToCurrency:         arg.ToCurrency,
+		ExchangeRate:       arg.ExchangeRate,
+		SpreadBps:          arg.SpreadBps,
+		ReversesTransferID: reversesTransferID,
+		Status:             TransferPosted,
+	})
+	if err != nil {
 		return err
+	}
+
+	return postTransferEntries(ctx, q, result.Transfer, result)
+}
+
+// postTransferEntries creates the entries of a transfer and moves the money between
+// its accounts, filling in result apart from Transfer. A cross-currency transfer also
+// gets the treasury's exchange entries, which aren't in result.
+func postTransferEntries(ctx context.Context, q *Queries, transfer Transfer, result *TransferTxResult) error {
+	var err error
+
+	// Create entries
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.FromAccountID,
+		Amount:     -transfer.Amount,
+		Currency:   transfer.Currency,
+		TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
 	})
+	if err != nil {
+		return err
+	}
 
-	return result, err
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  transfer.ToAccountID,
+		Amount:     transfer.ToAmount,
+		Currency:   transfer.ToCurrency,
+		TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Update balances
+	result.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:      transfer.FromAccountID,
+		Balance: -transfer.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	result.ToAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID:      transfer.ToAccountID,
+		Balance: transfer.ToAmount,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Converting money goes through the treasury, which holds the currency positions
+	if transfer.Currency != transfer.ToCurrency {
+		return postExchangeEntries(ctx, q, transfer)
+	}
+	return nil
+}
+
+// transferAuditDetails describes a transfer for the audit log
+func transferAuditDetails(transfer Transfer) map[string]any {
+	return map[string]any{
+		"transfer_id":     transfer.ID,
+		"from_account_id": transfer.FromAccountID,
+		"to_account_id":   transfer.ToAccountID,
+		"amount":          transfer.Amount,
+		"currency":        transfer.Currency,
+		"to_amount":       transfer.ToAmount,
+		"to_currency":     transfer.ToCurrency,
+	}
+}
+
+// normalizeTransferTxParams fills in the credit side of a same-currency transfer
+// and checks that a cross-currency one has one.
+func normalizeTransferTxParams(arg TransferTxParams) (TransferTxParams, error) {
+	if arg.ToCurrency == "" || arg.ToCurrency == arg.Currency {
+		arg.ToCurrency = arg.Currency
+		arg.ToAmount = arg.Amount
+		arg.ExchangeRate = "1"
+		arg.SpreadBps = 0
+		return arg, nil
+	}
+
+	if arg.ToAmount <= 0 || arg.ExchangeRate == "" {
+		return arg, fmt.Errorf("cross-currency transfer from %s to %s needs a converted amount and exchange rate", arg.Currency, arg.ToCurrency)
+	}
+	return arg, nil
+}
+
+// lockAccountPair locks both accounts for update, always taking the smaller ID first,
+// and returns them in the order they were asked for.
+func lockAccountPair(ctx context.Context, q *Queries, accountID1, accountID2 int64) (account1 Account, account2 Account, err error) {
+	if accountID1 < accountID2 {
+		account1, err = q.GetAccountForUpdate(ctx, accountID1)
+		if err != nil {
+			return
+		}
+		account2, err = q.GetAccountForUpdate(ctx, accountID2)
+		return
+	}
+
+	account2, err = q.GetAccountForUpdate(ctx, accountID2)
+	if err != nil {
+		return
+	}
+	account1, err = q.GetAccountForUpdate(ctx, accountID1)
+	return
+}
+
+// claimIdempotencyKey stores the key for the from account owner, or, if the owner
+// already used it, loads the result it produced into result and reports replayed = true.
+// A concurrent request with the same key blocks on the insert until the first one finishes.
+func claimIdempotencyKey(ctx context.Context, q *Queries, fromAccount, toAccount Account, arg TransferTxParams, result *TransferTxResult) (replayed bool, err error) {
+	username := fromAccount.Owner
+	requestHash := hashTransferRequest(arg)
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:       username,
+		IdempotencyKey: arg.IdempotencyKey,
+		RequestHash:    requestHash,
+	})
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	// ON CONFLICT DO NOTHING returned no row: the key is already taken
+	key, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username:       username,
+		IdempotencyKey: arg.IdempotencyKey,
+	})
+	if err != nil {
+		return false, err
+	}
+	if key.RequestHash != requestHash || !key.TransferID.Valid {
+		return false, ErrIdempotencyKeyConflict
+	}
+
+	if string(key.Response) != "null" {
+		err = json.Unmarshal(key.Response, result)
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	// Keys used before results were stored only know the transfer they produced
+	result.Transfer, err = q.GetTransfer(ctx, key.TransferID.Int64)
+	if err != nil {
+		return false, err
+	}
+	result.FromAccount = fromAccount
+	result.ToAccount = toAccount
+	return true, nil
+}
+
+// hashTransferRequest fingerprints the parameters a retry must repeat exactly.
+// ToAmount is left out since the rate may have moved between retries.
+func hashTransferRequest(arg TransferTxParams) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%d:%d:%s:%s", arg.FromAccountID, arg.ToAccountID, arg.Amount, arg.Currency, arg.ToCurrency)))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ErrNoTreasuryAccount is returned when there is no treasury account in the currency
+// of an account that is to be credited from it, or of a cross-currency transfer.
+var ErrNoTreasuryAccount = errors.New("no treasury account for currency")
+
+// ErrTreasuryAccount is returned when a treasury account is asked to book money
+// against itself.
+var ErrTreasuryAccount = errors.New("account is a treasury account")
+
+// creditFromTreasury moves amount into an account from the treasury account in its currency,
+// booked as a transfer so the credit is matched by a debit on the treasury.
+// The treasury isn't held to an overdraft limit; its balance is what it has paid out.
+func creditFromTreasury(ctx context.Context, q *Queries, accountID int64, amount int64) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	treasury, account, err := lockWithTreasury(ctx, q, accountID)
+	if err != nil {
+		return result, err
+	}
+	if err := checkAccountActive(account); err != nil {
+		return result, err
+	}
+
+	err = postTreasuryTransfer(ctx, q, treasury, account, amount, &result)
+	return result, err
+}
+
+// lockWithTreasury locks an account together with the treasury account in its currency.
+// Treasury accounts are always locked after the customer accounts of a transaction, so
+// this can't deadlock with a cross-currency transfer exchanging through the treasury.
+func lockWithTreasury(ctx context.Context, q *Queries, accountID int64) (treasury Account, account Account, err error) {
+	account, err = q.GetAccountForUpdate(ctx, accountID)
+	if err != nil {
+		return
+	}
+
+	treasury, err = treasuryAccount(ctx, q, account.Currency)
+	if err != nil {
+		return
+	}
+	if treasury.ID == account.ID {
+		err = ErrTreasuryAccount
+		return
+	}
+
+	treasury, err = q.GetAccountForUpdate(ctx, treasury.ID)
+	return
+}
+
+// treasuryAccount returns the treasury account in currency, or ErrNoTreasuryAccount
+func treasuryAccount(ctx context.Context, q *Queries, currency string) (Account, error) {
+	treasury, err := q.GetTreasuryAccount(ctx, currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrNoTreasuryAccount
+	}
+	return treasury, err
+}
+
+// postTreasuryTransfer books amount between a locked account and its treasury account:
+// from the treasury when amount is positive and back to it when amount is negative.
+func postTreasuryTransfer(ctx context.Context, q *Queries, treasury Account, account Account, amount int64, result *TransferTxResult) error {
+	from, to := treasury, account
+	if amount < 0 {
+		from, to, amount = account, treasury, -amount
+	}
+
+	arg, err := normalizeTransferTxParams(TransferTxParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Currency:      account.Currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	return postTransfer(ctx, q, arg, result)
+}
+
+// postExchangeEntries books the treasury's side of a cross-currency transfer whose own
+// entries are posted: the treasury account in the transfer's currency takes the amount
+// debited and the one in its to currency pays the amount credited, keeping the spread.
+// The entries of each currency then sum to zero.
+func postExchangeEntries(ctx context.Context, q *Queries, transfer Transfer) error {
+	fromTreasury, err := treasuryAccount(ctx, q, transfer.Currency)
+	if err != nil {
+		return err
+	}
+	toTreasury, err := treasuryAccount(ctx, q, transfer.ToCurrency)
+	if err != nil {
+		return err
+	}
+
+	// Every cross-currency transfer takes both locks, so take them in ID order
+	_, _, err = lockAccountPair(ctx, q, fromTreasury.ID, toTreasury.ID)
+	if err != nil {
+		return err
+	}
+
+	legs := []CreateEntryParams{
+		{
+			AccountID:  fromTreasury.ID,
+			Amount:     transfer.Amount,
+			Currency:   transfer.Currency,
+			TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+		},
+		{
+			AccountID:  toTreasury.ID,
+			Amount:     -transfer.ToAmount,
+			Currency:   transfer.ToCurrency,
+			TransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+		},
+	}
+	for _, leg := range legs {
+		_, err = q.CreateEntry(ctx, leg)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:      leg.AccountID,
+			Balance: leg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+
+	"github.com/OmSingh2003/nimbus/util"
+)
+
+// UpdateAccountStatusTxParams contains the input parameters of UpdateAccountStatusTx
+type UpdateAccountStatusTxParams struct {
+	UpdateAccountStatusParams
+	Audit AuditContext `json:"-"`
+}
+
+// UpdateAccountStatusTxResult is the result of UpdateAccountStatusTx
+type UpdateAccountStatusTxResult struct {
+	Account Account `json:"account"`
+}
+
+// UpdateAccountStatusTx freezes or unfreezes an account on behalf of an admin and
+// records it in the owner's audit log.
+// It returns ErrAccountClosed if the account is closed.
+func (store *SQLStore) UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusTxParams) (UpdateAccountStatusTxResult, error) {
+	var result UpdateAccountStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		// Closing is final
+		if account.Status == util.AccountStatusClosed {
+			return ErrAccountClosed
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, arg.UpdateAccountStatusParams)
+		if err != nil {
+			return err
+		}
+
+		eventType := AuditAccountUnfrozen
+		if arg.Status == util.AccountStatusFrozen {
+			eventType = AuditAccountFrozen
+		}
+		_, err = appendAuditEvent(ctx, q, AuditEventParams{
+			Username:  account.Owner,
+			EventType: eventType,
+			Details: map[string]any{
+				"account_id":      account.ID,
+				"previous_status": account.Status,
+			},
+			Audit: arg.Audit,
+		})
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,66 @@
+package db
+
+import "context"
+
+// UpdateUserTxParams contains the input parameters of UpdateUserTx
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	Audit AuditContext `json:"-"`
+}
+
+// UpdateUserTxResult is the result of UpdateUserTx
+type UpdateUserTxResult struct {
+	User User `json:"user"`
+}
+
+// UpdateUserTx updates a user's profile and records what changed in their audit log.
+// A password change is recorded as its own event.
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error) {
+	var result UpdateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.UpdateUser(ctx, arg.UpdateUserParams)
+		if err != nil {
+			return err
+		}
+
+		// Only record which fields changed, never their values
+		var fields []string
+		if arg.FullName.Valid {
+			fields = append(fields, "full_name")
+		}
+		if arg.Email.Valid {
+			fields = append(fields, "email")
+		}
+		if arg.IsEmailVerified.Valid {
+			fields = append(fields, "is_email_verified")
+		}
+		if arg.TransferConfirmationThreshold.Valid {
+			fields = append(fields, "transfer_confirmation_threshold")
+		}
+		if len(fields) > 0 {
+			_, err = appendAuditEvent(ctx, q, AuditEventParams{
+				Username:  result.User.Username,
+				EventType: AuditUserUpdated,
+				Details:   map[string]any{"fields": fields},
+				Audit:     arg.Audit,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if arg.HashedPassword.Valid {
+			_, err = appendAuditEvent(ctx, q, AuditEventParams{
+				Username:  result.User.Username,
+				EventType: AuditPasswordChanged,
+				Audit:     arg.Audit,
+			})
+		}
+		return err
+	})
+
+	return result, err
+}
@@ -3,6 +3,8 @@ package db
 import (
 	"context"
 	"database/sql"
+
+	"github.com/OmSingh2003/nimbus/util"
 )
 
 type VerifyEmailTxParams struct {
@@ -11,9 +13,10 @@ type VerifyEmailTxParams struct {
 }
 
 type VerifyEmailTxResult struct {
-	User           User
-	VerifyEmail    VerifyEmail
-	WelcomeAccount Account
+	User            User
+	VerifyEmail     VerifyEmail
+	WelcomeAccount  Account
+	WelcomeTransfer Transfer
 }
 
 func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error) {
@@ -41,13 +44,20 @@ func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParam
 			return err
 		}
 
-		// Create welcome account with $100 USD
-		result.WelcomeAccount, err = q.CreateAccount(ctx, CreateAccountParams{
-			Owner:    result.VerifyEmail.Username,
-			Balance:  10000, // $100.00 in cents
-			Currency: "USD",
-		})
-		return err
+		// Open a USD account funded with the welcome credit from the treasury
+		var account CreateAccountTxResult
+		err = createAccountTx(ctx, q, CreateAccountTxParams{
+			Owner:         result.VerifyEmail.Username,
+			Currency:      util.USD,
+			WelcomeCredit: util.WelcomeCreditAmount,
+		}, &account)
+		if err != nil {
+			return err
+		}
+
+		result.WelcomeAccount = account.Account
+		result.WelcomeTransfer = account.WelcomeTransfer
+		return nil
 	})
 
 	return result, err
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"testing"
 
 	"github.com/OmSingh2003/nimbus/util"
@@ -30,6 +31,7 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
 	require.Equal(t, arg.FullName, user.FullName)
 	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, util.DepositorRole, user.Role)
 
 	require.NotZero(t, user.CreatedAt)
 	require.NotZero(t, user.PasswordChangedAt)
@@ -94,3 +96,29 @@ func TestUpdateUserOnlyUserPassword(t *testing.T) {
 	require.Equal(t, oldUser.Email, updatedUser.Email)
 	require.Equal(t, newhashPassword, updatedUser.HashedPassword)
 }
+
+func TestSearchUsers(t *testing.T) {
+	user := createRandomUser(t)
+
+	// Matches are case-insensitive on part of the username or email
+	for _, query := range []string{user.Username[1:4], strings.ToUpper(user.Email)} {
+		users, err := testQueries.SearchUsers(context.Background(), SearchUsersParams{
+			Query:  query,
+			Limit:  100,
+			Offset: 0,
+		})
+		require.NoError(t, err)
+		require.Contains(t, users, user)
+	}
+
+	// Wildcards in the query are matched literally
+	for _, query := range []string{"%%", "__", user.Username[:1] + "%" + user.Username[2:4], `\%`} {
+		users, err := testQueries.SearchUsers(context.Background(), SearchUsersParams{
+			Query:  query,
+			Limit:  100,
+			Offset: 0,
+		})
+		require.NoError(t, err)
+		require.NotContains(t, users, user)
+	}
+}
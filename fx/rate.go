@@ -0,0 +1,78 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// ErrRateNotFound is returned when no rate is known for a currency pair
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// basisPoints is the number of basis points in 100%
+const basisPoints = 10000
+
+// RateProvider is an interface for looking up exchange rates
+type RateProvider interface {
+	// Rate returns how many units of quote currency one unit of base currency buys
+	Rate(ctx context.Context, base string, quote string) (*big.Rat, error)
+}
+
+// Quote is the conversion applied to a transfer from one currency to another
+type Quote struct {
+	From      string
+	To        string
+	Rate      *big.Rat
+	SpreadBps int64
+}
+
+// NewQuote looks up the rate from the provider and applies the given spread.
+// Converting a currency to itself always uses a rate of 1 and no spread.
+func NewQuote(ctx context.Context, provider RateProvider, from string, to string, spreadBps int64) (Quote, error) {
+	if from == to {
+		return Quote{From: from, To: to, Rate: big.NewRat(1, 1)}, nil
+	}
+
+	if spreadBps < 0 || spreadBps >= basisPoints {
+		return Quote{}, fmt.Errorf("invalid spread: %d bps", spreadBps)
+	}
+
+	rate, err := provider.Rate(ctx, from, to)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	quote := Quote{
+		From:      from,
+		To:        to,
+		Rate:      rate,
+		SpreadBps: spreadBps,
+	}
+	return quote, nil
+}
+
+// Convert returns the amount credited in the To currency, rounded down to the minor unit
+func (quote Quote) Convert(amount int64) int64 {
+	converted := new(big.Rat).Mul(big.NewRat(amount, 1), quote.Rate)
+	converted.Mul(converted, big.NewRat(basisPoints-quote.SpreadBps, basisPoints))
+
+	return new(big.Int).Quo(converted.Num(), converted.Denom()).Int64()
+}
+
+// RateString formats the rate the way it is stored in the database
+func (quote Quote) RateString() string {
+	return quote.Rate.FloatString(10)
+}
+
+// ParseRate parses a decimal rate such as "0.95" and checks that it is positive
+func ParseRate(value string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid rate: %q", value)
+	}
+	if rate.Sign() <= 0 {
+		return nil, fmt.Errorf("rate must be positive: %q", value)
+	}
+	return rate, nil
+}
@@ -0,0 +1,91 @@
+package fx
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteConvert(t *testing.T) {
+	testCases := []struct {
+		name      string
+		rate      *big.Rat
+		spreadBps int64
+		amount    int64
+		want      int64
+	}{
+		{
+			name:   "same currency",
+			rate:   big.NewRat(1, 1),
+			amount: 10000,
+			want:   10000,
+		},
+		{
+			name:   "USD to EUR",
+			rate:   big.NewRat(95, 100),
+			amount: 10000,
+			want:   9500,
+		},
+		{
+			name:      "with spread",
+			rate:      big.NewRat(95, 100),
+			spreadBps: 100,
+			amount:    10000,
+			want:      9405,
+		},
+		{
+			name:   "rounds down",
+			rate:   big.NewRat(1, 3),
+			amount: 100,
+			want:   33,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote := Quote{Rate: tc.rate, SpreadBps: tc.spreadBps}
+			require.Equal(t, tc.want, quote.Convert(tc.amount))
+		})
+	}
+}
+
+func TestNewQuote(t *testing.T) {
+	provider, err := NewStaticProvider(map[string]string{
+		"USD/INR": "83",
+	})
+	require.NoError(t, err)
+
+	quote, err := NewQuote(context.Background(), provider, "USD", "INR", 50)
+	require.NoError(t, err)
+	require.Equal(t, "83.0000000000", quote.RateString())
+	require.Equal(t, int64(50), quote.SpreadBps)
+	require.Equal(t, int64(825850), quote.Convert(10000))
+
+	quote, err = NewQuote(context.Background(), provider, "EUR", "EUR", 50)
+	require.NoError(t, err)
+	require.Zero(t, quote.SpreadBps)
+	require.Equal(t, int64(10000), quote.Convert(10000))
+
+	_, err = NewQuote(context.Background(), provider, "USD", "EUR", 50)
+	require.ErrorIs(t, err, ErrRateNotFound)
+
+	_, err = NewQuote(context.Background(), provider, "USD", "INR", basisPoints)
+	require.Error(t, err)
+}
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("0.95")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(19, 20), rate)
+
+	_, err = ParseRate("abc")
+	require.Error(t, err)
+
+	_, err = ParseRate("0")
+	require.Error(t, err)
+
+	_, err = ParseRate("-1.5")
+	require.Error(t, err)
+}
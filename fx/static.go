@@ -0,0 +1,68 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// StaticProvider serves rates from a fixed in-memory table
+type StaticProvider struct {
+	rates map[string]*big.Rat
+}
+
+// NewStaticProvider creates a StaticProvider from rates keyed by "BASE/QUOTE", e.g. "USD/EUR": "0.95".
+// A missing pair falls back to the inverse of the opposite pair.
+func NewStaticProvider(rates map[string]string) (*StaticProvider, error) {
+	provider := &StaticProvider{
+		rates: make(map[string]*big.Rat, len(rates)),
+	}
+
+	for pair, value := range rates {
+		base, quote, ok := strings.Cut(pair, "/")
+		if !ok || base == "" || quote == "" {
+			return nil, fmt.Errorf("invalid currency pair: %q", pair)
+		}
+
+		rate, err := ParseRate(value)
+		if err != nil {
+			return nil, fmt.Errorf("pair %s: %w", pair, err)
+		}
+		provider.rates[pairKey(base, quote)] = rate
+	}
+
+	return provider, nil
+}
+
+// NewFileProvider creates a StaticProvider from a JSON file holding the same "BASE/QUOTE" map
+func NewFileProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var rates map[string]string
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file: %w", err)
+	}
+
+	return NewStaticProvider(rates)
+}
+
+// Rate returns the rate for the pair, or the inverse of the opposite pair
+func (provider *StaticProvider) Rate(ctx context.Context, base string, quote string) (*big.Rat, error) {
+	if rate, ok := provider.rates[pairKey(base, quote)]; ok {
+		return new(big.Rat).Set(rate), nil
+	}
+	if rate, ok := provider.rates[pairKey(quote, base)]; ok {
+		return new(big.Rat).Inv(rate), nil
+	}
+	return nil, fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+}
+
+func pairKey(base string, quote string) string {
+	return base + "/" + quote
+}
@@ -0,0 +1,54 @@
+package fx
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	provider, err := NewStaticProvider(map[string]string{
+		"USD/EUR": "0.8",
+	})
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(4, 5), rate)
+
+	// The inverse pair is derived from the stored one
+	rate, err = provider.Rate(context.Background(), "EUR", "USD")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(5, 4), rate)
+
+	_, err = provider.Rate(context.Background(), "USD", "INR")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestNewStaticProviderInvalid(t *testing.T) {
+	_, err := NewStaticProvider(map[string]string{"USDEUR": "0.8"})
+	require.Error(t, err)
+
+	_, err = NewStaticProvider(map[string]string{"USD/EUR": "zero"})
+	require.Error(t, err)
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	err := os.WriteFile(path, []byte(`{"USD/INR": "83.5"}`), 0o600)
+	require.NoError(t, err)
+
+	provider, err := NewFileProvider(path)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "INR")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(167, 2), rate)
+
+	_, err = NewFileProvider(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+)
+
+// StoreProvider serves rates from the fx_rates table
+type StoreProvider struct {
+	store db.Querier
+}
+
+// NewStoreProvider creates a RateProvider backed by the database
+func NewStoreProvider(store db.Querier) *StoreProvider {
+	return &StoreProvider{
+		store: store,
+	}
+}
+
+// Rate returns the stored rate for the pair, or the inverse of the opposite pair
+func (provider *StoreProvider) Rate(ctx context.Context, base string, quote string) (*big.Rat, error) {
+	fxRate, err := provider.store.GetFxRate(ctx, db.GetFxRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+	})
+	if err == nil {
+		return ParseRate(fxRate.Rate)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get rate: %w", err)
+	}
+
+	fxRate, err = provider.store.GetFxRate(ctx, db.GetFxRateParams{
+		BaseCurrency:  quote,
+		QuoteCurrency: base,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s/%s", ErrRateNotFound, base, quote)
+		}
+		return nil, fmt.Errorf("failed to get rate: %w", err)
+	}
+
+	rate, err := ParseRate(fxRate.Rate)
+	if err != nil {
+		return nil, err
+	}
+	return rate.Inv(rate), nil
+}
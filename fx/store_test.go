@@ -0,0 +1,58 @@
+package fx
+
+import (
+	"context"
+	"database/sql"
+	"math/big"
+	"testing"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStoreProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		GetFxRate(gomock.Any(), gomock.Eq(db.GetFxRateParams{BaseCurrency: "USD", QuoteCurrency: "EUR"})).
+		Times(1).
+		Return(db.FxRate{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: "0.9500000000"}, nil)
+
+	store.EXPECT().
+		GetFxRate(gomock.Any(), gomock.Eq(db.GetFxRateParams{BaseCurrency: "EUR", QuoteCurrency: "INR"})).
+		Times(1).
+		Return(db.FxRate{}, sql.ErrNoRows)
+
+	store.EXPECT().
+		GetFxRate(gomock.Any(), gomock.Eq(db.GetFxRateParams{BaseCurrency: "INR", QuoteCurrency: "EUR"})).
+		Times(1).
+		Return(db.FxRate{BaseCurrency: "INR", QuoteCurrency: "EUR", Rate: "0.0125000000"}, nil)
+
+	provider := NewStoreProvider(store)
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(19, 20), rate)
+
+	rate, err = provider.Rate(context.Background(), "EUR", "INR")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(80, 1), rate)
+}
+
+func TestStoreProviderNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		GetFxRate(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(db.FxRate{}, sql.ErrNoRows)
+
+	provider := NewStoreProvider(store)
+
+	_, err := provider.Rate(context.Background(), "USD", "XYZ")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
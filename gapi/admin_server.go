@@ -0,0 +1,27 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+)
+
+// AdminServer serves the AdminService. It shares the store, token maker and
+// config of the Server it was created from.
+type AdminServer struct {
+	pb.UnimplementedAdminServiceServer
+	server *Server
+}
+
+// NewAdminServer creates the AdminService server on top of server
+func NewAdminServer(server *Server) *AdminServer {
+	return &AdminServer{server: server}
+}
+
+// authorizeAdmin authenticates the caller and requires the admin role,
+// whichever way the method was reached
+func (admin *AdminServer) authorizeAdmin(ctx context.Context) (*token.Payload, error) {
+	return admin.server.authorizeUser(ctx, []string{util.AdminRole})
+}
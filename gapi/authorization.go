@@ -2,10 +2,13 @@ package gapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -17,6 +20,60 @@ const (
 	authorizationBearer = "bearer"
 )
 
+// errPermissionDenied is returned when the caller's role may not call the method
+var errPermissionDenied = errors.New("permission denied")
+
+// publicMethods don't require authentication
+var publicMethods = map[string]bool{
+	"/pb.VaultguardAPI/CreateUser":           true,
+	"/pb.VaultguardAPI/LoginUser":            true,
+	"/pb.VaultguardAPI/VerifyEmail":          true,
+	"/pb.VaultguardAPI/Logout":               true,
+	"/pb.VaultguardAPI/RenewAccessToken":     true,
+	"/pb.VaultguardAPI/VerifyMFA":            true,
+	"/pb.VaultguardAPI/RequestPasswordReset": true,
+	"/pb.VaultguardAPI/ResetPassword":        true,
+	"/pb.VaultguardAPI/ConfirmEmailChange":   true,
+}
+
+var allRoles = []string{util.DepositorRole, util.SupportRole, util.AdminRole}
+
+// methodRoles lists the roles allowed to call each method that requires authentication.
+// Support staff can read any account but can't move money. A method missing here
+// can't be called by anyone.
+var methodRoles = map[string][]string{
+	"/pb.VaultguardAPI/UpdateUser":             allRoles,
+	"/pb.VaultguardAPI/CreateTransfer":         {util.DepositorRole, util.AdminRole},
+	"/pb.VaultguardAPI/ConfirmTransfer":        {util.DepositorRole, util.AdminRole},
+	"/pb.VaultguardAPI/ReverseTransfer":        {util.DepositorRole, util.AdminRole},
+	"/pb.VaultguardAPI/CreateHold":             {util.DepositorRole, util.AdminRole},
+	"/pb.VaultguardAPI/CaptureHold":            {util.DepositorRole, util.AdminRole},
+	"/pb.VaultguardAPI/ReleaseHold":            {util.DepositorRole, util.AdminRole},
+	"/pb.VaultguardAPI/CreateAccount":          {util.DepositorRole, util.AdminRole},
+	"/pb.VaultguardAPI/CloseAccount":           {util.DepositorRole, util.AdminRole},
+	"/pb.VaultguardAPI/GetAccount":             allRoles,
+	"/pb.VaultguardAPI/ListAccounts":           allRoles,
+	"/pb.VaultguardAPI/ListTransfers":          allRoles,
+	"/pb.VaultguardAPI/ListAccountEntries":     allRoles,
+	"/pb.VaultguardAPI/ExportStatement":        allRoles,
+	"/pb.VaultguardAPI/ListSessions":           allRoles,
+	"/pb.VaultguardAPI/RevokeSession":          allRoles,
+	"/pb.VaultguardAPI/RevokeOtherSessions":    allRoles,
+	"/pb.VaultguardAPI/ListSecurityEvents":     allRoles,
+	"/pb.VaultguardAPI/EnrollMFA":              allRoles,
+	"/pb.VaultguardAPI/ConfirmMFA":             allRoles,
+	"/pb.AdminService/SearchUsers":             {util.AdminRole},
+	"/pb.AdminService/GetAccount":              {util.AdminRole},
+	"/pb.AdminService/ListAccountEntries":      {util.AdminRole},
+	"/pb.AdminService/FreezeAccount":           {util.AdminRole},
+	"/pb.AdminService/UnfreezeAccount":         {util.AdminRole},
+	"/pb.AdminService/SetOverdraftLimit":       {util.AdminRole},
+	"/pb.AdminService/AdjustAccountBalance":    {util.AdminRole},
+	"/pb.AdminService/RunLedgerReconciliation": {util.AdminRole},
+	"/pb.AdminService/GetLedgerReport":         {util.AdminRole},
+	"/pb.AdminService/ReverseTransfer":         {util.AdminRole},
+}
+
 func (server *Server) authorizeUser(ctx context.Context, accessibleRoles []string) (*token.Payload, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -45,61 +102,49 @@ func (server *Server) authorizeUser(ctx context.Context, accessibleRoles []strin
 		return nil, fmt.Errorf("invalid access token: %s", err)
 	}
 
-	// Since we don't have role-based access control yet, we'll skip the role check
-	// TODO: Implement role-based access control when User model includes roles
-	// if !hasPermission(payload.Username, accessibleRoles) {
-	//	return nil, fmt.Errorf("permission denied")
-	// }
+	if !hasPermission(payload.Role, accessibleRoles) {
+		return nil, errPermissionDenied
+	}
+
+	if err := server.passwordChanges.CheckToken(ctx, payload); err != nil {
+		return nil, fmt.Errorf("invalid access token: %s", err)
+	}
 
 	return payload, nil
 }
 
-// getAuthPayload extracts the authentication payload from context
+// getAuthPayload authenticates the caller and checks their role against methodRoles.
+// Gateway calls don't go through AuthorizationInterceptor, so handlers check here as well.
+// A call whose method can't be told is denied, like a method missing from methodRoles.
 func (server *Server) getAuthPayload(ctx context.Context) (*token.Payload, error) {
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, fmt.Errorf("missing metadata")
-	}
-
-	values := md.Get(authorizationHeader)
-	if len(values) == 0 {
-		return nil, fmt.Errorf("missing authorization header")
-	}
-
-	authHeader := values[0]
-	fields := strings.Fields(authHeader)
-	if len(fields) < 2 {
-		return nil, fmt.Errorf("invalid authorization header format")
-	}
+	method, _ := rpcMethod(ctx)
+	return server.authorizeUser(ctx, methodRoles[method])
+}
 
-	authType := strings.ToLower(fields[0])
-	if authType != authorizationBearer {
-		return nil, fmt.Errorf("unsupported authorization type: %s", authType)
+// rpcMethod returns the full name of the method being called, from the gRPC server
+// or from the gateway's annotated context
+func rpcMethod(ctx context.Context) (string, bool) {
+	if method, ok := grpc.Method(ctx); ok && method != "" {
+		return method, true
 	}
+	return runtime.RPCMethod(ctx)
+}
 
-	accessToken := fields[1]
-	payload, err := server.tokenMaker.VerifyToken(accessToken)
-	if err != nil {
-		return nil, fmt.Errorf("invalid access token: %s", err)
+// authError converts an error from authorizeUser into a gRPC status
+func authError(err error) error {
+	if errors.Is(err, errPermissionDenied) {
+		return status.Errorf(codes.PermissionDenied, "%s", err)
 	}
-
-	return payload, nil
+	return status.Errorf(codes.Unauthenticated, "authentication failed: %s", err)
 }
 
 // AuthorizationInterceptor is a gRPC unary interceptor for authorization
 func (server *Server) AuthorizationInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-	// List of methods that don't require authentication
-	publicMethods := map[string]bool{
-		"/pb.VaultguardAPI/CreateUser": true,
-		"/pb.VaultguardAPI/LoginUser":  true,
-	}
-
-	// Check if this method requires authentication
+	// Check if this method requires authentication and a role
 	if !publicMethods[info.FullMethod] {
-		// Verify the token
-		_, err := server.getAuthPayload(ctx)
+		_, err := server.authorizeUser(ctx, methodRoles[info.FullMethod])
 		if err != nil {
-			return nil, status.Error(codes.Unauthenticated, err.Error())
+			return nil, authError(err)
 		}
 	}
 
@@ -107,6 +152,12 @@ func (server *Server) AuthorizationInterceptor(ctx context.Context, req interfac
 	return handler(ctx, req)
 }
 
+// canReadAccount reports whether the caller may read an account with the given owner.
+// Staff can read any account.
+func canReadAccount(payload *token.Payload, owner string) bool {
+	return owner == payload.Username || util.IsStaffRole(payload.Role)
+}
+
 func hasPermission(userRole string, accessibleRoles []string) bool {
 	for _, role := range accessibleRoles {
 		if userRole == role {
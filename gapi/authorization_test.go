@@ -0,0 +1,162 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthorizationInterceptor(t *testing.T) {
+	server := newTestServer(t, nil, nil)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	testCases := []struct {
+		name   string
+		method string
+		role   string
+		noAuth bool
+		code   codes.Code
+	}{
+		{name: "DepositorTransfer", method: "/pb.VaultguardAPI/CreateTransfer", role: util.DepositorRole, code: codes.OK},
+		{name: "AdminTransfer", method: "/pb.VaultguardAPI/CreateTransfer", role: util.AdminRole, code: codes.OK},
+		{name: "SupportTransfer", method: "/pb.VaultguardAPI/CreateTransfer", role: util.SupportRole, code: codes.PermissionDenied},
+		{name: "SupportCreateAccount", method: "/pb.VaultguardAPI/CreateAccount", role: util.SupportRole, code: codes.PermissionDenied},
+		{name: "SupportGetAccount", method: "/pb.VaultguardAPI/GetAccount", role: util.SupportRole, code: codes.OK},
+		{name: "UnknownRole", method: "/pb.VaultguardAPI/GetAccount", role: "guest", code: codes.PermissionDenied},
+		{name: "MFAPendingToken", method: "/pb.VaultguardAPI/GetAccount", role: util.MFAPendingRole, code: codes.PermissionDenied},
+		{name: "UnlistedMethod", method: "/pb.VaultguardAPI/Unknown", role: util.AdminRole, code: codes.PermissionDenied},
+		{name: "PublicMethod", method: "/pb.VaultguardAPI/LoginUser", noAuth: true, code: codes.OK},
+		{name: "NoAuthorization", method: "/pb.VaultguardAPI/GetAccount", noAuth: true, code: codes.Unauthenticated},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if !tc.noAuth {
+				ctx = newContextWithBearerToken(t, server.tokenMaker, util.RandomOwner(), tc.role, time.Minute)
+			}
+
+			info := &grpc.UnaryServerInfo{FullMethod: tc.method}
+			res, err := server.AuthorizationInterceptor(ctx, nil, info, handler)
+			require.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				require.Equal(t, "ok", res)
+			}
+		})
+	}
+}
+
+func TestGetAuthPayloadChecksMethodRoles(t *testing.T) {
+	server := newTestServer(t, nil, nil)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, util.RandomOwner(), util.SupportRole, time.Minute)
+
+	// Handlers reached through the gateway skip the interceptor but still know the method
+	transferCtx := newContextWithMethod(ctx, "/pb.VaultguardAPI/CreateTransfer")
+	_, err := server.getAuthPayload(transferCtx)
+	require.ErrorIs(t, err, errPermissionDenied)
+	require.Equal(t, codes.PermissionDenied, status.Code(authError(err)))
+
+	accountCtx := newContextWithMethod(ctx, "/pb.VaultguardAPI/GetAccount")
+	payload, err := server.getAuthPayload(accountCtx)
+	require.NoError(t, err)
+	require.Equal(t, util.SupportRole, payload.Role)
+
+	// Without a method there are no roles to check against
+	adminCtx := newContextWithBearerToken(t, server.tokenMaker, util.RandomOwner(), util.AdminRole, time.Minute)
+	_, err = server.getAuthPayload(adminCtx)
+	require.ErrorIs(t, err, errPermissionDenied)
+}
+
+func TestSupportCanReadAnyAccount(t *testing.T) {
+	owner, _ := randomUser(t)
+	account := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner.Username,
+		Balance:  util.RandomMoney(),
+		Currency: util.USD,
+	}
+
+	testCases := []struct {
+		name string
+		role string
+		code codes.Code
+	}{
+		{name: "Support", role: util.SupportRole, code: codes.OK},
+		{name: "Admin", role: util.AdminRole, code: codes.OK},
+		{name: "OtherDepositor", role: util.DepositorRole, code: codes.PermissionDenied},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+
+			server := newTestServer(t, store, nil)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, util.RandomOwner(), tc.role, time.Minute)
+
+			res, err := server.GetAccount(newContextWithMethod(ctx, "/pb.VaultguardAPI/GetAccount"), &pb.GetAccountRequest{Id: account.ID})
+			require.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK {
+				require.Equal(t, account.ID, res.GetAccount().GetId())
+			}
+		})
+	}
+}
+
+func TestAuthorizationRejectsTokensIssuedBeforePasswordChange(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+
+	server := newTestServer(t, store, nil)
+	server.passwordChanges = token.NewPasswordChangeCache(db.PasswordChangedAt(store), time.Minute)
+	info := &grpc.UnaryServerInfo{FullMethod: "/pb.VaultguardAPI/GetAccount"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	oldCtx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role, time.Minute)
+	user.PasswordChangedAt = time.Now()
+	newCtx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role, time.Minute)
+
+	// The user is only looked up once while the entry is cached
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+
+	_, err := server.AuthorizationInterceptor(oldCtx, nil, info, handler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	res, err := server.AuthorizationInterceptor(newCtx, nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", res)
+
+	_, err = server.getAuthPayload(newContextWithMethod(oldCtx, info.FullMethod))
+	require.Error(t, err)
+	require.NotErrorIs(t, err, errPermissionDenied)
+}
@@ -7,12 +7,86 @@ import (
 )
 
 func convertAccount(account db.Account) *pb.Account {
-	return &pb.Account{
-		Id:            account.ID,
-		Owner:         account.Owner,
-		Balance:       account.Balance,
-		Currency:      account.Currency,
-		CreatedAt:     timestamppb.New(account.CreatedAt),
-		AccountNumber: account.AccountNumber.String,
+	pbAccount := &pb.Account{
+		Id:               account.ID,
+		Owner:            account.Owner,
+		Balance:          account.Balance,
+		Currency:         account.Currency,
+		CreatedAt:        timestamppb.New(account.CreatedAt),
+		AccountNumber:    account.AccountNumber.String,
+		OverdraftLimit:   account.OverdraftLimit,
+		Status:           account.Status,
+		AvailableBalance: account.Balance - account.HeldAmount,
 	}
+	if account.ClosedAt.Valid {
+		pbAccount.ClosedAt = timestamppb.New(account.ClosedAt.Time)
+	}
+	return pbAccount
+}
+
+func convertSession(session db.Session) *pb.Session {
+	return &pb.Session{
+		Id:        session.ID.String(),
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		CreatedAt: timestamppb.New(session.CreatedAt),
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+	}
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:         entry.ID,
+		AccountId:  entry.AccountID,
+		Amount:     entry.Amount,
+		Currency:   entry.Currency,
+		CreatedAt:  timestamppb.New(entry.CreatedAt),
+		TransferId: entry.TransferID.Int64,
+	}
+}
+
+func convertAccountAdjustment(adjustment db.AccountAdjustment) *pb.AccountAdjustment {
+	return &pb.AccountAdjustment{
+		Id:         adjustment.ID,
+		AccountId:  adjustment.AccountID,
+		EntryId:    adjustment.EntryID,
+		Amount:     adjustment.Amount,
+		Reason:     adjustment.Reason,
+		CreatedBy:  adjustment.CreatedBy,
+		CreatedAt:  timestamppb.New(adjustment.CreatedAt),
+		TransferId: adjustment.TransferID,
+	}
+}
+
+func convertPendingTransfer(pending db.PendingTransfer) *pb.PendingTransfer {
+	return &pb.PendingTransfer{
+		Id:            pending.ID,
+		FromAccountId: pending.FromAccountID,
+		ToAccountId:   pending.ToAccountID,
+		Amount:        pending.Amount,
+		Currency:      pending.Currency,
+		ToAmount:      pending.ToAmount,
+		ToCurrency:    pending.ToCurrency,
+		ExchangeRate:  pending.ExchangeRate,
+		Status:        pending.Status,
+		ExpiresAt:     timestamppb.New(pending.ExpiresAt),
+		CreatedAt:     timestamppb.New(pending.CreatedAt),
+	}
+}
+
+func convertLedgerDiscrepancy(discrepancy db.LedgerDiscrepancy) *pb.LedgerDiscrepancy {
+	rsp := &pb.LedgerDiscrepancy{
+		Id:         discrepancy.ID,
+		RunId:      discrepancy.RunID,
+		Kind:       discrepancy.Kind,
+		AccountId:  discrepancy.AccountID.Int64,
+		TransferId: discrepancy.TransferID.Int64,
+		Expected:   discrepancy.Expected,
+		Actual:     discrepancy.Actual,
+	}
+	// Discrepancies in a report aren't recorded and have no creation time
+	if !discrepancy.CreatedAt.IsZero() {
+		rsp.CreatedAt = timestamppb.New(discrepancy.CreatedAt)
+	}
+	return rsp
 }
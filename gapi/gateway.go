@@ -0,0 +1,142 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const exportStatementPath = "/v1/accounts/{account_id}/statement"
+
+var exportStatementQueryFilter = utilities.NewDoubleArray([][]string{{"account_id"}})
+
+// OutgoingHeaderMatcher maps response metadata to HTTP headers for the gateway.
+// Content-Disposition is passed through as is so statement exports download as files.
+func OutgoingHeaderMatcher(key string) (string, bool) {
+	if key == contentDispositionHeader {
+		return "Content-Disposition", true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// RegisterStatementHandler serves ExportStatement on the gateway. The generated
+// in-process handler can't run streaming calls, so this one streams the statement
+// straight into the response as it is rendered. It must be registered after the
+// generated handlers so it takes their place.
+func RegisterStatementHandler(mux *runtime.ServeMux, server *Server) error {
+	return mux.HandlePath(http.MethodGet, exportStatementPath, func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, outboundMarshaler := runtime.MarshalerForRequest(mux, r)
+		ctx, err := runtime.AnnotateIncomingContext(r.Context(), mux, r, pb.VaultguardAPI_ExportStatement_FullMethodName, runtime.WithHTTPPathPattern(exportStatementPath))
+		if err != nil {
+			runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, err)
+			return
+		}
+
+		stream := &gatewayBodyStream{ctx: ctx, w: w}
+		req, err := parseExportStatementRequest(r, pathParams)
+		if err == nil {
+			err = server.ExportStatement(req, stream)
+		}
+		if err != nil {
+			// Once the body has started, the status can't be changed any more
+			if stream.wroteHeader {
+				log.Error().Err(err).Msg("failed to stream statement")
+				return
+			}
+			ctx = runtime.NewServerMetadataContext(ctx, runtime.ServerMetadata{HeaderMD: stream.header})
+			runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+		}
+	})
+}
+
+func parseExportStatementRequest(r *http.Request, pathParams map[string]string) (*pb.ExportStatementRequest, error) {
+	req := &pb.ExportStatementRequest{}
+
+	accountID, err := runtime.Int64(pathParams["account_id"])
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "type mismatch, parameter: %s, error: %v", "account_id", err)
+	}
+	req.AccountId = accountID
+
+	if err := r.ParseForm(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	if err := runtime.PopulateQueryParameters(req, r.Form, exportStatementQueryFilter); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return req, nil
+}
+
+// gatewayBodyStream writes each HttpBody sent on it to an HTTP response as it comes,
+// after the headers set on the stream
+type gatewayBodyStream struct {
+	ctx         context.Context
+	w           http.ResponseWriter
+	header      metadata.MD
+	wroteHeader bool
+}
+
+func (s *gatewayBodyStream) Send(body *httpbody.HttpBody) error {
+	if !s.wroteHeader {
+		for key, values := range s.header {
+			if name, ok := OutgoingHeaderMatcher(key); ok {
+				for _, value := range values {
+					s.w.Header().Add(name, value)
+				}
+			}
+		}
+		s.w.Header().Set("Content-Type", body.GetContentType())
+		s.w.WriteHeader(http.StatusOK)
+		s.wroteHeader = true
+	}
+
+	if _, err := s.w.Write(body.GetData()); err != nil {
+		return err
+	}
+	// Each chunk goes out as soon as it is sent rather than when the handler returns
+	err := http.NewResponseController(s.w).Flush()
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+func (s *gatewayBodyStream) SetHeader(md metadata.MD) error {
+	if s.wroteHeader {
+		return status.Errorf(codes.Internal, "headers already sent")
+	}
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *gatewayBodyStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *gatewayBodyStream) SetTrailer(metadata.MD) {}
+
+func (s *gatewayBodyStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *gatewayBodyStream) SendMsg(m any) error {
+	body, ok := m.(*httpbody.HttpBody)
+	if !ok {
+		return status.Errorf(codes.Internal, "unexpected message type %T", m)
+	}
+	return s.Send(body)
+}
+
+func (s *gatewayBodyStream) RecvMsg(any) error {
+	return io.EOF
+}
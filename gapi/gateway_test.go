@@ -0,0 +1,120 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	mockwk "github.com/OmSingh2003/nimbus/worker/mock"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestStatementHandler(t *testing.T) {
+	user, _ := randomUser(t)
+	account := db.Account{
+		ID:        util.RandomInt(1, 1000),
+		Owner:     user.Username,
+		Balance:   util.RandomMoney(),
+		Currency:  util.USD,
+		CreatedAt: time.Now().Add(-48 * time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		path          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			path: fmt.Sprintf("/v1/accounts/%d/statement?format=csv", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CountAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					GetAccountStatementBalances(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetAccountStatementBalancesRow{OpeningBalance: 1000, ClosingBalance: 1000}, nil)
+				store.EXPECT().
+					ListAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListAccountStatementEntriesRow{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), "attachment; filename=\"statement-")
+				require.True(t, strings.HasPrefix(recorder.Body.String(), "date,entry_id,"))
+			},
+		},
+		{
+			name: "InvalidAccountID",
+			path: "/v1/accounts/abc/statement?format=csv",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidFormat",
+			path: fmt.Sprintf("/v1/accounts/%d/statement?format=xlsx", account.ID),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, mockwk.NewMockTaskDistributor(ctrl))
+			server.config.StatementInlineLimit = 100
+
+			mux := runtime.NewServeMux(runtime.WithOutgoingHeaderMatcher(OutgoingHeaderMatcher))
+			require.NoError(t, pb.RegisterVaultguardAPIHandlerServer(context.Background(), mux, server))
+			require.NoError(t, RegisterStatementHandler(mux, server))
+
+			accessToken, _, err := server.tokenMaker.CreateToken(user.Username, util.DepositorRole, time.Minute)
+			require.NoError(t, err)
+
+			request := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			request.Header.Set("Authorization", fmt.Sprintf("%s %s", authorizationBearer, accessToken))
+
+			recorder := httptest.NewRecorder()
+			mux.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/OmSingh2003/nimbus/worker"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Scopes that failed logins are counted under
+const (
+	loginScopeUsername = "username"
+	loginScopeIP       = "ip"
+)
+
+// errInvalidLogin is returned for both unknown usernames and wrong passwords,
+// so the response doesn't reveal whether a username exists
+var errInvalidLogin = status.Error(codes.Unauthenticated, "invalid username or password")
+
+var errLoginLocked = status.Error(codes.ResourceExhausted, "too many failed login attempts, please try again later")
+
+// dummyPasswordHash is checked against when the username doesn't exist, so
+// unknown usernames take as long to reject as wrong passwords
+var dummyPasswordHash = sync.OnceValue(func() string {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(util.RandomString(32)), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return string(hashedPassword)
+})
+
+// loginKeys returns the keys to throttle a login attempt for username by
+func (server *Server) loginKeys(ctx context.Context, username string) []db.LoginFailureKey {
+	keys := []db.LoginFailureKey{{Scope: loginScopeUsername, Key: username}}
+	if clientIP := normalizeClientIP(server.extractMetadata(ctx).ClientIP); clientIP != "" {
+		keys = append(keys, db.LoginFailureKey{Scope: loginScopeIP, Key: clientIP})
+	}
+	return keys
+}
+
+// normalizeClientIP drops the port from a peer address
+func normalizeClientIP(clientIP string) string {
+	if host, _, err := net.SplitHostPort(clientIP); err == nil {
+		return host
+	}
+	return clientIP
+}
+
+// reserveLoginAttempt counts a login attempt as failed against each of the keys
+// before it is checked, and blocks further attempts for a while. The wait doubles
+// with each failure until the limit is reached, then the key is locked out.
+// Concurrent attempts are counted one after another, so they can't get past the wait.
+func (server *Server) reserveLoginAttempt(ctx context.Context, keys []db.LoginFailureKey) (db.ReserveLoginAttemptTxResult, error) {
+	reservation, err := server.store.ReserveLoginAttemptTx(ctx, db.ReserveLoginAttemptTxParams{
+		Keys:        keys,
+		ResetBefore: time.Now().Add(-server.config.LoginLockoutDuration),
+		LockFor: func(failures int64) time.Duration {
+			if failures >= server.config.LoginMaxFailures {
+				return server.config.LoginLockoutDuration
+			}
+			return server.loginBackoff(failures)
+		},
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrLoginLocked) {
+			return reservation, errLoginLocked
+		}
+		return reservation, status.Errorf(codes.Internal, "failed to reserve login attempt: %s", err)
+	}
+	return reservation, nil
+}
+
+// loginAttemptFailed keeps a reserved attempt counted as failed, and lets the user
+// know if it locked their account. user is nil if the username doesn't exist.
+func (server *Server) loginAttemptFailed(ctx context.Context, reservation db.ReserveLoginAttemptTxResult, user *db.User) {
+	for _, failure := range reservation.Reserved {
+		lockedOut := failure.FailureCount >= server.config.LoginMaxFailures
+		if lockedOut && failure.Scope == loginScopeUsername && user != nil {
+			server.sendLockoutEmail(ctx, user.Username, failure.LockedUntil)
+		}
+	}
+}
+
+// releaseLoginAttempt takes back a reserved attempt once it turns out to be correct,
+// leaving the keys as they were before it. Attempts reserved since stay counted.
+func (server *Server) releaseLoginAttempt(ctx context.Context, reservation db.ReserveLoginAttemptTxResult) error {
+	for i, failure := range reservation.Previous {
+		err := server.store.ReleaseLoginAttempt(ctx, db.ReleaseLoginAttemptParams{
+			FailureCount:  failure.FailureCount,
+			LastFailedAt:  failure.LastFailedAt,
+			LockedUntil:   failure.LockedUntil,
+			Scope:         failure.Scope,
+			Key:           failure.Key,
+			ReservedUntil: reservation.Reserved[i].LockedUntil,
+		})
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to release login attempt: %s", err)
+		}
+	}
+	return nil
+}
+
+// loginBackoff is how long a key waits after its nth failed login in a row
+func (server *Server) loginBackoff(failures int64) time.Duration {
+	if failures < 1 {
+		return 0
+	}
+	backoff := server.config.LoginBackoffBase
+	for i := int64(1); i < failures; i++ {
+		backoff *= 2
+		if backoff >= server.config.LoginLockoutDuration {
+			return server.config.LoginLockoutDuration
+		}
+	}
+	return backoff
+}
+
+// sendLockoutEmail lets the user know their account was locked. It doesn't fail
+// the login, since that would reveal that the username exists.
+func (server *Server) sendLockoutEmail(ctx context.Context, username string, lockedUntil time.Time) {
+	taskPayload := &worker.PayloadSendLockoutEmail{
+		Username:    username,
+		LockedUntil: lockedUntil,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(worker.QueueCritical),
+	}
+	err := server.taskDistributor.DistributeTaskSendLockoutEmail(ctx, taskPayload, opts...)
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("failed to distribute lockout email task")
+	}
+}
+
+// clearLoginFailures resets the username's failed login count after a successful login.
+// The client IP keeps its count, so logging in to one account doesn't allow more
+// guesses against others.
+func (server *Server) clearLoginFailures(ctx context.Context, username string) error {
+	err := server.store.DeleteLoginFailure(ctx, db.DeleteLoginFailureParams{
+		Scope: loginScopeUsername,
+		Key:   username,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to clear login failures: %s", err)
+	}
+	return nil
+}
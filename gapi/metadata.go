@@ -3,7 +3,9 @@ package gapi
 import (
 	"context"
 	"log"
+	"strings"
 
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 )
@@ -32,19 +34,42 @@ func (server *Server) extractMetadata(ctx context.Context) *Metadata {
 			// Fallback to standard user-agent header for direct gRPC calls
 			mtdt.UserAgent = userAgents[0]
 		}
-		
-		// Try to get client IP from X-Forwarded-For header (from gRPC Gateway)
-		if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
-			mtdt.ClientIP = clientIPs[0]
-		}
 	}
-	
-	// If no client IP from headers, try to get it from peer info (direct gRPC)
-	if mtdt.ClientIP == "" {
-		if peer, ok := peer.FromContext(ctx); ok {
-			mtdt.ClientIP = peer.Addr.String()
-		}
+
+	// Direct gRPC callers can send any metadata, so their address comes from the
+	// connection. Calls through the HTTP gateway have no peer; the gateway appends the
+	// HTTP client's address to X-Forwarded-For, so only the trusted hops on the right count.
+	if peer, ok := peer.FromContext(ctx); ok {
+		mtdt.ClientIP = peer.Addr.String()
+	} else if md, ok := metadata.FromIncomingContext(ctx); ok {
+		mtdt.ClientIP = forwardedClientIP(md.Get(xForwardedForHeader), server.config.TrustedProxyHops)
 	}
-	
+
 	return mtdt
 }
+
+// forwardedClientIP picks the client's address out of X-Forwarded-For values, skipping
+// the trustedHops entries added by proxies in front of the gateway. Anything further
+// left was sent by the client and can't be trusted.
+func forwardedClientIP(values []string, trustedHops int) string {
+	var hops []string
+	for _, value := range values {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	if len(hops) == 0 {
+		return ""
+	}
+
+	i := max(len(hops)-1-trustedHops, 0)
+	return strings.TrimSpace(hops[i])
+}
+
+// auditContext describes the caller for the audit log
+func (server *Server) auditContext(ctx context.Context, actor string) db.AuditContext {
+	mtdt := server.extractMetadata(ctx)
+	return db.AuditContext{
+		Actor:     actor,
+		ClientIP:  mtdt.ClientIP,
+		UserAgent: mtdt.UserAgent,
+	}
+}
@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestExtractMetadataClientIP(t *testing.T) {
+	testCases := []struct {
+		name        string
+		ctx         context.Context
+		trustedHops int
+		clientIP    string
+	}{
+		{
+			name:     "Gateway",
+			ctx:      newContextWithClientIP("203.0.113.7"),
+			clientIP: "203.0.113.7",
+		},
+		{
+			name:     "GatewaySpoofedForwardedFor",
+			ctx:      newContextWithClientIP("198.51.100.1, 203.0.113.7"),
+			clientIP: "203.0.113.7",
+		},
+		{
+			name:        "GatewayBehindProxy",
+			ctx:         newContextWithClientIP("198.51.100.1, 203.0.113.7, 10.0.0.2"),
+			trustedHops: 1,
+			clientIP:    "203.0.113.7",
+		},
+		{
+			name:        "TooFewHops",
+			ctx:         newContextWithClientIP("203.0.113.7"),
+			trustedHops: 2,
+			clientIP:    "203.0.113.7",
+		},
+		{
+			name: "DirectGRPC",
+			ctx: peer.NewContext(newContextWithClientIP("198.51.100.1"), &peer.Peer{
+				Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 5555},
+			}),
+			clientIP: "203.0.113.7:5555",
+		},
+		{
+			name:     "NoAddress",
+			ctx:      metadata.NewIncomingContext(context.Background(), metadata.MD{}),
+			clientIP: "",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, nil, nil)
+			server.config.TrustedProxyHops = tc.trustedHops
+
+			require.Equal(t, tc.clientIP, server.extractMetadata(tc.ctx).ClientIP)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package gapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// pageToken marks the last row of a page; list RPCs resume strictly after it in (created_at, id) order
+type pageToken struct {
+	CreatedAt time.Time `json:"c"`
+	ID        int64     `json:"i"`
+}
+
+var errInvalidPageToken = errors.New("invalid page token")
+
+// encodePageToken turns the position of the last row into an opaque next_page_token
+func encodePageToken(createdAt time.Time, id int64) string {
+	data, _ := json.Marshal(pageToken{CreatedAt: createdAt.UTC(), ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePageToken parses a page_token produced by encodePageToken
+func decodePageToken(value string) (pageToken, error) {
+	var token pageToken
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return token, errInvalidPageToken
+	}
+
+	if err := json.Unmarshal(data, &token); err != nil || token.ID <= 0 {
+		return token, errInvalidPageToken
+	}
+
+	return token, nil
+}
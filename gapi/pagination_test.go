@@ -0,0 +1,26 @@
+package gapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageToken(t *testing.T) {
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	value := encodePageToken(createdAt, 42)
+	require.NotEmpty(t, value)
+
+	token, err := decodePageToken(value)
+	require.NoError(t, err)
+	require.True(t, createdAt.Equal(token.CreatedAt))
+	require.Equal(t, int64(42), token.ID)
+
+	_, err = decodePageToken("not a token")
+	require.ErrorIs(t, err, errInvalidPageToken)
+
+	_, err = decodePageToken(encodePageToken(createdAt, 0))
+	require.ErrorIs(t, err, errInvalidPageToken)
+}
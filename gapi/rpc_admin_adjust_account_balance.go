@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (admin *AdminServer) AdjustAccountBalance(ctx context.Context, req *pb.AdjustAccountBalanceRequest) (*pb.AdjustAccountBalanceResponse, error) {
+	authPayload, err := admin.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateAdjustAccountBalanceRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	result, err := admin.server.store.AdjustAccountBalanceTx(ctx, db.AdjustAccountBalanceTxParams{
+		AccountID: req.GetAccountId(),
+		Amount:    req.GetAmount(),
+		Reason:    strings.TrimSpace(req.GetReason()),
+		CreatedBy: authPayload.Username,
+		Audit:     admin.server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		if errors.Is(err, db.ErrAccountClosed) {
+			return nil, status.Errorf(codes.FailedPrecondition, "account is closed")
+		}
+		if errors.Is(err, db.ErrTreasuryAccount) {
+			return nil, status.Errorf(codes.FailedPrecondition, "treasury accounts can't be adjusted")
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			return nil, status.Errorf(codes.FailedPrecondition, "adjustment would take the balance below the overdraft limit")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to adjust account balance: %s", err)
+	}
+
+	rsp := &pb.AdjustAccountBalanceResponse{
+		Account:    convertAccount(result.Account),
+		Entry:      convertEntry(result.Entry),
+		Adjustment: convertAccountAdjustment(result.Adjustment),
+	}
+	return rsp, nil
+}
+
+func validateAdjustAccountBalanceRequest(req *pb.AdjustAccountBalanceRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := val.ValidateAdjustmentAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateReason(req.GetReason()); err != nil {
+		violations = append(violations, fieldViolation("reason", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,257 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdjustAccountBalanceAPI(t *testing.T) {
+	adminUser, _ := randomUser(t)
+	adminUser.Role = util.AdminRole
+	account := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Balance:  100,
+		Currency: util.USD,
+		Status:   util.AccountStatusActive,
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.AdjustAccountBalanceRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.AdjustAccountBalanceResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.AdjustAccountBalanceRequest{
+				AccountId: account.ID,
+				Amount:    -25,
+				Reason:    "  reverse duplicate deposit ",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				adjusted := account
+				adjusted.Balance = 75
+				store.EXPECT().
+					AdjustAccountBalanceTx(gomock.Any(), gomock.Eq(db.AdjustAccountBalanceTxParams{
+						AccountID: account.ID,
+						Amount:    -25,
+						Reason:    "reverse duplicate deposit",
+						CreatedBy: adminUser.Username,
+						Audit:     db.AuditContext{Actor: adminUser.Username},
+					})).
+					Times(1).
+					Return(db.AdjustAccountBalanceTxResult{
+						Account: adjusted,
+						Entry:   db.Entry{ID: 7, AccountID: account.ID, Amount: -25, Currency: util.USD},
+						Adjustment: db.AccountAdjustment{
+							ID:        3,
+							AccountID: account.ID,
+							EntryID:   7,
+							Amount:    -25,
+							Reason:    "reverse duplicate deposit",
+							CreatedBy: adminUser.Username,
+						},
+					}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AdjustAccountBalanceResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(75), rsp.GetAccount().GetBalance())
+				require.Equal(t, int64(7), rsp.GetEntry().GetId())
+				require.Equal(t, int64(7), rsp.GetAdjustment().GetEntryId())
+				require.Equal(t, adminUser.Username, rsp.GetAdjustment().GetCreatedBy())
+			},
+		},
+		{
+			name: "MissingReason",
+			req: &pb.AdjustAccountBalanceRequest{
+				AccountId: account.ID,
+				Amount:    -25,
+				Reason:    "   ",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AdjustAccountBalanceResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "ZeroAmount",
+			req: &pb.AdjustAccountBalanceRequest{
+				AccountId: account.ID,
+				Amount:    0,
+				Reason:    "reverse duplicate deposit",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AdjustAccountBalanceResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "AccountNotFound",
+			req: &pb.AdjustAccountBalanceRequest{
+				AccountId: account.ID,
+				Amount:    25,
+				Reason:    "missing interest payment",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdjustAccountBalanceTxResult{}, sql.ErrNoRows)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AdjustAccountBalanceResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req: &pb.AdjustAccountBalanceRequest{
+				AccountId: account.ID,
+				Amount:    -500,
+				Reason:    "reverse duplicate deposit",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdjustAccountBalanceTxResult{}, db.ErrInsufficientFunds)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AdjustAccountBalanceResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "SupportRoleDenied",
+			req: &pb.AdjustAccountBalanceRequest{
+				AccountId: account.ID,
+				Amount:    25,
+				Reason:    "missing interest payment",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.SupportRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AdjustAccountBalanceResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req: &pb.AdjustAccountBalanceRequest{
+				AccountId: account.ID,
+				Amount:    25,
+				Reason:    "missing interest payment",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					AdjustAccountBalanceTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AdjustAccountBalanceResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			admin := NewAdminServer(newTestServer(t, store, nil))
+
+			ctx := tc.buildContext(t, admin.server.tokenMaker)
+			rsp, err := admin.AdjustAccountBalance(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestFreezeAccountAPI(t *testing.T) {
+	adminUser, _ := randomUser(t)
+	account := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Currency: util.USD,
+		Status:   util.AccountStatusFrozen,
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+
+	store.EXPECT().
+		UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.UpdateAccountStatusTxParams) (db.UpdateAccountStatusTxResult, error) {
+			require.Equal(t, account.ID, arg.ID)
+			require.Equal(t, util.AccountStatusFrozen, arg.Status)
+			require.Equal(t, adminUser.Username, arg.Audit.Actor)
+			return db.UpdateAccountStatusTxResult{Account: account}, nil
+		})
+
+	admin := NewAdminServer(newTestServer(t, store, nil))
+
+	// Depositors can't reach the admin service even for their own accounts
+	ctx := newContextWithBearerToken(t, admin.server.tokenMaker, account.Owner, util.DepositorRole, time.Minute)
+	_, err := admin.FreezeAccount(ctx, &pb.FreezeAccountRequest{Id: account.ID})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	ctx = newContextWithBearerToken(t, admin.server.tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+	rsp, err := admin.FreezeAccount(ctx, &pb.FreezeAccountRequest{Id: account.ID})
+	require.NoError(t, err)
+	require.Equal(t, util.AccountStatusFrozen, rsp.GetAccount().GetStatus())
+}
@@ -0,0 +1,70 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (admin *AdminServer) FreezeAccount(ctx context.Context, req *pb.FreezeAccountRequest) (*pb.FreezeAccountResponse, error) {
+	account, err := admin.setAccountStatus(ctx, req.GetId(), util.AccountStatusFrozen)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &pb.FreezeAccountResponse{
+		Account: convertAccount(account),
+	}
+	return rsp, nil
+}
+
+func (admin *AdminServer) UnfreezeAccount(ctx context.Context, req *pb.UnfreezeAccountRequest) (*pb.UnfreezeAccountResponse, error) {
+	account, err := admin.setAccountStatus(ctx, req.GetId(), util.AccountStatusActive)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &pb.UnfreezeAccountResponse{
+		Account: convertAccount(account),
+	}
+	return rsp, nil
+}
+
+// setAccountStatus authorizes the admin and moves the account to the given status
+func (admin *AdminServer) setAccountStatus(ctx context.Context, accountID int64, accountStatus string) (db.Account, error) {
+	authPayload, err := admin.authorizeAdmin(ctx)
+	if err != nil {
+		return db.Account{}, authError(err)
+	}
+
+	if err := val.ValidateID(accountID); err != nil {
+		return db.Account{}, InvalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", err)})
+	}
+
+	txResult, err := admin.server.store.UpdateAccountStatusTx(ctx, db.UpdateAccountStatusTxParams{
+		UpdateAccountStatusParams: db.UpdateAccountStatusParams{
+			ID:     accountID,
+			Status: accountStatus,
+		},
+		Audit: admin.server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Account{}, status.Errorf(codes.NotFound, "account not found")
+		}
+		if errors.Is(err, db.ErrAccountClosed) {
+			return db.Account{}, status.Errorf(codes.FailedPrecondition, "account is closed")
+		}
+		return db.Account{}, status.Errorf(codes.Internal, "failed to update account status: %s", err)
+	}
+
+	return txResult.Account, nil
+}
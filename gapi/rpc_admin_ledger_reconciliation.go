@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/worker"
+	"github.com/hibiken/asynq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxLedgerReportWindow bounds how much of the ledger one report scans
+const maxLedgerReportWindow = 31 * 24 * time.Hour
+
+func (admin *AdminServer) RunLedgerReconciliation(ctx context.Context, req *pb.RunLedgerReconciliationRequest) (*pb.RunLedgerReconciliationResponse, error) {
+	authPayload, err := admin.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	// The run goes through the worker like the scheduled ones, so runs never overlap
+	// and the discrepancies are emailed the same way
+	err = admin.server.taskDistributor.DistributeTaskReconcileLedger(ctx, &worker.PayloadReconcileLedger{
+		TriggeredBy: authPayload.Username,
+	}, asynq.MaxRetry(10), asynq.Queue(worker.QueueDefault))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to distribute ledger reconciliation task: %s", err)
+	}
+
+	return &pb.RunLedgerReconciliationResponse{}, nil
+}
+
+func (admin *AdminServer) GetLedgerReport(ctx context.Context, req *pb.GetLedgerReportRequest) (*pb.GetLedgerReportResponse, error) {
+	_, err := admin.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateGetLedgerReportRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	result, err := admin.server.store.ReportLedgerTx(ctx, db.ReportLedgerTxParams{
+		StartTime: req.GetStartTime().AsTime(),
+		EndTime:   req.GetEndTime().AsTime(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to report on ledger: %s", err)
+	}
+
+	rsp := &pb.GetLedgerReportResponse{
+		Discrepancies: make([]*pb.LedgerDiscrepancy, len(result.Discrepancies)),
+	}
+	for i, discrepancy := range result.Discrepancies {
+		rsp.Discrepancies[i] = convertLedgerDiscrepancy(discrepancy)
+	}
+	return rsp, nil
+}
+
+func validateGetLedgerReportRequest(req *pb.GetLedgerReportRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetStartTime() == nil {
+		violations = append(violations, fieldViolation("start_time", errors.New("is required")))
+	}
+
+	if req.GetEndTime() == nil {
+		violations = append(violations, fieldViolation("end_time", errors.New("is required")))
+	}
+
+	if req.GetStartTime() != nil && req.GetEndTime() != nil {
+		window := req.GetEndTime().AsTime().Sub(req.GetStartTime().AsTime())
+		if window <= 0 {
+			violations = append(violations, fieldViolation("end_time", errors.New("must be after start_time")))
+		} else if window > maxLedgerReportWindow {
+			violations = append(violations, fieldViolation("end_time", errors.New("must be at most 31 days after start_time")))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,270 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/OmSingh2003/nimbus/worker"
+	mockwk "github.com/OmSingh2003/nimbus/worker/mock"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestRunLedgerReconciliationAPI(t *testing.T) {
+	adminUser, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(taskDistributor *mockwk.MockTaskDistributor)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.RunLedgerReconciliationResponse, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(taskDistributor *mockwk.MockTaskDistributor) {
+				taskDistributor.EXPECT().
+					DistributeTaskReconcileLedger(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, payload *worker.PayloadReconcileLedger, opts ...asynq.Option) error {
+						require.Equal(t, adminUser.Username, payload.TriggeredBy)
+						return nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.RunLedgerReconciliationResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, rsp)
+			},
+		},
+		{
+			name: "DistributeError",
+			buildStubs: func(taskDistributor *mockwk.MockTaskDistributor) {
+				taskDistributor.EXPECT().
+					DistributeTaskReconcileLedger(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(fmt.Errorf("redis is down"))
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.RunLedgerReconciliationResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NotAdmin",
+			buildStubs: func(taskDistributor *mockwk.MockTaskDistributor) {
+				taskDistributor.EXPECT().
+					DistributeTaskReconcileLedger(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.SupportRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.RunLedgerReconciliationResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			taskCtrl := gomock.NewController(t)
+			defer taskCtrl.Finish()
+			taskDistributor := mockwk.NewMockTaskDistributor(taskCtrl)
+
+			tc.buildStubs(taskDistributor)
+			admin := NewAdminServer(newTestServer(t, store, taskDistributor))
+
+			ctx := tc.buildContext(t, admin.server.tokenMaker)
+			rsp, err := admin.RunLedgerReconciliation(ctx, &pb.RunLedgerReconciliationRequest{})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestGetLedgerReportAPI(t *testing.T) {
+	adminUser, _ := randomUser(t)
+	endTime := time.Now().Truncate(time.Second)
+	startTime := endTime.Add(-24 * time.Hour)
+
+	discrepancy := db.LedgerDiscrepancy{
+		Kind:      db.DiscrepancyAccountBalance,
+		AccountID: sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true},
+		Expected:  100,
+		Actual:    150,
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.GetLedgerReportRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.GetLedgerReportResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.GetLedgerReportRequest{
+				StartTime: timestamppb.New(startTime),
+				EndTime:   timestamppb.New(endTime),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReportLedgerTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ReportLedgerTxParams) (db.ReportLedgerTxResult, error) {
+						require.True(t, startTime.Equal(arg.StartTime))
+						require.True(t, endTime.Equal(arg.EndTime))
+						return db.ReportLedgerTxResult{Discrepancies: []db.LedgerDiscrepancy{discrepancy}}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetLedgerReportResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, rsp.GetDiscrepancies(), 1)
+
+				got := rsp.GetDiscrepancies()[0]
+				require.Zero(t, got.GetId())
+				require.Equal(t, db.DiscrepancyAccountBalance, got.GetKind())
+				require.Equal(t, discrepancy.AccountID.Int64, got.GetAccountId())
+				require.Equal(t, int64(100), got.GetExpected())
+				require.Equal(t, int64(150), got.GetActual())
+				require.Nil(t, got.GetCreatedAt())
+			},
+		},
+		{
+			name: "MissingWindow",
+			req:  &pb.GetLedgerReportRequest{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReportLedgerTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetLedgerReportResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "EndBeforeStart",
+			req: &pb.GetLedgerReportRequest{
+				StartTime: timestamppb.New(endTime),
+				EndTime:   timestamppb.New(startTime),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReportLedgerTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetLedgerReportResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "WindowTooLong",
+			req: &pb.GetLedgerReportRequest{
+				StartTime: timestamppb.New(endTime.Add(-32 * 24 * time.Hour)),
+				EndTime:   timestamppb.New(endTime),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReportLedgerTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetLedgerReportResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req: &pb.GetLedgerReportRequest{
+				StartTime: timestamppb.New(startTime),
+				EndTime:   timestamppb.New(endTime),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReportLedgerTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReportLedgerTxResult{}, sql.ErrConnDone)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetLedgerReportResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "NotAdmin",
+			req: &pb.GetLedgerReportRequest{
+				StartTime: timestamppb.New(startTime),
+				EndTime:   timestamppb.New(endTime),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReportLedgerTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.GetLedgerReportResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			admin := NewAdminServer(newTestServer(t, store, nil))
+
+			ctx := tc.buildContext(t, admin.server.tokenMaker)
+			rsp, err := admin.GetLedgerReport(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package gapi
+
+import (
+	"context"
+	"strings"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (admin *AdminServer) ReverseTransfer(ctx context.Context, req *pb.AdminReverseTransferRequest) (*pb.ReverseTransferResponse, error) {
+	authPayload, err := admin.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateAdminReverseTransferRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	result, err := admin.server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: req.GetTransferId(),
+		Amount:     req.GetAmount(),
+		Force:      req.GetForce(),
+		Reason:     strings.TrimSpace(req.GetReason()),
+		Audit:      admin.server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		return nil, reverseTransferError(err)
+	}
+
+	rsp := &pb.ReverseTransferResponse{
+		Transfer:         convertTransfer(result.Reversal.Transfer),
+		OriginalTransfer: convertTransfer(result.Original),
+	}
+	return rsp, nil
+}
+
+func validateAdminReverseTransferRequest(req *pb.AdminReverseTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetTransferId()); err != nil {
+		violations = append(violations, fieldViolation("transfer_id", err))
+	}
+
+	// 0 reverses whatever is left
+	if req.GetAmount() != 0 {
+		if err := val.ValidateAmount(req.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+
+	if err := val.ValidateReason(req.GetReason()); err != nil {
+		violations = append(violations, fieldViolation("reason", err))
+	}
+
+	return violations
+}
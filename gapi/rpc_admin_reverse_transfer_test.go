@@ -0,0 +1,158 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminReverseTransferAPI(t *testing.T) {
+	adminUser, _ := randomUser(t)
+	transfer := randomTransfer(util.RandomInt(1, 1000), util.RandomInt(1001, 2000))
+
+	testCases := []struct {
+		name          string
+		req           *pb.AdminReverseTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.ReverseTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.AdminReverseTransferRequest{
+				TransferId: transfer.ID,
+				Reason:     "chargeback from card issuer",
+				Force:      true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ReverseTransferTxParams) (db.ReverseTransferTxResult, error) {
+						require.Equal(t, transfer.ID, arg.TransferID)
+						require.Zero(t, arg.Amount)
+						require.True(t, arg.Force)
+						require.Equal(t, adminUser.Username, arg.Audit.Actor)
+
+						original := transfer
+						original.ReversedAmount = transfer.ToAmount
+						return db.ReverseTransferTxResult{
+							Original: original,
+							Reversal: db.TransferTxResult{
+								Transfer: db.Transfer{
+									ID:                 transfer.ID + 1,
+									FromAccountID:      transfer.ToAccountID,
+									ToAccountID:        transfer.FromAccountID,
+									Amount:             transfer.ToAmount,
+									ToAmount:           transfer.Amount,
+									ReversesTransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+								},
+							},
+						}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, rsp.GetTransfer().GetReversesTransferId())
+				require.Equal(t, transfer.ToAmount, rsp.GetOriginalTransfer().GetReversedAmount())
+			},
+		},
+		{
+			name: "MissingReason",
+			req:  &pb.AdminReverseTransferRequest{TransferId: transfer.ID, Force: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "TransferNotFound",
+			req:  &pb.AdminReverseTransferRequest{TransferId: transfer.ID, Reason: "duplicate payment"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, sql.ErrNoRows)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "AccountClosed",
+			req:  &pb.AdminReverseTransferRequest{TransferId: transfer.ID, Reason: "duplicate payment", Force: true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrAccountClosed)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "NotAdmin",
+			req:  &pb.AdminReverseTransferRequest{TransferId: transfer.ID, Reason: "duplicate payment"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			admin := NewAdminServer(newTestServer(t, store, nil))
+
+			ctx := tc.buildContext(t, admin.server.tokenMaker)
+			rsp, err := admin.ReverseTransfer(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
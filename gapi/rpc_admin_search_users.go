@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (admin *AdminServer) SearchUsers(ctx context.Context, req *pb.SearchUsersRequest) (*pb.SearchUsersResponse, error) {
+	_, err := admin.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateSearchUsersRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	users, err := admin.server.store.SearchUsers(ctx, db.SearchUsersParams{
+		Query:  req.GetQuery(),
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to search users: %s", err)
+	}
+
+	pbUsers := make([]*pb.User, len(users))
+	for i, user := range users {
+		pbUsers[i] = convertUser(user)
+	}
+
+	rsp := &pb.SearchUsersResponse{
+		Users: pbUsers,
+	}
+	return rsp, nil
+}
+
+func validateSearchUsersRequest(req *pb.SearchUsersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if len(req.GetQuery()) < 2 {
+		violations = append(violations, fieldViolation("query", errors.New("must contain at least 2 characters")))
+	}
+
+	if err := val.ValidatePageNumber(req.GetPageId()); err != nil {
+		violations = append(violations, fieldViolation("page_id", err))
+	}
+
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+
+	return violations
+}
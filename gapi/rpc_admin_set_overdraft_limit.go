@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (admin *AdminServer) SetOverdraftLimit(ctx context.Context, req *pb.SetOverdraftLimitRequest) (*pb.SetOverdraftLimitResponse, error) {
+	authPayload, err := admin.authorizeAdmin(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateSetOverdraftLimitRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	result, err := admin.server.store.SetOverdraftLimitTx(ctx, db.SetOverdraftLimitTxParams{
+		UpdateAccountOverdraftLimitParams: db.UpdateAccountOverdraftLimitParams{
+			ID:             req.GetId(),
+			OverdraftLimit: req.GetOverdraftLimit(),
+		},
+		Audit: admin.server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		if errors.Is(err, db.ErrAccountClosed) {
+			return nil, status.Errorf(codes.FailedPrecondition, "account is closed")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to set overdraft limit: %s", err)
+	}
+
+	rsp := &pb.SetOverdraftLimitResponse{
+		Account: convertAccount(result.Account),
+	}
+	return rsp, nil
+}
+
+func validateSetOverdraftLimitRequest(req *pb.SetOverdraftLimitRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	if err := val.ValidateOverdraftLimit(req.GetOverdraftLimit()); err != nil {
+		violations = append(violations, fieldViolation("overdraft_limit", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,161 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSetOverdraftLimitAPI(t *testing.T) {
+	adminUser, _ := randomUser(t)
+	account := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    util.RandomOwner(),
+		Currency: util.USD,
+		Status:   util.AccountStatusActive,
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.SetOverdraftLimitRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.SetOverdraftLimitResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.SetOverdraftLimitRequest{Id: account.ID, OverdraftLimit: 5000},
+			buildStubs: func(store *mockdb.MockStore) {
+				updated := account
+				updated.OverdraftLimit = 5000
+				store.EXPECT().
+					SetOverdraftLimitTx(gomock.Any(), gomock.Eq(db.SetOverdraftLimitTxParams{
+						UpdateAccountOverdraftLimitParams: db.UpdateAccountOverdraftLimitParams{
+							ID:             account.ID,
+							OverdraftLimit: 5000,
+						},
+						Audit: db.AuditContext{Actor: adminUser.Username},
+					})).
+					Times(1).
+					Return(db.SetOverdraftLimitTxResult{Account: updated}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.SetOverdraftLimitResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(5000), rsp.GetAccount().GetOverdraftLimit())
+			},
+		},
+		{
+			name: "NegativeLimit",
+			req:  &pb.SetOverdraftLimitRequest{Id: account.ID, OverdraftLimit: -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetOverdraftLimitTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.SetOverdraftLimitResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "LimitTooLarge",
+			req:  &pb.SetOverdraftLimitRequest{Id: account.ID, OverdraftLimit: 1000000001},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetOverdraftLimitTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.SetOverdraftLimitResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "AccountNotFound",
+			req:  &pb.SetOverdraftLimitRequest{Id: account.ID, OverdraftLimit: 5000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetOverdraftLimitTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SetOverdraftLimitTxResult{}, sql.ErrNoRows)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.SetOverdraftLimitResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "AccountClosed",
+			req:  &pb.SetOverdraftLimitRequest{Id: account.ID, OverdraftLimit: 5000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetOverdraftLimitTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SetOverdraftLimitTxResult{}, db.ErrAccountClosed)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, adminUser.Username, util.AdminRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.SetOverdraftLimitResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "NotAdmin",
+			req:  &pb.SetOverdraftLimitRequest{Id: account.ID, OverdraftLimit: 5000},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetOverdraftLimitTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, account.Owner, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.SetOverdraftLimitResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			admin := NewAdminServer(newTestServer(t, store, nil))
+
+			ctx := tc.buildContext(t, admin.server.tokenMaker)
+			rsp, err := admin.SetOverdraftLimit(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) CaptureHold(ctx context.Context, req *pb.CaptureHoldRequest) (*pb.CaptureHoldResponse, error) {
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateCaptureHoldRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	hold, err := server.getHoldForRecipient(ctx, req.GetId(), authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.CaptureHoldTx(ctx, db.CaptureHoldTxParams{
+		ID:     hold.ID,
+		Amount: req.GetAmount(),
+		Audit:  server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		return nil, holdError(err)
+	}
+
+	rsp := &pb.CaptureHoldResponse{
+		Transfer: convertTransfer(result.Transfer),
+	}
+	return rsp, nil
+}
+
+func validateCaptureHoldRequest(req *pb.CaptureHoldRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	// 0 captures all of the hold
+	if req.GetAmount() != 0 {
+		if err := val.ValidateAmount(req.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,185 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func randomHold(fromAccountID int64, toAccountID int64) db.Transfer {
+	hold := randomTransfer(fromAccountID, toAccountID)
+	hold.Status = db.TransferPending
+	hold.HoldExpiresAt = sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	return hold
+}
+
+func TestCaptureHoldAPI(t *testing.T) {
+	sender, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+	toAccount := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    recipient.Username,
+		Currency: util.USD,
+	}
+	hold := randomHold(util.RandomInt(1001, 2000), toAccount.ID)
+
+	testCases := []struct {
+		name          string
+		req           *pb.CaptureHoldRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.CaptureHoldResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CaptureHoldRequest{Id: hold.ID, Amount: 1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CaptureHoldTxParams) (db.CaptureHoldTxResult, error) {
+						require.Equal(t, hold.ID, arg.ID)
+						require.Equal(t, int64(1), arg.Amount)
+						require.Equal(t, recipient.Username, arg.Audit.Actor)
+
+						captured := hold
+						captured.Amount = arg.Amount
+						captured.ToAmount = arg.Amount
+						captured.Status = db.TransferPosted
+						return db.CaptureHoldTxResult{
+							TransferTxResult: db.TransferTxResult{Transfer: captured},
+						}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CaptureHoldResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, hold.ID, rsp.GetTransfer().GetId())
+				require.Equal(t, int64(1), rsp.GetTransfer().GetAmount())
+				require.Equal(t, pb.TransferStatus_TRANSFER_STATUS_POSTED, rsp.GetTransfer().GetStatus())
+			},
+		},
+		{
+			name: "NotRecipient",
+			req:  &pb.CaptureHoldRequest{Id: hold.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, sender.Username, sender.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CaptureHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NotAHold",
+			req:  &pb.CaptureHoldRequest{Id: hold.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(randomTransfer(hold.FromAccountID, toAccount.ID), nil)
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CaptureHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NotActive",
+			req:  &pb.CaptureHoldRequest{Id: hold.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CaptureHoldTxResult{}, db.ErrHoldNotActive)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CaptureHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "ExceedsHold",
+			req:  &pb.CaptureHoldRequest{Id: hold.ID, Amount: hold.Amount + 1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CaptureHoldTxResult{}, db.ErrCaptureExceedsHold)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CaptureHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "NegativeAmount",
+			req:  &pb.CaptureHoldRequest{Id: hold.ID, Amount: -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CaptureHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			rsp, err := server.CaptureHold(newContextWithMethod(ctx, "/pb.VaultguardAPI/CaptureHold"), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
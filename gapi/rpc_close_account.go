@@ -0,0 +1,98 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/fx"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CloseAccount(ctx context.Context, req *pb.CloseAccountRequest) (*pb.CloseAccountResponse, error) {
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateCloseAccountRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find account: %s", err)
+	}
+
+	if account.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	result, err := server.store.CloseAccountTx(ctx, db.CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: req.GetSweepToAccountId(),
+		QuoteSweep: func(sweep db.TransferTxParams) (db.TransferTxParams, error) {
+			quote, err := fx.NewQuote(ctx, server.rateProvider, sweep.Currency, sweep.ToCurrency, server.config.FXSpreadBps)
+			if err != nil {
+				return sweep, err
+			}
+			sweep.ToAmount = quote.Convert(sweep.Amount)
+			sweep.ExchangeRate = quote.RateString()
+			sweep.SpreadBps = quote.SpreadBps
+			return sweep, nil
+		},
+		Audit: server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "sweep account not found")
+		}
+		if errors.Is(err, db.ErrAccountBalanceNotZero) {
+			return nil, status.Errorf(codes.FailedPrecondition, "account balance must be zero, or swept to another account with sweep_to_account_id")
+		}
+		if errors.Is(err, db.ErrAccountHasHolds) {
+			return nil, status.Errorf(codes.FailedPrecondition, "account has active holds; capture or release them first")
+		}
+		if errors.Is(err, db.ErrSweepAccountNotOwned) {
+			return nil, status.Errorf(codes.PermissionDenied, "sweep account doesn't belong to the authenticated user")
+		}
+		if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		}
+		if errors.Is(err, fx.ErrRateNotFound) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot convert the balance into the sweep account currency: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to close account: %s", err)
+	}
+
+	rsp := &pb.CloseAccountResponse{
+		Account: convertAccount(result.Account),
+	}
+	if result.Sweep != nil {
+		rsp.SweepTransfer = convertTransfer(result.Sweep.Transfer)
+	}
+	return rsp, nil
+}
+
+func validateCloseAccountRequest(req *pb.CloseAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	if req.GetSweepToAccountId() < 0 {
+		violations = append(violations, fieldViolation("sweep_to_account_id", errors.New("must not be negative")))
+	} else if req.GetSweepToAccountId() == req.GetId() {
+		violations = append(violations, fieldViolation("sweep_to_account_id", errors.New("must be a different account")))
+	}
+
+	return violations
+}
@@ -0,0 +1,56 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ConfirmEmailChange(ctx context.Context, req *pb.ConfirmEmailChangeRequest) (*pb.ConfirmEmailChangeResponse, error) {
+	violations := validateConfirmEmailChangeRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	txResult, err := server.store.ConfirmEmailChangeTx(ctx, db.ConfirmEmailChangeTxParams{
+		ChangeID:   req.GetChangeId(),
+		SecretHash: util.HashSecretCode(req.GetSecretCode()),
+		Audit:      server.auditContext(ctx, ""),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, status.Errorf(codes.FailedPrecondition, "email change link is invalid, used or expired")
+		case errors.Is(err, db.ErrEmailChangeSuperseded):
+			return nil, status.Errorf(codes.FailedPrecondition, "%s", err)
+		case errors.Is(err, db.ErrEmailTaken):
+			return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to confirm email change: %s", err)
+	}
+
+	rsp := &pb.ConfirmEmailChangeResponse{
+		Email: txResult.User.Email,
+	}
+	return rsp, nil
+}
+
+func validateConfirmEmailChangeRequest(req *pb.ConfirmEmailChangeRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetChangeId()); err != nil {
+		violations = append(violations, fieldViolation("change_id", err))
+	}
+
+	if err := val.ValidateSecretCode(req.GetSecretCode()); err != nil {
+		violations = append(violations, fieldViolation("secret_code", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,123 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConfirmEmailChangeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	newEmail := "new_" + user.Email
+
+	secretCode, err := util.NewSecretCode()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		req           *pb.ConfirmEmailChangeRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ConfirmEmailChangeResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.ConfirmEmailChangeRequest{ChangeId: 1, SecretCode: secretCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ConfirmEmailChangeTxParams) (db.ConfirmEmailChangeTxResult, error) {
+						require.Equal(t, int64(1), arg.ChangeID)
+						require.Equal(t, util.HashSecretCode(secretCode), arg.SecretHash)
+						updated := user
+						updated.Email = newEmail
+						return db.ConfirmEmailChangeTxResult{User: updated}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmEmailChangeResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, newEmail, rsp.GetEmail())
+			},
+		},
+		{
+			name: "InvalidOrUsedLink",
+			req:  &pb.ConfirmEmailChangeRequest{ChangeId: 1, SecretCode: secretCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConfirmEmailChangeTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmEmailChangeResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "Superseded",
+			req:  &pb.ConfirmEmailChangeRequest{ChangeId: 1, SecretCode: secretCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConfirmEmailChangeTxResult{}, db.ErrEmailChangeSuperseded)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmEmailChangeResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "EmailTaken",
+			req:  &pb.ConfirmEmailChangeRequest{ChangeId: 1, SecretCode: secretCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConfirmEmailChangeTxResult{}, db.ErrEmailTaken)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmEmailChangeResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidChangeID",
+			req:  &pb.ConfirmEmailChangeRequest{ChangeId: 0, SecretCode: secretCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ConfirmEmailChangeTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmEmailChangeResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			rsp, err := server.ConfirmEmailChange(context.Background(), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
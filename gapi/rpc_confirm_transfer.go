@@ -0,0 +1,227 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/fx"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/OmSingh2003/nimbus/val"
+	"github.com/OmSingh2003/nimbus/worker"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errIncorrectStepUp = status.Error(codes.Unauthenticated, "incorrect password or two-factor code")
+
+// transferConfirmationThreshold is the amount in USD cents above which the user's
+// transfers must be confirmed again
+func (server *Server) transferConfirmationThreshold(user db.User) int64 {
+	if user.TransferConfirmationThreshold.Valid {
+		return user.TransferConfirmationThreshold.Int64
+	}
+	return server.config.TransferConfirmationThreshold
+}
+
+// transferConfirmationAmount converts a transfer's amount into USD cents at the mid
+// rate, so it can be compared with the confirmation threshold whatever its currency
+func (server *Server) transferConfirmationAmount(ctx context.Context, currency string, amount int64) (int64, error) {
+	quote, err := fx.NewQuote(ctx, server.rateProvider, currency, util.USD, 0)
+	if err != nil {
+		return 0, err
+	}
+	return quote.Convert(amount), nil
+}
+
+// holdTransfer stores a transfer until the user confirms it and schedules it to
+// expire if they don't. A retry with the same idempotency key gets back the transfer
+// already held for it rather than a new one to confirm.
+func (server *Server) holdTransfer(ctx context.Context, username string, arg db.TransferTxParams) (*pb.CreateTransferResponse, error) {
+	pending, err := server.store.CreatePendingTransfer(ctx, db.CreatePendingTransferParams{
+		Username:       username,
+		FromAccountID:  arg.FromAccountID,
+		ToAccountID:    arg.ToAccountID,
+		Amount:         arg.Amount,
+		Currency:       arg.Currency,
+		ToAmount:       arg.ToAmount,
+		ToCurrency:     arg.ToCurrency,
+		ExchangeRate:   arg.ExchangeRate,
+		SpreadBps:      arg.SpreadBps,
+		IdempotencyKey: arg.IdempotencyKey,
+		ExpiresAt:      time.Now().Add(server.config.PendingTransferDuration),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) && arg.IdempotencyKey != "" {
+			return server.heldTransfer(ctx, username, arg)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create pending transfer: %s", err)
+	}
+
+	// ConfirmTransfer checks the expiry itself, so a lost task only leaves the status stale
+	taskPayload := &worker.PayloadExpirePendingTransfer{
+		PendingTransferID: pending.ID,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.ProcessAt(pending.ExpiresAt),
+		asynq.Queue(worker.QueueDefault),
+	}
+	err = server.taskDistributor.DistributeTaskExpirePendingTransfer(ctx, taskPayload, opts...)
+	if err != nil {
+		log.Error().Err(err).Int64("pending_transfer_id", pending.ID).Msg("failed to distribute expire pending transfer task")
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		PendingTransfer: convertPendingTransfer(pending),
+	}
+	return rsp, nil
+}
+
+// heldTransfer returns the pending transfer the user already holds under the
+// idempotency key, provided it is for the same transfer
+func (server *Server) heldTransfer(ctx context.Context, username string, arg db.TransferTxParams) (*pb.CreateTransferResponse, error) {
+	pending, err := server.store.GetPendingTransferByIdempotencyKey(ctx, db.GetPendingTransferByIdempotencyKeyParams{
+		Username:       username,
+		IdempotencyKey: arg.IdempotencyKey,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get pending transfer: %s", err)
+	}
+
+	if pending.FromAccountID != arg.FromAccountID ||
+		pending.ToAccountID != arg.ToAccountID ||
+		pending.Amount != arg.Amount ||
+		pending.Currency != arg.Currency ||
+		pending.ToCurrency != arg.ToCurrency {
+		return nil, transferError(db.ErrIdempotencyKeyConflict)
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		PendingTransfer: convertPendingTransfer(pending),
+	}
+	return rsp, nil
+}
+
+func (server *Server) ConfirmTransfer(ctx context.Context, req *pb.ConfirmTransferRequest) (*pb.ConfirmTransferResponse, error) {
+	violations := validateConfirmTransferRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	pending, err := server.store.GetPendingTransfer(ctx, req.GetId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "pending transfer not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get pending transfer: %s", err)
+	}
+	// Don't reveal other users' pending transfers
+	if pending.Username != authPayload.Username {
+		return nil, status.Errorf(codes.NotFound, "pending transfer not found")
+	}
+	if pending.Status != db.PendingTransferPending || !time.Now().Before(pending.ExpiresAt) {
+		return nil, status.Errorf(codes.FailedPrecondition, "pending transfer has already been confirmed or has expired")
+	}
+
+	// Wrong passwords and codes count as failed logins, so they can't be guessed here either
+	reservation, err := server.reserveLoginAttempt(ctx, server.loginKeys(ctx, authPayload.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	ok, err := server.checkStepUp(ctx, user, req)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		_, err = server.store.RecordAuditEventTx(ctx, db.AuditEventParams{
+			Username:  user.Username,
+			EventType: db.AuditStepUpFailed,
+			Details:   map[string]any{"pending_transfer_id": pending.ID},
+			Audit:     server.auditContext(ctx, user.Username),
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to record failed confirmation: %s", err)
+		}
+		server.loginAttemptFailed(ctx, reservation, &user)
+		return nil, errIncorrectStepUp
+	}
+
+	if err := server.releaseLoginAttempt(ctx, reservation); err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.ConfirmPendingTransferTx(ctx, db.ConfirmPendingTransferTxParams{
+		ID:    pending.ID,
+		Audit: server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrPendingTransferNotPending) {
+			return nil, status.Errorf(codes.FailedPrecondition, "pending transfer has already been confirmed or has expired")
+		}
+		return nil, transferError(err)
+	}
+
+	rsp := &pb.ConfirmTransferResponse{
+		Transfer: convertTransfer(result.Transfer),
+	}
+	return rsp, nil
+}
+
+// checkStepUp checks the password or TOTP code the user re-authenticated with.
+// A TOTP code is used up so it can't be replayed.
+func (server *Server) checkStepUp(ctx context.Context, user db.User, req *pb.ConfirmTransferRequest) (bool, error) {
+	if req.GetPassword() != "" {
+		return util.CheckPassword(req.GetPassword(), user.HashedPassword) == nil, nil
+	}
+
+	mfa, err := server.store.GetUserMFA(ctx, user.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+		}
+		return false, status.Errorf(codes.Internal, "failed to get two-factor settings: %s", err)
+	}
+	if !mfa.EnabledAt.Valid {
+		return false, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+
+	_, ok, err := server.useMFACode(ctx, mfa, req.GetTotpCode())
+	return ok, err
+}
+
+func validateConfirmTransferRequest(req *pb.ConfirmTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	switch {
+	case req.GetPassword() == "" && req.GetTotpCode() == "":
+		violations = append(violations, fieldViolation("password", errors.New("password or totp_code is required")))
+	case req.GetPassword() != "" && req.GetTotpCode() != "":
+		violations = append(violations, fieldViolation("totp_code", errors.New("must not be set together with password")))
+	case req.GetTotpCode() != "":
+		if err := val.ValidateTOTPCode(req.GetTotpCode()); err != nil {
+			violations = append(violations, fieldViolation("totp_code", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,533 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/fx"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/OmSingh2003/nimbus/worker"
+	mockwk "github.com/OmSingh2003/nimbus/worker/mock"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateTransferConfirmationThreshold(t *testing.T) {
+	user, _ := randomUser(t)
+	fromAccount := db.Account{ID: 1, Owner: user.Username, Balance: 1000000, Currency: util.USD}
+	toAccount := db.Account{ID: 2, Owner: util.RandomOwner(), Currency: util.USD}
+	idempotencyKey := util.RandomString(16)
+	held := db.PendingTransfer{
+		ID:             10,
+		Username:       user.Username,
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         200000,
+		Currency:       util.USD,
+		ToAmount:       200000,
+		ToCurrency:     util.USD,
+		IdempotencyKey: idempotencyKey,
+		Status:         db.PendingTransferPending,
+		ExpiresAt:      time.Now().Add(5 * time.Minute),
+	}
+
+	testCases := []struct {
+		name           string
+		amount         int64
+		currency       string
+		idempotencyKey string
+		user           func() db.User
+		buildStubs     func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor, amount int64)
+		checkResponse  func(t *testing.T, rsp *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name:     "BelowThreshold",
+			amount:   100000,
+			currency: util.USD,
+			user:     func() db.User { return user },
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor, amount int64) {
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: randomTransfer(fromAccount.ID, toAccount.ID)}, nil)
+				store.EXPECT().
+					CreatePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, rsp.GetTransfer())
+				require.Nil(t, rsp.GetPendingTransfer())
+			},
+		},
+		{
+			name:     "AboveThreshold",
+			amount:   100001,
+			currency: util.USD,
+			user:     func() db.User { return user },
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor, amount int64) {
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreatePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreatePendingTransferParams) (db.PendingTransfer, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, amount, arg.Amount)
+						require.Equal(t, amount, arg.ToAmount)
+						require.WithinDuration(t, time.Now().Add(10*time.Minute), arg.ExpiresAt, time.Second)
+						return db.PendingTransfer{
+							ID:            7,
+							Username:      arg.Username,
+							FromAccountID: arg.FromAccountID,
+							ToAccountID:   arg.ToAccountID,
+							Amount:        arg.Amount,
+							Currency:      arg.Currency,
+							Status:        db.PendingTransferPending,
+							ExpiresAt:     arg.ExpiresAt,
+						}, nil
+					})
+				taskDistributor.EXPECT().
+					DistributeTaskExpirePendingTransfer(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, payload *worker.PayloadExpirePendingTransfer, opts ...asynq.Option) error {
+						require.Equal(t, int64(7), payload.PendingTransferID)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Nil(t, rsp.GetTransfer())
+				require.Equal(t, int64(7), rsp.GetPendingTransfer().GetId())
+				require.Equal(t, db.PendingTransferPending, rsp.GetPendingTransfer().GetStatus())
+			},
+		},
+		{
+			name:     "UserThreshold",
+			amount:   600,
+			currency: util.USD,
+			user: func() db.User {
+				stricter := user
+				stricter.TransferConfirmationThreshold = sql.NullInt64{Int64: 500, Valid: true}
+				return stricter
+			},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor, amount int64) {
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreatePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PendingTransfer{ID: 8, Status: db.PendingTransferPending}, nil)
+				taskDistributor.EXPECT().
+					DistributeTaskExpirePendingTransfer(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(8), rsp.GetPendingTransfer().GetId())
+			},
+		},
+		{
+			// 50,000.00 INR is 600.00 USD, under the threshold
+			name:     "ConvertedBelowThreshold",
+			amount:   5000000,
+			currency: util.INR,
+			user:     func() db.User { return user },
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor, amount int64) {
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferTxResult{Transfer: randomTransfer(fromAccount.ID, toAccount.ID)}, nil)
+				store.EXPECT().
+					CreatePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, rsp.GetTransfer())
+			},
+		},
+		{
+			// 100,000.00 INR is 1,200.00 USD, over the threshold
+			name:     "ConvertedAboveThreshold",
+			amount:   10000000,
+			currency: util.INR,
+			user:     func() db.User { return user },
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor, amount int64) {
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreatePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PendingTransfer{ID: 9, Status: db.PendingTransferPending}, nil)
+				taskDistributor.EXPECT().
+					DistributeTaskExpirePendingTransfer(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(9), rsp.GetPendingTransfer().GetId())
+			},
+		},
+		{
+			name:           "RetriedWithIdempotencyKey",
+			amount:         held.Amount,
+			currency:       util.USD,
+			idempotencyKey: idempotencyKey,
+			user:           func() db.User { return user },
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor, amount int64) {
+				store.EXPECT().
+					CreatePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PendingTransfer{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetPendingTransferByIdempotencyKey(gomock.Any(), gomock.Eq(db.GetPendingTransferByIdempotencyKeyParams{
+						Username:       user.Username,
+						IdempotencyKey: idempotencyKey,
+					})).
+					Times(1).
+					Return(held, nil)
+				taskDistributor.EXPECT().
+					DistributeTaskExpirePendingTransfer(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, held.ID, rsp.GetPendingTransfer().GetId())
+			},
+		},
+		{
+			name:           "IdempotencyKeyUsedForDifferentTransfer",
+			amount:         held.Amount + 1,
+			currency:       util.USD,
+			idempotencyKey: idempotencyKey,
+			user:           func() db.User { return user },
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor, amount int64) {
+				store.EXPECT().
+					CreatePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PendingTransfer{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetPendingTransferByIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(held, nil)
+				taskDistributor.EXPECT().
+					DistributeTaskExpirePendingTransfer(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			taskCtrl := gomock.NewController(t)
+			defer taskCtrl.Finish()
+			taskDistributor := mockwk.NewMockTaskDistributor(taskCtrl)
+
+			from, to := fromAccount, toAccount
+			from.Currency, to.Currency = tc.currency, tc.currency
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				AnyTimes().
+				Return(from, nil)
+			store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				AnyTimes().
+				Return(to, nil)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(tc.user(), nil)
+			tc.buildStubs(store, taskDistributor, tc.amount)
+
+			server := newTestServer(t, store, taskDistributor)
+			rateProvider, err := fx.NewStaticProvider(map[string]string{"INR/USD": "0.012"})
+			require.NoError(t, err)
+			server.rateProvider = rateProvider
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role, time.Minute)
+
+			req := &pb.CreateTransferRequest{
+				FromAccountId:  fromAccount.ID,
+				ToAccountId:    toAccount.ID,
+				Amount:         tc.amount,
+				Currency:       tc.currency,
+				IdempotencyKey: tc.idempotencyKey,
+			}
+			rsp, err := server.CreateTransfer(newContextWithMethod(ctx, "/pb.VaultguardAPI/CreateTransfer"), req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestConfirmTransferAPI(t *testing.T) {
+	user, password := randomUser(t)
+
+	pending := db.PendingTransfer{
+		ID:            util.RandomInt(1, 1000),
+		Username:      user.Username,
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        200000,
+		Currency:      util.USD,
+		ToAmount:      200000,
+		ToCurrency:    util.USD,
+		ExchangeRate:  "1",
+		Status:        db.PendingTransferPending,
+		ExpiresAt:     time.Now().Add(5 * time.Minute),
+	}
+	transfer := randomTransfer(pending.FromAccountID, pending.ToAccountID)
+
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+	code, err := util.TOTPCode(secret, util.TOTPStep(time.Now()))
+	require.NoError(t, err)
+
+	usernameKey := db.LoginFailureKey{Scope: loginScopeUsername, Key: user.Username}
+
+	testCases := []struct {
+		name          string
+		req           *pb.ConfirmTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ConfirmTransferResponse, err error)
+	}{
+		{
+			name: "OKWithPassword",
+			req:  &pb.ConfirmTransferRequest{Id: pending.ID, Password: password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reservedLoginAttempt(1, usernameKey), nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					ConfirmPendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ConfirmPendingTransferTxParams) (db.ConfirmPendingTransferTxResult, error) {
+						require.Equal(t, pending.ID, arg.ID)
+						require.Equal(t, user.Username, arg.Audit.Actor)
+						return db.ConfirmPendingTransferTxResult{
+							TransferTxResult: db.TransferTxResult{Transfer: transfer},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, rsp.GetTransfer().GetId())
+			},
+		},
+		{
+			name: "OKWithTOTPCode",
+			req:  &pb.ConfirmTransferRequest{Id: pending.ID, TotpCode: code},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reservedLoginAttempt(1, usernameKey), nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserMfa{
+						Username:   user.Username,
+						TotpSecret: secret,
+						EnabledAt:  sql.NullTime{Time: time.Now(), Valid: true},
+					}, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserMfa{}, nil)
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					ConfirmPendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConfirmPendingTransferTxResult{
+						TransferTxResult: db.TransferTxResult{Transfer: transfer},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, rsp.GetTransfer().GetId())
+			},
+		},
+		{
+			name: "IncorrectPassword",
+			req:  &pb.ConfirmTransferRequest{Id: pending.ID, Password: "wrong" + password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reservedLoginAttempt(1, usernameKey), nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RecordAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.AuditEventParams) (db.AuditEvent, error) {
+						require.Equal(t, db.AuditStepUpFailed, arg.EventType)
+						return db.AuditEvent{}, nil
+					})
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ConfirmPendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "OtherUsersTransfer",
+			req:  &pb.ConfirmTransferRequest{Id: pending.ID, Password: password},
+			buildStubs: func(store *mockdb.MockStore) {
+				other := pending
+				other.Username = util.RandomOwner()
+				store.EXPECT().
+					GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).
+					Times(1).
+					Return(other, nil)
+				store.EXPECT().
+					ConfirmPendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "Expired",
+			req:  &pb.ConfirmTransferRequest{Id: pending.ID, Password: password},
+			buildStubs: func(store *mockdb.MockStore) {
+				expired := pending
+				expired.ExpiresAt = time.Now().Add(-time.Second)
+				store.EXPECT().
+					GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).
+					Times(1).
+					Return(expired, nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					ConfirmPendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req:  &pb.ConfirmTransferRequest{Id: pending.ID, Password: password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reservedLoginAttempt(1, usernameKey), nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					ConfirmPendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ConfirmPendingTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "NoPasswordOrCode",
+			req:  &pb.ConfirmTransferRequest{Id: pending.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetPendingTransfer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role, time.Minute)
+			rsp, err := server.ConfirmTransfer(newContextWithMethod(ctx, "/pb.VaultguardAPI/ConfirmTransfer"), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 
 	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/fx"
 	"github.com/OmSingh2003/nimbus/pb"
 	"github.com/OmSingh2003/nimbus/util"
 	"github.com/OmSingh2003/nimbus/val"
@@ -16,7 +17,7 @@ import (
 func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
 	authPayload, err := server.getAuthPayload(ctx)
 	if err != nil {
-		return nil, err
+		return nil, authError(err)
 	}
 
 	violations := validateCreateAccountRequest(req)
@@ -24,20 +25,25 @@ func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRe
 		return nil, InvalidArgumentError(violations)
 	}
 
-	arg := db.CreateAccountParams{
+	// Welcome credits are a fixed USD amount converted into the account currency
+	quote, err := fx.NewQuote(ctx, server.rateProvider, util.USD, req.GetCurrency(), 0)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get exchange rate: %s", err)
+	}
+
+	txResult, err := server.store.CreateAccountTx(ctx, db.CreateAccountTxParams{
 		Owner:         authPayload.Username,
 		Currency:      req.GetCurrency(),
-		Balance:       util.GetWelcomeCreditAmount(req.GetCurrency()), // Currency-specific welcome credits
 		AccountNumber: sql.NullString{String: util.RandomAccountNumber(), Valid: true},
-	}
-
-	account, err := server.store.CreateAccount(ctx, arg)
+		WelcomeCredit: quote.Convert(util.WelcomeCreditAmount),
+		Audit:         server.auditContext(ctx, authPayload.Username),
+	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
 	}
 
 	rsp := &pb.CreateAccountResponse{
-		Account: convertAccount(account),
+		Account: convertAccount(txResult.Account),
 	}
 	return rsp, nil
 }
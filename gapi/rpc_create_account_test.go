@@ -0,0 +1,177 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateAccountAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateAccountRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateAccountTxParams) (db.CreateAccountTxResult, error) {
+						require.Equal(t, user.Username, arg.Owner)
+						require.Equal(t, util.USD, arg.Currency)
+						require.True(t, arg.AccountNumber.Valid)
+						require.Equal(t, int64(util.WelcomeCreditAmount), arg.WelcomeCredit)
+						return db.CreateAccountTxResult{
+							Account: db.Account{
+								ID:            1,
+								Owner:         arg.Owner,
+								Balance:       arg.WelcomeCredit,
+								Currency:      arg.Currency,
+								AccountNumber: arg.AccountNumber,
+								Status:        util.AccountStatusActive,
+							},
+						}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, rsp.GetAccount().GetOwner())
+				require.Equal(t, int64(util.WelcomeCreditAmount), rsp.GetAccount().GetBalance())
+			},
+		},
+		{
+			name: "WelcomeCreditConverted",
+			req:  &pb.CreateAccountRequest{Currency: util.EUR},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFxRate(gomock.Any(), gomock.Eq(db.GetFxRateParams{BaseCurrency: util.USD, QuoteCurrency: util.EUR})).
+					Times(1).
+					Return(db.FxRate{BaseCurrency: util.USD, QuoteCurrency: util.EUR, Rate: "0.9500000000"}, nil)
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateAccountTxParams) (db.CreateAccountTxResult, error) {
+						require.Equal(t, util.EUR, arg.Currency)
+						require.Equal(t, int64(9500), arg.WelcomeCredit)
+						return db.CreateAccountTxResult{
+							Account: db.Account{ID: 1, Owner: arg.Owner, Balance: arg.WelcomeCredit, Currency: arg.Currency},
+						}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(9500), rsp.GetAccount().GetBalance())
+			},
+		},
+		{
+			name: "NoTreasuryAccount",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateAccountTxResult{}, db.ErrNoTreasuryAccount)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			req:  &pb.CreateAccountRequest{Currency: "usd"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "RateUnavailable",
+			req:  &pb.CreateAccountRequest{Currency: util.INR},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetFxRate(gomock.Any(), gomock.Any()).
+					AnyTimes().
+					Return(db.FxRate{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "Unauthenticated",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAccountTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateAccountResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			rsp, err := server.CreateAccount(newContextWithMethod(ctx, "/pb.VaultguardAPI/CreateAccount"), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/fx"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateHold(ctx context.Context, req *pb.CreateHoldRequest) (*pb.CreateHoldResponse, error) {
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateCreateHoldRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	fromAccount, err := server.store.GetAccount(ctx, req.GetFromAccountId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "from account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get from account: %s", err)
+	}
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "from account currency mismatch: %s vs %s", fromAccount.Currency, req.GetCurrency())
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "to account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get to account: %s", err)
+	}
+
+	// The rate is fixed when the hold is placed, so the recipient knows what a capture credits
+	quote, err := fx.NewQuote(ctx, server.rateProvider, req.GetCurrency(), toAccount.Currency, server.config.FXSpreadBps)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot convert %s to %s: %s", req.GetCurrency(), toAccount.Currency, err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get exchange rate: %s", err)
+	}
+
+	// The recipient can capture a hold straight away, so a hold can't get round the
+	// confirmation that a transfer of the same amount would need
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+	confirmationAmount, err := server.transferConfirmationAmount(ctx, req.GetCurrency(), req.GetAmount())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get exchange rate: %s", err)
+	}
+	if confirmationAmount > server.transferConfirmationThreshold(user) {
+		return nil, status.Errorf(codes.FailedPrecondition, "amount is above the transfer confirmation threshold; make a transfer and confirm it instead")
+	}
+
+	result, err := server.store.CreateHoldTx(ctx, db.CreateHoldTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.GetAmount(),
+		Currency:      req.GetCurrency(),
+		ToCurrency:    quote.To,
+		ToAmount:      quote.Convert(req.GetAmount()),
+		ExchangeRate:  quote.RateString(),
+		SpreadBps:     quote.SpreadBps,
+		ExpiresAt:     time.Now().Add(server.config.HoldDuration),
+		Audit:         server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		return nil, transferError(err)
+	}
+
+	rsp := &pb.CreateHoldResponse{
+		Hold: convertTransfer(result.Hold),
+	}
+	return rsp, nil
+}
+
+// holdError maps the errors of CaptureHoldTx and ReleaseHoldTx to gRPC errors
+func holdError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "hold not found")
+	}
+	if errors.Is(err, db.ErrHoldNotActive) || errors.Is(err, db.ErrCaptureExceedsHold) {
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	}
+	if errors.Is(err, db.ErrCaptureTooSmall) {
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+	if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) {
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	}
+	return status.Errorf(codes.Internal, "failed to settle hold: %s", err)
+}
+
+// getHoldForRecipient returns the hold with id if the user owns the account it is for;
+// only the recipient decides whether a hold is captured or released
+func (server *Server) getHoldForRecipient(ctx context.Context, id int64, username string) (db.Transfer, error) {
+	hold, err := server.store.GetTransfer(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return hold, status.Errorf(codes.NotFound, "hold not found")
+		}
+		return hold, status.Errorf(codes.Internal, "failed to get hold: %s", err)
+	}
+	if !hold.HoldExpiresAt.Valid {
+		return hold, status.Errorf(codes.NotFound, "hold not found")
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, hold.ToAccountID)
+	if err != nil {
+		return hold, status.Errorf(codes.Internal, "failed to get to account: %s", err)
+	}
+	if toAccount.Owner != username {
+		return hold, status.Errorf(codes.PermissionDenied, "only the recipient can settle a hold")
+	}
+	return hold, nil
+}
+
+func validateCreateHoldRequest(req *pb.CreateHoldRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if err := val.ValidateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+
+	if err := val.ValidateAmount(req.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	return violations
+}
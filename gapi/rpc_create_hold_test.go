@@ -0,0 +1,256 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateHoldAPI(t *testing.T) {
+	sender, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+	fromAccount := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    sender.Username,
+		Currency: util.USD,
+	}
+	toAccount := db.Account{
+		ID:       util.RandomInt(1001, 2000),
+		Owner:    recipient.Username,
+		Currency: util.USD,
+	}
+	amount := int64(100)
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateHoldRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.CreateHoldResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateHoldRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(sender.Username)).Times(1).Return(sender, nil)
+				store.EXPECT().
+					CreateHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateHoldTxParams) (db.CreateHoldTxResult, error) {
+						require.Equal(t, fromAccount.ID, arg.FromAccountID)
+						require.Equal(t, toAccount.ID, arg.ToAccountID)
+						require.Equal(t, amount, arg.Amount)
+						require.Equal(t, amount, arg.ToAmount)
+						require.Equal(t, util.USD, arg.ToCurrency)
+						require.WithinDuration(t, time.Now().Add(24*time.Hour), arg.ExpiresAt, time.Minute)
+						require.Equal(t, sender.Username, arg.Audit.Actor)
+
+						return db.CreateHoldTxResult{
+							Hold: db.Transfer{
+								ID:            util.RandomInt(1, 1000),
+								FromAccountID: arg.FromAccountID,
+								ToAccountID:   arg.ToAccountID,
+								Amount:        arg.Amount,
+								Currency:      arg.Currency,
+								ToAmount:      arg.ToAmount,
+								ToCurrency:    arg.ToCurrency,
+								ExchangeRate:  arg.ExchangeRate,
+								Status:        db.TransferPending,
+								HoldExpiresAt: sql.NullTime{Time: arg.ExpiresAt, Valid: true},
+							},
+						}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, sender.Username, sender.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateHoldResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, pb.TransferStatus_TRANSFER_STATUS_PENDING, rsp.GetHold().GetStatus())
+				require.Equal(t, amount, rsp.GetHold().GetAmount())
+				require.NotNil(t, rsp.GetHold().GetHoldExpiresAt())
+			},
+		},
+		{
+			// Without confirmation no hold is placed, so there is nothing for the recipient to capture
+			name: "AboveConfirmationThreshold",
+			req: &pb.CreateHoldRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        100001,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(sender.Username)).Times(1).Return(sender, nil)
+				store.EXPECT().
+					CreateHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CaptureHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, sender.Username, sender.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "AboveUserConfirmationThreshold",
+			req: &pb.CreateHoldRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				stricter := sender
+				stricter.TransferConfirmationThreshold = sql.NullInt64{Int64: amount - 1, Valid: true}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(sender.Username)).Times(1).Return(stricter, nil)
+				store.EXPECT().
+					CreateHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, sender.Username, sender.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "NotOwner",
+			req: &pb.CreateHoldRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().
+					CreateHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "ToAccountNotFound",
+			req: &pb.CreateHoldRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+					CreateHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, sender.Username, sender.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req: &pb.CreateHoldRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(sender.Username)).Times(1).Return(sender, nil)
+				store.EXPECT().
+					CreateHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CreateHoldTxResult{}, db.ErrInsufficientFunds)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, sender.Username, sender.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidAmount",
+			req: &pb.CreateHoldRequest{
+				FromAccountId: fromAccount.ID,
+				ToAccountId:   toAccount.ID,
+				Amount:        -1,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					CreateHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, sender.Username, sender.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			rsp, err := server.CreateHold(newContextWithMethod(ctx, "/pb.VaultguardAPI/CreateHold"), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
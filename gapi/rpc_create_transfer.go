@@ -3,22 +3,25 @@ package gapi
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/fx"
 	"github.com/OmSingh2003/nimbus/pb"
 	"github.com/OmSingh2003/nimbus/val"
 	"github.com/OmSingh2003/nimbus/worker"
 	"github.com/hibiken/asynq"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
 	authPayload, err := server.getAuthPayload(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %s", err)
+		return nil, authError(err)
 	}
 
 	err = validateCreateTransferRequest(req)
@@ -70,23 +73,49 @@ func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransfer
 		return nil, status.Errorf(codes.InvalidArgument, "from account currency mismatch: %s vs %s", fromAccount.Currency, req.GetCurrency())
 	}
 
-	if toAccount.Currency != req.GetCurrency() {
-		return nil, status.Errorf(codes.InvalidArgument, "to account currency mismatch: %s vs %s", toAccount.Currency, req.GetCurrency())
+	// Convert into the to account's currency when it differs
+	quote, err := fx.NewQuote(ctx, server.rateProvider, req.GetCurrency(), toAccount.Currency, server.config.FXSpreadBps)
+	if err != nil {
+		if errors.Is(err, fx.ErrRateNotFound) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot convert %s to %s: %s", req.GetCurrency(), toAccount.Currency, err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get exchange rate: %s", err)
 	}
 
 	arg := db.TransferTxParams{
-		FromAccountID: req.GetFromAccountId(),
-		ToAccountID:   toAccount.ID, // Use the actual account ID from the lookup
-		Amount:        req.GetAmount(),
+		FromAccountID:  req.GetFromAccountId(),
+		ToAccountID:    toAccount.ID, // Use the actual account ID from the lookup
+		Amount:         req.GetAmount(),
+		Currency:       req.GetCurrency(),
+		ToCurrency:     quote.To,
+		ToAmount:       quote.Convert(req.GetAmount()),
+		ExchangeRate:   quote.RateString(),
+		SpreadBps:      quote.SpreadBps,
+		IdempotencyKey: req.GetIdempotencyKey(),
+		Audit:          server.auditContext(ctx, authPayload.Username),
+	}
+
+	// Large transfers are held until the user confirms them by re-authenticating
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+	confirmationAmount, err := server.transferConfirmationAmount(ctx, req.GetCurrency(), req.GetAmount())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get exchange rate: %s", err)
+	}
+	if confirmationAmount > server.transferConfirmationThreshold(user) {
+		return server.holdTransfer(ctx, user.Username, arg)
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create transfer: %s", err)
+		return nil, transferError(err)
 	}
 
-	// Check if this is a transfer to the demo account
-	if toAccount.ID == getDemoAccountID() || isAccountNumber(toAccount, "DEMO-1234567890") {
+	// Check if this is a transfer to the demo account; it only sends back in its own currency
+	isDemoAccount := toAccount.ID == getDemoAccountID() || isAccountNumber(toAccount, "DEMO-1234567890")
+	if isDemoAccount && toAccount.Currency == req.GetCurrency() {
 		// Trigger demo response task
 		taskPayload := &worker.PayloadDemoResponse{
 			FromAccountID: req.GetFromAccountId(),
@@ -108,6 +137,23 @@ func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransfer
 	return rsp, nil
 }
 
+// transferError maps the errors of TransferTx to gRPC errors
+func transferError(err error) error {
+	if errors.Is(err, db.ErrInsufficientFunds) {
+		return status.Errorf(codes.FailedPrecondition, "from account has insufficient funds")
+	}
+	if errors.Is(err, db.ErrCurrencyMismatch) {
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+	if errors.Is(err, db.ErrIdempotencyKeyConflict) {
+		return status.Errorf(codes.AlreadyExists, "idempotency key was already used for a different transfer")
+	}
+	if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) {
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	}
+	return status.Errorf(codes.Internal, "failed to create transfer: %s", err)
+}
+
 func validateCreateTransferRequest(req *pb.CreateTransferRequest) error {
 	if req.GetFromAccountId() <= 0 {
 		return status.Errorf(codes.InvalidArgument, "from_account_id must be greater than 0")
@@ -125,6 +171,12 @@ func validateCreateTransferRequest(req *pb.CreateTransferRequest) error {
 		return status.Errorf(codes.InvalidArgument, "invalid currency: %s", err.Error())
 	}
 
+	if req.GetIdempotencyKey() != "" {
+		if err := val.ValidateIdempotencyKey(req.GetIdempotencyKey()); err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid idempotency_key: %s", err.Error())
+		}
+	}
+
 	return nil
 }
 
@@ -141,13 +193,42 @@ func isAccountNumber(account db.Account, accountNumber string) bool {
 	return account.Owner == "demo_user" // Simplified check for demo account
 }
 
-func convertTransfer(transfer db.Transfer) *pb.Transfer {
-	return &pb.Transfer{
-		Id:            transfer.ID,
-		FromAccountId: transfer.FromAccountID,
-		ToAccountId:   transfer.ToAccountID,
-		Amount:        transfer.Amount,
-		Currency:      "", // Note: Transfer table doesn't store currency directly
-		CreatedAt:     transfer.CreatedAt.String(),
+// transferStatuses maps the statuses of a transfer in the database to the API's
+var transferStatuses = map[string]pb.TransferStatus{
+	db.TransferPending:  pb.TransferStatus_TRANSFER_STATUS_PENDING,
+	db.TransferPosted:   pb.TransferStatus_TRANSFER_STATUS_POSTED,
+	db.TransferFailed:   pb.TransferStatus_TRANSFER_STATUS_FAILED,
+	db.TransferReversed: pb.TransferStatus_TRANSFER_STATUS_REVERSED,
+}
+
+// dbTransferStatus is the database status for an API one, or "" if there is none
+func dbTransferStatus(status pb.TransferStatus) string {
+	for dbStatus, pbStatus := range transferStatuses {
+		if pbStatus == status {
+			return dbStatus
+		}
 	}
+	return ""
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	pbTransfer := &pb.Transfer{
+		Id:                 transfer.ID,
+		FromAccountId:      transfer.FromAccountID,
+		ToAccountId:        transfer.ToAccountID,
+		Amount:             transfer.Amount,
+		Currency:           transfer.Currency,
+		CreatedAt:          transfer.CreatedAt.String(),
+		ToAmount:           transfer.ToAmount,
+		ToCurrency:         transfer.ToCurrency,
+		ExchangeRate:       transfer.ExchangeRate,
+		SpreadBps:          transfer.SpreadBps,
+		ReversedAmount:     transfer.ReversedAmount,
+		ReversesTransferId: transfer.ReversesTransferID.Int64,
+		Status:             transferStatuses[transfer.Status],
+	}
+	if transfer.HoldExpiresAt.Valid {
+		pbTransfer.HoldExpiresAt = timestamppb.New(transfer.HoldExpiresAt.Time)
+	}
+	return pbTransfer
 }
@@ -58,5 +58,9 @@ func convertUser(user db.User) *pb.User {
 		Email:             user.Email,
 		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
 		CreatedAt:         timestamppb.New(user.CreatedAt),
+		Role:              user.Role,
+		IsEmailVerified:   user.IsEmailVerified,
+		// Left at 0 when the default applies
+		TransferConfirmationThreshold: user.TransferConfirmationThreshold.Int64,
 	}
 }
@@ -89,6 +89,7 @@ func randomUser(t *testing.T) (user db.User, password string) {
 		PasswordChangedAt: time.Now(),
 		CreatedAt:         time.Now(),
 		IsEmailVerified:   false,
+		Role:              util.DepositorRole,
 	}
 	return
 }
@@ -0,0 +1,114 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mfaIssuer is the name authenticator apps list Nimbus codes under
+const mfaIssuer = "Nimbus"
+
+func (server *Server) EnrollMFA(ctx context.Context, req *pb.EnrollMFARequest) (*pb.EnrollMFAResponse, error) {
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	secret, err := util.NewTOTPSecret()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create secret: %s", err)
+	}
+
+	// Enrolling again before confirming replaces the secret
+	_, err = server.store.StartUserMFAEnrollment(ctx, db.StartUserMFAEnrollmentParams{
+		Username:   authPayload.Username,
+		TotpSecret: secret,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to start enrollment: %s", err)
+	}
+
+	rsp := &pb.EnrollMFAResponse{
+		Secret:          secret,
+		ProvisioningUri: util.TOTPProvisioningURI(mfaIssuer, authPayload.Username, secret),
+	}
+	return rsp, nil
+}
+
+func (server *Server) ConfirmMFA(ctx context.Context, req *pb.ConfirmMFARequest) (*pb.ConfirmMFAResponse, error) {
+	violations := validateConfirmMFARequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	mfa, err := server.store.GetUserMFA(ctx, authPayload.Username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "no two-factor enrollment to confirm")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor settings: %s", err)
+	}
+	if mfa.EnabledAt.Valid {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	step, ok := util.ValidateTOTP(mfa.TotpSecret, req.GetCode(), time.Now())
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "incorrect two-factor code")
+	}
+
+	recoveryCodes, err := util.NewRecoveryCodes(util.RecoveryCodeCount)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create recovery codes: %s", err)
+	}
+	codeHashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		codeHashes[i] = util.HashRecoveryCode(code)
+	}
+
+	_, err = server.store.EnableMFATx(ctx, db.EnableMFATxParams{
+		EnableUserMFAParams: db.EnableUserMFAParams{
+			Username:     authPayload.Username,
+			LastUsedStep: step,
+		},
+		RecoveryCodeHashes: codeHashes,
+		Audit:              server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "no two-factor enrollment to confirm")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to enable two-factor authentication: %s", err)
+	}
+
+	rsp := &pb.ConfirmMFAResponse{
+		RecoveryCodes: recoveryCodes,
+	}
+	return rsp, nil
+}
+
+func validateConfirmMFARequest(req *pb.ConfirmMFARequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateTOTPCode(req.GetCode()); err != nil {
+		violations = append(violations, fieldViolation("code", err))
+	}
+
+	return violations
+}
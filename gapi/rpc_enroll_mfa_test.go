@@ -0,0 +1,213 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestEnrollMFAAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		setupAuth     func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.EnrollMFAResponse, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					StartUserMFAEnrollment(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.StartUserMFAEnrollmentParams) (db.UserMfa, error) {
+						require.Equal(t, user.Username, arg.Username)
+						return db.UserMfa{Username: arg.Username, TotpSecret: arg.TotpSecret}, nil
+					})
+			},
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.EnrollMFAResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.GetSecret())
+
+				uri, err := url.Parse(rsp.GetProvisioningUri())
+				require.NoError(t, err)
+				require.Equal(t, "otpauth", uri.Scheme)
+				require.Equal(t, rsp.GetSecret(), uri.Query().Get("secret"))
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					StartUserMFAEnrollment(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserMfa{}, sql.ErrNoRows)
+			},
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, user.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.EnrollMFAResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "MFAPendingToken",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					StartUserMFAEnrollment(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			setupAuth: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.MFAPendingRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.EnrollMFAResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.setupAuth(t, server.tokenMaker)
+			rsp, err := server.EnrollMFA(newContextWithMethod(ctx, "/pb.VaultguardAPI/EnrollMFA"), &pb.EnrollMFARequest{})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestConfirmMFAAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+	pending := db.UserMfa{Username: user.Username, TotpSecret: secret}
+
+	code, err := util.TOTPCode(secret, util.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	wrongCode := "000000"
+	if wrongCode == code {
+		wrongCode = "111111"
+	}
+
+	testCases := []struct {
+		name          string
+		code          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ConfirmMFAResponse, err error)
+	}{
+		{
+			name: "OK",
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					EnableMFATx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.EnableMFATxParams) (db.EnableMFATxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, util.TOTPStep(time.Now()), arg.LastUsedStep, 1)
+						require.Len(t, arg.RecoveryCodeHashes, util.RecoveryCodeCount)
+						return db.EnableMFATxResult{UserMFA: pending}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmMFAResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, rsp.GetRecoveryCodes(), util.RecoveryCodeCount)
+			},
+		},
+		{
+			name: "IncorrectCode",
+			code: wrongCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(pending, nil)
+				store.EXPECT().
+					EnableMFATx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmMFAResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "NotEnrolled",
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserMfa{}, sql.ErrNoRows)
+				store.EXPECT().
+					EnableMFATx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmMFAResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidCode",
+			code: "abc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConfirmMFAResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role, time.Minute)
+			rsp, err := server.ConfirmMFA(newContextWithMethod(ctx, "/pb.VaultguardAPI/ConfirmMFA"), &pb.ConfirmMFARequest{Code: tc.code})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package gapi
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/statement"
+	"github.com/OmSingh2003/nimbus/val"
+	"github.com/OmSingh2003/nimbus/worker"
+	"github.com/hibiken/asynq"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// contentDispositionHeader is passed through the gateway so browsers save the statement as a file
+const contentDispositionHeader = "content-disposition"
+
+// statementChunkSize is how much of a rendered statement is sent per message
+const statementChunkSize = 32 * 1024
+
+// exportStatementQueuedResponse is the body returned when the statement is emailed instead
+type exportStatementQueuedResponse struct {
+	Message string `json:"message"`
+	Entries int64  `json:"entries"`
+}
+
+// ExportStatement streams the statement as it is rendered, loading its entries a page
+// at a time, so a long statement is never held in memory as a whole
+func (server *Server) ExportStatement(req *pb.ExportStatementRequest, stream grpc.ServerStreamingServer[httpbody.HttpBody]) error {
+	ctx := stream.Context()
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return authError(err)
+	}
+
+	violations := validateExportStatementRequest(req)
+	if violations != nil {
+		return InvalidArgumentError(violations)
+	}
+	format, _ := statement.ParseFormat(req.GetFormat())
+
+	account, err := server.store.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return status.Errorf(codes.NotFound, "account not found")
+		}
+		return status.Errorf(codes.Internal, "failed to find account: %s", err)
+	}
+
+	if !canReadAccount(authPayload, account.Owner) {
+		return status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	startTime := account.CreatedAt
+	if req.GetStartTime() != nil {
+		startTime = req.GetStartTime().AsTime()
+	}
+	endTime := time.Now()
+	if req.GetEndTime() != nil {
+		endTime = req.GetEndTime().AsTime()
+	}
+
+	count, err := server.store.CountAccountStatementEntries(ctx, db.CountAccountStatementEntriesParams{
+		AccountID: account.ID,
+		StartTime: startTime,
+		EndTime:   endTime,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to count entries: %s", err)
+	}
+
+	// Large statements are rendered by the worker and emailed rather than held up in the request
+	if count > server.config.StatementInlineLimit {
+		return server.queueStatementEmail(stream, authPayload.Username, account.ID, format, startTime, endTime, count)
+	}
+
+	summary, err := statement.LoadSummary(ctx, server.store, account, startTime, endTime)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to load statement: %s", err)
+	}
+
+	disposition := fmt.Sprintf(`attachment; filename="%s"`, format.FileName(summary))
+	if err := stream.SetHeader(metadata.Pairs(contentDispositionHeader, disposition)); err != nil {
+		return status.Errorf(codes.Internal, "failed to set header: %s", err)
+	}
+
+	w := bufio.NewWriterSize(&httpBodyWriter{stream: stream, contentType: format.ContentType()}, statementChunkSize)
+	renderer, err := statement.NewRenderer(w, format, summary)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to render statement: %s", err)
+	}
+	if err := renderer.Begin(); err != nil {
+		return status.Errorf(codes.Internal, "failed to render statement: %s", err)
+	}
+	if err := statement.LoadLines(ctx, server.store, summary, renderer.WriteLines); err != nil {
+		return status.Errorf(codes.Internal, "failed to render statement: %s", err)
+	}
+	if err := renderer.End(); err != nil {
+		return status.Errorf(codes.Internal, "failed to render statement: %s", err)
+	}
+	if err := w.Flush(); err != nil {
+		return status.Errorf(codes.Internal, "failed to send statement: %s", err)
+	}
+	return nil
+}
+
+// httpBodyWriter sends what is written to it as the next chunk of an HttpBody stream
+type httpBodyWriter struct {
+	stream      grpc.ServerStreamingServer[httpbody.HttpBody]
+	contentType string
+}
+
+func (w *httpBodyWriter) Write(p []byte) (int, error) {
+	// The message may still be read after Send returns, so it can't share p with the caller
+	err := w.stream.Send(&httpbody.HttpBody{
+		ContentType: w.contentType,
+		Data:        bytes.Clone(p),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (server *Server) queueStatementEmail(stream grpc.ServerStreamingServer[httpbody.HttpBody], username string, accountID int64, format statement.Format, startTime, endTime time.Time, count int64) error {
+	taskPayload := &worker.PayloadSendStatement{
+		Username:  username,
+		AccountID: accountID,
+		Format:    string(format),
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+	opts := []asynq.Option{
+		asynq.MaxRetry(3),
+		asynq.Queue(worker.QueueDefault),
+	}
+	err := server.taskDistributor.DistributeTaskSendStatement(stream.Context(), taskPayload, opts...)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to queue statement: %s", err)
+	}
+
+	data, err := json.Marshal(exportStatementQueuedResponse{
+		Message: "the statement is too large to download and will be emailed to you",
+		Entries: count,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to marshal response: %s", err)
+	}
+
+	rsp := &httpbody.HttpBody{
+		ContentType: "application/json",
+		Data:        data,
+	}
+	return stream.Send(rsp)
+}
+
+func validateExportStatementRequest(req *pb.ExportStatementRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if _, err := statement.ParseFormat(req.GetFormat()); err != nil {
+		violations = append(violations, fieldViolation("format", err))
+	}
+
+	if req.GetStartTime() != nil && req.GetEndTime() != nil && !req.GetStartTime().AsTime().Before(req.GetEndTime().AsTime()) {
+		violations = append(violations, fieldViolation("end_time", errors.New("must be after start_time")))
+	}
+
+	return violations
+}
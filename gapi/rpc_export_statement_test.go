@@ -0,0 +1,257 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/OmSingh2003/nimbus/worker"
+	mockwk "github.com/OmSingh2003/nimbus/worker/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestExportStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+	account := db.Account{
+		ID:        util.RandomInt(1, 1000),
+		Owner:     user.Username,
+		Balance:   util.RandomMoney(),
+		Currency:  util.USD,
+		CreatedAt: time.Now().Add(-48 * time.Hour),
+	}
+	startTime := time.Now().Add(-24 * time.Hour).UTC()
+	endTime := time.Now().UTC()
+	inlineLimit := int64(100)
+
+	entries := []db.ListAccountStatementEntriesRow{
+		{
+			ID:             util.RandomInt(1, 1000),
+			AccountID:      account.ID,
+			Amount:         -250,
+			CreatedAt:      startTime.Add(time.Hour),
+			Currency:       account.Currency,
+			TransferID:     sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true},
+			RunningBalance: 750,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.ExportStatementRequest
+		buildStubs    func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, err error)
+	}{
+		{
+			name: "CSV",
+			req: &pb.ExportStatementRequest{
+				AccountId: account.ID,
+				Format:    "csv",
+				StartTime: timestamppb.New(startTime),
+				EndTime:   timestamppb.New(endTime),
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CountAccountStatementEntries(gomock.Any(), gomock.Eq(db.CountAccountStatementEntriesParams{
+						AccountID: account.ID,
+						StartTime: startTime,
+						EndTime:   endTime,
+					})).
+					Times(1).
+					Return(int64(len(entries)), nil)
+				store.EXPECT().
+					GetAccountStatementBalances(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.GetAccountStatementBalancesRow{OpeningBalance: 1000, ClosingBalance: 750}, nil)
+				store.EXPECT().
+					ListAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(entries, nil)
+				distributor.EXPECT().
+					DistributeTaskSendStatement(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, err error) {
+				require.NoError(t, err)
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+
+				lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+				require.Len(t, lines, 2)
+				require.Contains(t, lines[1], "-2.50,7.50,USD")
+
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), "attachment; filename=\"statement-")
+			},
+		},
+		{
+			name: "LargeStatementEmailed",
+			req: &pb.ExportStatementRequest{
+				AccountId: account.ID,
+				Format:    "pdf",
+				StartTime: timestamppb.New(startTime),
+				EndTime:   timestamppb.New(endTime),
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CountAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(inlineLimit+1, nil)
+				store.EXPECT().
+					ListAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(0)
+				distributor.EXPECT().
+					DistributeTaskSendStatement(gomock.Any(), gomock.Eq(&worker.PayloadSendStatement{
+						Username:  user.Username,
+						AccountID: account.ID,
+						Format:    "pdf",
+						StartTime: startTime,
+						EndTime:   endTime,
+					}), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+				var body exportStatementQueuedResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				require.Equal(t, inlineLimit+1, body.Entries)
+				require.Empty(t, recorder.Header().Get("Content-Disposition"))
+			},
+		},
+		{
+			name: "InvalidFormat",
+			req: &pb.ExportStatementRequest{
+				AccountId: account.ID,
+				Format:    "xlsx",
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "PermissionDenied",
+			req: &pb.ExportStatementRequest{
+				AccountId: account.ID,
+				Format:    "ofx",
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+				store.EXPECT().
+					CountAccountStatementEntries(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, otherUser.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "NotFound",
+			req: &pb.ExportStatementRequest{
+				AccountId: account.ID,
+				Format:    "csv",
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req: &pb.ExportStatementRequest{
+				AccountId: account.ID,
+				Format:    "csv",
+			},
+			buildStubs: func(store *mockdb.MockStore, distributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			taskCtrl := gomock.NewController(t)
+			defer taskCtrl.Finish()
+			taskDistributor := mockwk.NewMockTaskDistributor(taskCtrl)
+
+			tc.buildStubs(store, taskDistributor)
+			server := newTestServer(t, store, taskDistributor)
+			server.config.StatementInlineLimit = inlineLimit
+
+			recorder := httptest.NewRecorder()
+			ctx := newContextWithMethod(tc.buildContext(t, server.tokenMaker), "/pb.VaultguardAPI/ExportStatement")
+			err := server.ExportStatement(tc.req, &gatewayBodyStream{ctx: ctx, w: recorder})
+			tc.checkResponse(t, recorder, err)
+		})
+	}
+}
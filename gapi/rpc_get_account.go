@@ -14,7 +14,7 @@ import (
 func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
 	authPayload, err := server.getAuthPayload(ctx)
 	if err != nil {
-		return nil, err
+		return nil, authError(err)
 	}
 
 	violations := validateGetAccountRequest(req)
@@ -30,7 +30,7 @@ func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest)
 		return nil, status.Errorf(codes.Internal, "failed to find account: %s", err)
 	}
 
-	if account.Owner != authPayload.Username {
+	if !canReadAccount(authPayload, account.Owner) {
 		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
 	}
 
@@ -0,0 +1,136 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) ListAccountEntries(ctx context.Context, req *pb.ListAccountEntriesRequest) (*pb.ListAccountEntriesResponse, error) {
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateListAccountEntriesRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find account: %s", err)
+	}
+
+	if !canReadAccount(authPayload, account.Owner) {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	return server.listAccountEntries(ctx, account, req)
+}
+
+// listAccountEntries loads one page of the account's entries for the period in req,
+// once the caller has been allowed to read the account
+func (server *Server) listAccountEntries(ctx context.Context, account db.Account, req *pb.ListAccountEntriesRequest) (*pb.ListAccountEntriesResponse, error) {
+	startTime := account.CreatedAt
+	if req.GetStartTime() != nil {
+		startTime = req.GetStartTime().AsTime()
+	}
+	endTime := time.Now()
+	if req.GetEndTime() != nil {
+		endTime = req.GetEndTime().AsTime()
+	}
+
+	// Fetch one extra row to know whether there is another page
+	arg := db.ListAccountStatementEntriesParams{
+		AccountID: account.ID,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     req.GetPageSize() + 1,
+	}
+	if req.GetPageToken() != "" {
+		token, err := decodePageToken(req.GetPageToken())
+		if err != nil {
+			return nil, InvalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+		}
+		arg.AfterCreatedAt = sql.NullTime{Time: token.CreatedAt, Valid: true}
+		arg.AfterID = sql.NullInt64{Int64: token.ID, Valid: true}
+	}
+
+	entries, err := server.store.ListAccountStatementEntries(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list entries: %s", err)
+	}
+
+	balances, err := server.store.GetAccountStatementBalances(ctx, db.GetAccountStatementBalancesParams{
+		StartTime: startTime,
+		EndTime:   endTime,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get statement balances: %s", err)
+	}
+
+	var nextPageToken string
+	if len(entries) > int(req.GetPageSize()) {
+		entries = entries[:req.GetPageSize()]
+		last := entries[len(entries)-1]
+		nextPageToken = encodePageToken(last.CreatedAt, last.ID)
+	}
+
+	pbEntries := make([]*pb.Entry, 0, len(entries))
+	for _, entry := range entries {
+		pbEntries = append(pbEntries, convertStatementEntry(entry))
+	}
+
+	rsp := &pb.ListAccountEntriesResponse{
+		Entries:        pbEntries,
+		OpeningBalance: balances.OpeningBalance,
+		ClosingBalance: balances.ClosingBalance,
+		Currency:       account.Currency,
+		NextPageToken:  nextPageToken,
+	}
+	return rsp, nil
+}
+
+func validateListAccountEntriesRequest(req *pb.ListAccountEntriesRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page_size", err))
+	}
+
+	if req.GetStartTime() != nil && req.GetEndTime() != nil && !req.GetStartTime().AsTime().Before(req.GetEndTime().AsTime()) {
+		violations = append(violations, fieldViolation("end_time", errors.New("must be after start_time")))
+	}
+
+	return violations
+}
+
+func convertStatementEntry(entry db.ListAccountStatementEntriesRow) *pb.Entry {
+	return &pb.Entry{
+		Id:                        entry.ID,
+		AccountId:                 entry.AccountID,
+		Amount:                    entry.Amount,
+		Currency:                  entry.Currency,
+		CreatedAt:                 timestamppb.New(entry.CreatedAt),
+		TransferId:                entry.TransferID.Int64,
+		RunningBalance:            entry.RunningBalance,
+		CounterpartyAccountId:     entry.CounterpartyAccountID,
+		CounterpartyAccountNumber: entry.CounterpartyAccountNumber,
+	}
+}
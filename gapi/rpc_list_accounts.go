@@ -2,6 +2,7 @@ package gapi
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 
 	db "github.com/OmSingh2003/nimbus/db/sqlc"
@@ -14,7 +15,7 @@ import (
 func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
 	authPayload, err := server.getAuthPayload(ctx)
 	if err != nil {
-		return nil, err
+		return nil, authError(err)
 	}
 
 	violations := validateListAccountsRequest(req)
@@ -22,10 +23,20 @@ func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequ
 		return nil, InvalidArgumentError(violations)
 	}
 
+	// Fetch one extra row to know whether there is another page
 	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Limit:  req.GetPageSize(),
-		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+		Owner: authPayload.Username,
+		Limit: req.GetPageSize() + 1,
+	}
+	if req.GetPageToken() != "" {
+		token, err := decodePageToken(req.GetPageToken())
+		if err != nil {
+			return nil, InvalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+		}
+		arg.AfterCreatedAt = sql.NullTime{Time: token.CreatedAt, Valid: true}
+		arg.AfterID = sql.NullInt64{Int64: token.ID, Valid: true}
+	} else if req.GetPageId() > 0 {
+		arg.Offset = (req.GetPageId() - 1) * req.GetPageSize()
 	}
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
@@ -33,20 +44,30 @@ func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequ
 		return nil, status.Errorf(codes.Internal, "failed to list accounts: %s", err)
 	}
 
+	var nextPageToken string
+	if len(accounts) > int(req.GetPageSize()) {
+		accounts = accounts[:req.GetPageSize()]
+		last := accounts[len(accounts)-1]
+		nextPageToken = encodePageToken(last.CreatedAt, last.ID)
+	}
+
 	pbAccounts := make([]*pb.Account, len(accounts))
 	for i, account := range accounts {
 		pbAccounts[i] = convertAccount(account)
 	}
 
 	rsp := &pb.ListAccountsResponse{
-		Accounts: pbAccounts,
+		Accounts:      pbAccounts,
+		NextPageToken: nextPageToken,
 	}
 	return rsp, nil
 }
 
 func validateListAccountsRequest(req *pb.ListAccountsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
-	if req.GetPageId() <= 0 {
-		violations = append(violations, fieldViolation("page_id", errors.New("must be greater than 0")))
+	if req.GetPageId() < 0 {
+		violations = append(violations, fieldViolation("page_id", errors.New("must not be negative")))
+	} else if req.GetPageId() > 0 && req.GetPageToken() != "" {
+		violations = append(violations, fieldViolation("page_id", errors.New("cannot be combined with page_token")))
 	}
 
 	if req.GetPageSize() <= 0 {
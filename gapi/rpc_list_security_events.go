@@ -0,0 +1,89 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (server *Server) ListSecurityEvents(ctx context.Context, req *pb.ListSecurityEventsRequest) (*pb.ListSecurityEventsResponse, error) {
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
+		return nil, InvalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_size", err)})
+	}
+
+	// Fetch one extra row to know whether there is another page
+	arg := db.ListUserAuditEventsParams{
+		Username: authPayload.Username,
+		Limit:    req.GetPageSize() + 1,
+	}
+	if req.GetPageToken() != "" {
+		token, err := decodePageToken(req.GetPageToken())
+		if err != nil {
+			return nil, InvalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+		}
+		arg.BeforeID = sql.NullInt64{Int64: token.ID, Valid: true}
+	}
+
+	events, err := server.store.ListUserAuditEvents(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list security events: %s", err)
+	}
+
+	var nextPageToken string
+	if len(events) > int(req.GetPageSize()) {
+		events = events[:req.GetPageSize()]
+		last := events[len(events)-1]
+		nextPageToken = encodePageToken(last.CreatedAt, last.ID)
+	}
+
+	pbEvents := make([]*pb.SecurityEvent, 0, len(events))
+	for _, event := range events {
+		pbEvent, err := convertSecurityEvent(event)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to convert security event: %s", err)
+		}
+		pbEvents = append(pbEvents, pbEvent)
+	}
+
+	rsp := &pb.ListSecurityEventsResponse{
+		Events:        pbEvents,
+		NextPageToken: nextPageToken,
+	}
+	return rsp, nil
+}
+
+func convertSecurityEvent(event db.AuditEvent) (*pb.SecurityEvent, error) {
+	var details map[string]any
+	if err := json.Unmarshal(event.Details, &details); err != nil {
+		return nil, err
+	}
+
+	pbDetails, err := structpb.NewStruct(details)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.SecurityEvent{
+		Id:        event.ID,
+		EventType: event.EventType,
+		Actor:     event.Actor,
+		Details:   pbDetails,
+		ClientIp:  event.ClientIp,
+		UserAgent: event.UserAgent,
+		CreatedAt: timestamppb.New(event.CreatedAt),
+	}, nil
+}
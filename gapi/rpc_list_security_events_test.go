@@ -0,0 +1,77 @@
+package gapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestListSecurityEventsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	events := make([]db.AuditEvent, 3)
+	for i := range events {
+		events[i] = db.AuditEvent{
+			ID:        int64(30 - i),
+			Username:  user.Username,
+			Actor:     user.Username,
+			EventType: db.AuditLogin,
+			Details:   json.RawMessage(`{"session_id":"abc"}`),
+			CreatedAt: time.Now(),
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := mockdb.NewMockStore(ctrl)
+
+	// First page: one extra row is fetched to detect the next page
+	store.EXPECT().
+		ListUserAuditEvents(gomock.Any(), gomock.Eq(db.ListUserAuditEventsParams{
+			Username: user.Username,
+			Limit:    3,
+		})).
+		Times(1).
+		Return(events, nil)
+
+	server := newTestServer(t, store, nil)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, util.DepositorRole, time.Minute)
+
+	rsp, err := server.ListSecurityEvents(newContextWithMethod(ctx, "/pb.VaultguardAPI/ListSecurityEvents"), &pb.ListSecurityEventsRequest{PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, rsp.GetEvents(), 2)
+	require.Equal(t, int64(30), rsp.GetEvents()[0].GetId())
+	require.Equal(t, "abc", rsp.GetEvents()[0].GetDetails().GetFields()["session_id"].GetStringValue())
+	require.NotEmpty(t, rsp.GetNextPageToken())
+
+	// Second page resumes before the last event of the first one
+	store.EXPECT().
+		ListUserAuditEvents(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.ListUserAuditEventsParams) ([]db.AuditEvent, error) {
+			require.True(t, arg.BeforeID.Valid)
+			require.Equal(t, int64(29), arg.BeforeID.Int64)
+			return events[2:], nil
+		})
+
+	rsp, err = server.ListSecurityEvents(newContextWithMethod(ctx, "/pb.VaultguardAPI/ListSecurityEvents"), &pb.ListSecurityEventsRequest{PageSize: 2, PageToken: rsp.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, rsp.GetEvents(), 1)
+	require.Empty(t, rsp.GetNextPageToken())
+
+	_, err = server.ListSecurityEvents(context.Background(), &pb.ListSecurityEventsRequest{PageSize: 2})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = server.ListSecurityEvents(newContextWithMethod(ctx, "/pb.VaultguardAPI/ListSecurityEvents"), &pb.ListSecurityEventsRequest{PageSize: 0})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -2,6 +2,8 @@ package gapi
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	db "github.com/OmSingh2003/nimbus/db/sqlc"
 	"github.com/OmSingh2003/nimbus/pb"
@@ -14,7 +16,7 @@ import (
 func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
 	authPayload, err := server.getAuthPayload(ctx)
 	if err != nil {
-		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %s", err)
+		return nil, authError(err)
 	}
 
 	violations := validateListTransfersRequest(req)
@@ -22,101 +24,140 @@ func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRe
 		return nil, InvalidArgumentError(violations)
 	}
 
-	// Get user's accounts to filter transfers
-	accounts, err := server.store.ListAccounts(ctx, db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Limit:  100, // Get all user accounts
-		Offset: 0,
-	})
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to get user accounts: %s", err)
+	// Filtering by owner happens in SQL, so pagination and the count only see the user's transfers
+	filter := db.CountUserTransfersParams{
+		Owner: authPayload.Username,
 	}
-
-	if len(accounts) == 0 {
-		return &pb.ListTransfersResponse{
-			Transfers: []*pb.Transfer{},
-		}, nil
+	if req.GetAccountId() > 0 {
+		// Staff can list the transfers of any account, like they can read its entries
+		account, err := server.store.GetAccount(ctx, req.GetAccountId())
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, status.Errorf(codes.NotFound, "account not found")
+			}
+			return nil, status.Errorf(codes.Internal, "failed to find account: %s", err)
+		}
+		if !canReadAccount(authPayload, account.Owner) {
+			return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+		}
+		filter.Owner = account.Owner
+		filter.AccountID = sql.NullInt64{Int64: account.ID, Valid: true}
+	}
+	switch req.GetDirection() {
+	case pb.TransferDirection_TRANSFER_DIRECTION_IN:
+		filter.Direction = sql.NullString{String: "in", Valid: true}
+	case pb.TransferDirection_TRANSFER_DIRECTION_OUT:
+		filter.Direction = sql.NullString{String: "out", Valid: true}
+	}
+	if req.GetStatus() != pb.TransferStatus_TRANSFER_STATUS_UNSPECIFIED {
+		filter.Status = sql.NullString{String: dbTransferStatus(req.GetStatus()), Valid: true}
+	}
+	if req.GetStartTime() != nil {
+		filter.StartTime = sql.NullTime{Time: req.GetStartTime().AsTime(), Valid: true}
+	}
+	if req.GetEndTime() != nil {
+		filter.EndTime = sql.NullTime{Time: req.GetEndTime().AsTime(), Valid: true}
+	}
+	if req.GetMinAmount() > 0 {
+		filter.MinAmount = sql.NullInt64{Int64: req.GetMinAmount(), Valid: true}
+	}
+	if req.GetMaxAmount() > 0 {
+		filter.MaxAmount = sql.NullInt64{Int64: req.GetMaxAmount(), Valid: true}
 	}
 
-	// Use the first account for the query (the query will find transfers for any account owned by the user)
-	accountId := accounts[0].ID
-
-	arg := db.ListTransfersParams{
-		FromAccountID: accountId,
-		ToAccountID:   accountId,
-		Limit:         req.GetPageSize(),
-		Offset:        (req.GetPageNumber() - 1) * req.GetPageSize(),
+	// Fetch one extra row to know whether there is another page
+	arg := db.ListUserTransfersParams{
+		Owner:     filter.Owner,
+		AccountID: filter.AccountID,
+		Direction: filter.Direction,
+		StartTime: filter.StartTime,
+		EndTime:   filter.EndTime,
+		MinAmount: filter.MinAmount,
+		MaxAmount: filter.MaxAmount,
+		Status:    filter.Status,
+		Limit:     req.GetPageSize() + 1,
+	}
+	if req.GetPageToken() != "" {
+		token, err := decodePageToken(req.GetPageToken())
+		if err != nil {
+			return nil, InvalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", err)})
+		}
+		arg.BeforeCreatedAt = sql.NullTime{Time: token.CreatedAt, Valid: true}
+		arg.BeforeID = sql.NullInt64{Int64: token.ID, Valid: true}
+	} else if req.GetPageNumber() > 0 {
+		arg.Offset = (req.GetPageNumber() - 1) * req.GetPageSize()
 	}
 
-	transfers, err := server.store.ListTransfers(ctx, arg)
+	transfers, err := server.store.ListUserTransfers(ctx, arg)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list transfers: %s", err)
 	}
 
-	// Filter transfers to only include those involving user's accounts
-	var userTransfers []db.Transfer
-	accountIds := make(map[int64]bool)
-	for _, account := range accounts {
-		accountIds[account.ID] = true
+	var nextPageToken string
+	if len(transfers) > int(req.GetPageSize()) {
+		transfers = transfers[:req.GetPageSize()]
+		last := transfers[len(transfers)-1]
+		nextPageToken = encodePageToken(last.CreatedAt, last.ID)
 	}
 
-	for _, transfer := range transfers {
-		if accountIds[transfer.FromAccountID] || accountIds[transfer.ToAccountID] {
-			userTransfers = append(userTransfers, transfer)
-		}
+	totalCount, err := server.store.CountUserTransfers(ctx, filter)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to count transfers: %s", err)
 	}
 
 	// Convert to protobuf
-	var pbTransfers []*pb.Transfer
-	for _, transfer := range userTransfers {
-		pbTransfers = append(pbTransfers, convertTransferWithDetails(transfer, accounts))
+	pbTransfers := make([]*pb.Transfer, 0, len(transfers))
+	for _, transfer := range transfers {
+		pbTransfers = append(pbTransfers, convertTransfer(transfer))
 	}
 
 	rsp := &pb.ListTransfersResponse{
-		Transfers: pbTransfers,
+		Transfers:     pbTransfers,
+		TotalCount:    totalCount,
+		NextPageToken: nextPageToken,
 	}
 
 	return rsp, nil
 }
 
 func validateListTransfersRequest(req *pb.ListTransfersRequest) (violations []*errdetails.BadRequest_FieldViolation) {
-	if err := val.ValidatePageNumber(req.GetPageNumber()); err != nil {
-		violations = append(violations, fieldViolation("page_number", err))
+	if req.GetPageNumber() < 0 {
+		violations = append(violations, fieldViolation("page_number", errors.New("must not be negative")))
+	} else if req.GetPageNumber() > 0 && req.GetPageToken() != "" {
+		violations = append(violations, fieldViolation("page_number", errors.New("cannot be combined with page_token")))
 	}
 
 	if err := val.ValidatePageSize(req.GetPageSize()); err != nil {
 		violations = append(violations, fieldViolation("page_size", err))
 	}
 
-	return violations
-}
+	if req.GetAccountId() < 0 {
+		violations = append(violations, fieldViolation("account_id", errors.New("must be positive")))
+	}
 
-func convertTransferWithDetails(transfer db.Transfer, accounts []db.Account) *pb.Transfer {
-	// Find account details for better display
-	var fromAccount, toAccount *db.Account
-	for i := range accounts {
-		if accounts[i].ID == transfer.FromAccountID {
-			fromAccount = &accounts[i]
-		}
-		if accounts[i].ID == transfer.ToAccountID {
-			toAccount = &accounts[i]
-		}
+	if _, ok := pb.TransferDirection_name[int32(req.GetDirection())]; !ok {
+		violations = append(violations, fieldViolation("direction", errors.New("unknown direction")))
 	}
 
-	// Determine currency (use from_account currency if available)
-	currency := "USD" // default
-	if fromAccount != nil {
-		currency = fromAccount.Currency
-	} else if toAccount != nil {
-		currency = toAccount.Currency
+	if req.GetStatus() != pb.TransferStatus_TRANSFER_STATUS_UNSPECIFIED && dbTransferStatus(req.GetStatus()) == "" {
+		violations = append(violations, fieldViolation("status", errors.New("unknown status")))
 	}
 
-	return &pb.Transfer{
-		Id:            transfer.ID,
-		FromAccountId: transfer.FromAccountID,
-		ToAccountId:   transfer.ToAccountID,
-		Amount:        transfer.Amount,
-		Currency:      currency,
-		CreatedAt:     transfer.CreatedAt.String(),
+	if req.GetStartTime() != nil && req.GetEndTime() != nil && !req.GetStartTime().AsTime().Before(req.GetEndTime().AsTime()) {
+		violations = append(violations, fieldViolation("end_time", errors.New("must be after start_time")))
 	}
+
+	if req.GetMinAmount() < 0 {
+		violations = append(violations, fieldViolation("min_amount", errors.New("must not be negative")))
+	}
+
+	if req.GetMaxAmount() < 0 {
+		violations = append(violations, fieldViolation("max_amount", errors.New("must not be negative")))
+	}
+
+	if req.GetMaxAmount() > 0 && req.GetMinAmount() > req.GetMaxAmount() {
+		violations = append(violations, fieldViolation("max_amount", errors.New("must not be less than min_amount")))
+	}
+
+	return violations
 }
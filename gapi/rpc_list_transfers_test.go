@@ -0,0 +1,432 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func randomTransfer(fromAccountID int64, toAccountID int64) db.Transfer {
+	amount := util.RandomMoney()
+	return db.Transfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        amount,
+		Currency:      util.USD,
+		ToAmount:      amount,
+		ToCurrency:    util.USD,
+		ExchangeRate:  "1",
+		Status:        db.TransferPosted,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func TestListTransfersAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	accountID := util.RandomInt(1, 1000)
+	account := db.Account{ID: accountID, Owner: user.Username, Currency: util.USD}
+	otherAccount := db.Account{ID: accountID + 1, Owner: util.RandomOwner(), Currency: util.USD}
+	startTime := time.Now().Add(-24 * time.Hour).UTC()
+
+	transfers := []db.Transfer{
+		randomTransfer(accountID, accountID+1),
+		randomTransfer(accountID+1, accountID),
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.ListTransfersRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.ListTransfersResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.ListTransfersRequest{
+				PageNumber: 2,
+				PageSize:   5,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Eq(db.ListUserTransfersParams{
+						Owner:  user.Username,
+						Limit:  6,
+						Offset: 5,
+					})).
+					Times(1).
+					Return(transfers, nil)
+
+				store.EXPECT().
+					CountUserTransfers(gomock.Any(), gomock.Eq(db.CountUserTransfersParams{
+						Owner: user.Username,
+					})).
+					Times(1).
+					Return(int64(7), nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetTransfers(), len(transfers))
+				require.Equal(t, int64(7), res.GetTotalCount())
+				for i, transfer := range res.GetTransfers() {
+					require.Equal(t, transfers[i].ID, transfer.GetId())
+					require.Equal(t, transfers[i].Currency, transfer.GetCurrency())
+				}
+			},
+		},
+		{
+			name: "Filters",
+			req: &pb.ListTransfersRequest{
+				PageNumber: 1,
+				PageSize:   10,
+				AccountId:  accountID,
+				Direction:  pb.TransferDirection_TRANSFER_DIRECTION_OUT,
+				StartTime:  timestamppb.New(startTime),
+				MinAmount:  100,
+				MaxAmount:  500,
+				Status:     pb.TransferStatus_TRANSFER_STATUS_POSTED,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountID)).
+					Times(1).
+					Return(account, nil)
+
+				filter := db.CountUserTransfersParams{
+					Owner:     user.Username,
+					AccountID: sql.NullInt64{Int64: accountID, Valid: true},
+					Direction: sql.NullString{String: "out", Valid: true},
+					StartTime: sql.NullTime{Time: startTime, Valid: true},
+					MinAmount: sql.NullInt64{Int64: 100, Valid: true},
+					MaxAmount: sql.NullInt64{Int64: 500, Valid: true},
+					Status:    sql.NullString{String: db.TransferPosted, Valid: true},
+				}
+
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Eq(db.ListUserTransfersParams{
+						Owner:     filter.Owner,
+						AccountID: filter.AccountID,
+						Direction: filter.Direction,
+						StartTime: filter.StartTime,
+						MinAmount: filter.MinAmount,
+						MaxAmount: filter.MaxAmount,
+						Status:    filter.Status,
+						Limit:     11,
+						Offset:    0,
+					})).
+					Times(1).
+					Return([]db.Transfer{transfers[0]}, nil)
+
+				store.EXPECT().
+					CountUserTransfers(gomock.Any(), gomock.Eq(filter)).
+					Times(1).
+					Return(int64(1), nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetTransfers(), 1)
+				require.Equal(t, int64(1), res.GetTotalCount())
+				require.Equal(t, pb.TransferStatus_TRANSFER_STATUS_POSTED, res.GetTransfers()[0].GetStatus())
+			},
+		},
+		{
+			name: "SupportOtherUsersAccount",
+			req: &pb.ListTransfersRequest{
+				PageSize:  10,
+				AccountId: otherAccount.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).
+					Times(1).
+					Return(otherAccount, nil)
+
+				filter := db.CountUserTransfersParams{
+					Owner:     otherAccount.Owner,
+					AccountID: sql.NullInt64{Int64: otherAccount.ID, Valid: true},
+				}
+
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Eq(db.ListUserTransfersParams{
+						Owner:     filter.Owner,
+						AccountID: filter.AccountID,
+						Limit:     11,
+					})).
+					Times(1).
+					Return(transfers, nil)
+
+				store.EXPECT().
+					CountUserTransfers(gomock.Any(), gomock.Eq(filter)).
+					Times(1).
+					Return(int64(2), nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, util.RandomOwner(), util.SupportRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetTransfers(), len(transfers))
+			},
+		},
+		{
+			name: "OtherUsersAccount",
+			req: &pb.ListTransfersRequest{
+				PageSize:  10,
+				AccountId: otherAccount.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).
+					Times(1).
+					Return(otherAccount, nil)
+
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "AccountNotFound",
+			req: &pb.ListTransfersRequest{
+				PageSize:  10,
+				AccountId: accountID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(accountID)).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "PageToken",
+			req: &pb.ListTransfersRequest{
+				PageSize:  1,
+				PageToken: encodePageToken(transfers[0].CreatedAt, transfers[0].ID),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Eq(db.ListUserTransfersParams{
+						Owner:           user.Username,
+						BeforeCreatedAt: sql.NullTime{Time: transfers[0].CreatedAt.UTC(), Valid: true},
+						BeforeID:        sql.NullInt64{Int64: transfers[0].ID, Valid: true},
+						Limit:           2,
+					})).
+					Times(1).
+					Return(transfers, nil)
+
+				store.EXPECT().
+					CountUserTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(5), nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetTransfers(), 1)
+				require.Equal(t, transfers[0].ID, res.GetTransfers()[0].GetId())
+
+				// The extra row means there is another page, starting after the last one returned
+				token, err := decodePageToken(res.GetNextPageToken())
+				require.NoError(t, err)
+				require.Equal(t, transfers[0].ID, token.ID)
+			},
+		},
+		{
+			name: "LastPage",
+			req: &pb.ListTransfersRequest{
+				PageSize: 5,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Eq(db.ListUserTransfersParams{
+						Owner: user.Username,
+						Limit: 6,
+					})).
+					Times(1).
+					Return(transfers, nil)
+
+				store.EXPECT().
+					CountUserTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(len(transfers)), nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, res.GetTransfers(), len(transfers))
+				require.Empty(t, res.GetNextPageToken())
+			},
+		},
+		{
+			name: "InvalidPageToken",
+			req: &pb.ListTransfersRequest{
+				PageSize:  5,
+				PageToken: "garbage",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "InvalidAmountRange",
+			req: &pb.ListTransfersRequest{
+				PageNumber: 1,
+				PageSize:   10,
+				MinAmount:  500,
+				MaxAmount:  100,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "InvalidStatus",
+			req: &pb.ListTransfersRequest{
+				PageNumber: 1,
+				PageSize:   10,
+				Status:     pb.TransferStatus(99),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.InvalidArgument, st.Code())
+			},
+		},
+		{
+			name: "InternalError",
+			req: &pb.ListTransfersRequest{
+				PageNumber: 1,
+				PageSize:   10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+
+				store.EXPECT().
+					CountUserTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, util.DepositorRole, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Internal, st.Code())
+			},
+		},
+		{
+			name: "Unauthenticated",
+			req: &pb.ListTransfersRequest{
+				PageNumber: 1,
+				PageSize:   10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListUserTransfers(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, res *pb.ListTransfersResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.Unauthenticated, st.Code())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.ListTransfers(newContextWithMethod(ctx, "/pb.VaultguardAPI/ListTransfers"), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
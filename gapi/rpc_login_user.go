@@ -2,6 +2,8 @@ package gapi
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	db "github.com/OmSingh2003/nimbus/db/sqlc"
 	"github.com/OmSingh2003/nimbus/pb"
@@ -12,14 +14,37 @@ import (
 )
 
 func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	reservation, err := server.reserveLoginAttempt(ctx, server.loginKeys(ctx, req.GetUsername()))
+	if err != nil {
+		return nil, err
+	}
+
 	user, err := server.store.GetUser(ctx, req.GetUsername())
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "user not found: %s", err)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+		}
+		// Unknown usernames go through the same steps as wrong passwords, so
+		// neither the response nor its timing reveals whether the username exists
+		_ = util.CheckPassword(req.GetPassword(), dummyPasswordHash())
+		if err := server.recordFailedLogin(ctx, req.GetUsername(), "unknown_username"); err != nil {
+			return nil, err
+		}
+		server.loginAttemptFailed(ctx, reservation, nil)
+		return nil, errInvalidLogin
 	}
 
 	err = util.CheckPassword(req.GetPassword(), user.HashedPassword)
 	if err != nil {
-		return nil, status.Errorf(codes.Unauthenticated, "incorrect password: %s", err)
+		if err := server.recordFailedLogin(ctx, user.Username, "incorrect_password"); err != nil {
+			return nil, err
+		}
+		server.loginAttemptFailed(ctx, reservation, &user)
+		return nil, errInvalidLogin
+	}
+
+	if err := server.releaseLoginAttempt(ctx, reservation); err != nil {
+		return nil, err
 	}
 
 	// Check if email is verified
@@ -27,8 +52,43 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 		return nil, status.Errorf(codes.FailedPrecondition, "email not verified: please check your email and verify your account before logging in")
 	}
 
+	// Failed logins are only cleared once the second factor is verified too
+	mfa, err := server.store.GetUserMFA(ctx, user.Username)
+	if err == nil && mfa.EnabledAt.Valid {
+		return server.mfaChallenge(user)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor settings: %s", err)
+	}
+
+	if err := server.clearLoginFailures(ctx, user.Username); err != nil {
+		return nil, err
+	}
+
+	return server.createLoginSession(ctx, user, nil)
+}
+
+// recordFailedLogin records a failed login in the audit log under username,
+// whether or not a user by that name exists
+func (server *Server) recordFailedLogin(ctx context.Context, username string, reason string) error {
+	_, err := server.store.RecordAuditEventTx(ctx, db.AuditEventParams{
+		Username:  username,
+		EventType: db.AuditLoginFailed,
+		Details:   map[string]any{"reason": reason},
+		Audit:     server.auditContext(ctx, username),
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to record failed login: %s", err)
+	}
+	return nil
+}
+
+// createLoginSession issues access and refresh tokens for a user who has logged in,
+// and records the login in their audit log along with details
+func (server *Server) createLoginSession(ctx context.Context, user db.User, details map[string]any) (*pb.LoginUserResponse, error) {
 	accessToken, accessPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
+		user.Role,
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -37,6 +97,7 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 
 	refreshToken, refreshPayload, err := server.tokenMaker.CreateToken(
 		user.Username,
+		user.Role,
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
@@ -51,11 +112,26 @@ func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (
 		ClientIp:     mtdt.ClientIP,
 		IsBoolean:    false,
 		ExpiresAt:    refreshPayload.ExpiredAt,
+		FamilyID:     refreshPayload.ID,
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create session: %s", err)
 	}
 
+	if details == nil {
+		details = map[string]any{}
+	}
+	details["session_id"] = session.ID.String()
+	_, err = server.store.RecordAuditEventTx(ctx, db.AuditEventParams{
+		Username:  user.Username,
+		EventType: db.AuditLogin,
+		Details:   details,
+		Audit:     server.auditContext(ctx, user.Username),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to record login: %s", err)
+	}
+
 	rsp := &pb.LoginUserResponse{
 		User:                  convertUser(user),
 		AccessToken:           accessToken,
@@ -0,0 +1,345 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/worker"
+	mockwk "github.com/OmSingh2003/nimbus/worker/mock"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoginUserAPI(t *testing.T) {
+	user, password := randomUser(t)
+	user.IsEmailVerified = true
+
+	usernameKey := db.LoginFailureKey{Scope: loginScopeUsername, Key: user.Username}
+	ipKey := db.LoginFailureKey{Scope: loginScopeIP, Key: "203.0.113.7"}
+
+	testCases := []struct {
+		name          string
+		req           *pb.LoginUserRequest
+		ctx           context.Context
+		buildStubs    func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, rsp *pb.LoginUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: password},
+			ctx:  context.Background(),
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				reservation := reservedLoginAttempt(1, usernameKey)
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+						require.Equal(t, []db.LoginFailureKey{usernameKey}, arg.Keys)
+						return reservation, nil
+					})
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Eq(db.ReleaseLoginAttemptParams{
+						FailureCount:  0,
+						LastFailedAt:  reservation.Previous[0].LastFailedAt,
+						LockedUntil:   reservation.Previous[0].LockedUntil,
+						Scope:         loginScopeUsername,
+						Key:           user.Username,
+						ReservedUntil: reservation.Reserved[0].LockedUntil,
+					})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserMfa{}, sql.ErrNoRows)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Eq(db.DeleteLoginFailureParams{Scope: loginScopeUsername, Key: user.Username})).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, Username: arg.Username}, nil
+					})
+				store.EXPECT().
+					RecordAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.AuditEventParams) (db.AuditEvent, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, db.AuditLogin, arg.EventType)
+						return db.AuditEvent{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.GetAccessToken())
+				require.Equal(t, user.Username, rsp.GetUser().GetUsername())
+			},
+		},
+		{
+			name: "MFARequired",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: password},
+			ctx:  context.Background(),
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reservedLoginAttempt(1, usernameKey), nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.UserMfa{
+						Username:  user.Username,
+						EnabledAt: sql.NullTime{Time: time.Now(), Valid: true},
+					}, nil)
+				// Failed logins are kept until the second factor is verified
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, rsp.GetMfaRequired())
+				require.NotEmpty(t, rsp.GetMfaToken())
+				require.Empty(t, rsp.GetAccessToken())
+				require.Empty(t, rsp.GetRefreshToken())
+			},
+		},
+		{
+			name: "IncorrectPassword",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: "wrong" + password},
+			ctx:  context.Background(),
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+						// The third failure backs off for four times the base
+						require.Equal(t, 4*time.Second, arg.LockFor(3))
+						return reservedLoginAttempt(3, usernameKey), nil
+					})
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					RecordAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.AuditEventParams) (db.AuditEvent, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, db.AuditLoginFailed, arg.EventType)
+						return db.AuditEvent{}, nil
+					})
+				// The attempt stays counted as failed
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(0)
+				taskDistributor.EXPECT().
+					DistributeTaskSendLockoutEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+				require.Equal(t, errInvalidLogin.Error(), err.Error())
+			},
+		},
+		{
+			name: "UserNotFound",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: password},
+			ctx:  newContextWithClientIP("198.51.100.1, 203.0.113.7"),
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+						require.Equal(t, []db.LoginFailureKey{usernameKey, ipKey}, arg.Keys)
+						return reservedLoginAttempt(5, usernameKey, ipKey), nil
+					})
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+				// Recorded like a wrong password, so both take the same time
+				store.EXPECT().
+					RecordAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.AuditEventParams) (db.AuditEvent, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, db.AuditLoginFailed, arg.EventType)
+						require.Equal(t, "unknown_username", arg.Details["reason"])
+						return db.AuditEvent{}, nil
+					})
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(0)
+				taskDistributor.EXPECT().
+					DistributeTaskSendLockoutEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+				require.Equal(t, errInvalidLogin.Error(), err.Error())
+			},
+		},
+		{
+			name: "LockedOut",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: "wrong" + password},
+			ctx:  context.Background(),
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ReserveLoginAttemptTxParams) (db.ReserveLoginAttemptTxResult, error) {
+						require.Equal(t, 15*time.Minute, arg.LockFor(5))
+						return reservedLoginAttempt(5, usernameKey), nil
+					})
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					RecordAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditEvent{}, nil)
+				taskDistributor.EXPECT().
+					DistributeTaskSendLockoutEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, payload *worker.PayloadSendLockoutEmail, opts ...asynq.Option) error {
+						require.Equal(t, user.Username, payload.Username)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "Locked",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: password},
+			ctx:  newContextWithClientIP("203.0.113.7"),
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReserveLoginAttemptTxResult{}, db.ErrLoginLocked)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.ResourceExhausted, status.Code(err))
+			},
+		},
+		{
+			name: "ReserveError",
+			req:  &pb.LoginUserRequest{Username: user.Username, Password: password},
+			ctx:  context.Background(),
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReserveLoginAttemptTxResult{}, sql.ErrConnDone)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			taskCtrl := gomock.NewController(t)
+			defer taskCtrl.Finish()
+			taskDistributor := mockwk.NewMockTaskDistributor(taskCtrl)
+
+			tc.buildStubs(store, taskDistributor)
+			server := newTestServer(t, store, taskDistributor)
+
+			rsp, err := server.LoginUser(tc.ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestLoginBackoff(t *testing.T) {
+	server := newTestServer(t, nil, nil)
+
+	require.Zero(t, server.loginBackoff(0))
+	require.Equal(t, time.Second, server.loginBackoff(1))
+	require.Equal(t, 2*time.Second, server.loginBackoff(2))
+	require.Equal(t, 8*time.Second, server.loginBackoff(4))
+	require.Equal(t, 15*time.Minute, server.loginBackoff(100))
+}
+
+// reservedLoginAttempt is what ReserveLoginAttemptTx returns for an attempt that
+// is the failures-th failure in a row against each of the keys
+func reservedLoginAttempt(failures int64, keys ...db.LoginFailureKey) db.ReserveLoginAttemptTxResult {
+	now := time.Now()
+	var result db.ReserveLoginAttemptTxResult
+	for _, key := range keys {
+		result.Previous = append(result.Previous, db.LoginFailure{
+			Scope:        key.Scope,
+			Key:          key.Key,
+			FailureCount: failures - 1,
+			LastFailedAt: now.Add(-time.Minute),
+			LockedUntil:  now.Add(-time.Minute),
+		})
+		result.Reserved = append(result.Reserved, db.LoginFailure{
+			Scope:        key.Scope,
+			Key:          key.Key,
+			FailureCount: failures,
+			LastFailedAt: now,
+			LockedUntil:  now.Add(time.Second),
+		})
+	}
+	return result
+}
+
+func newContextWithClientIP(clientIP string) context.Context {
+	md := metadata.MD{
+		xForwardedForHeader: []string{clientIP},
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
@@ -0,0 +1,48 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (server *Server) ReleaseHold(ctx context.Context, req *pb.ReleaseHoldRequest) (*pb.ReleaseHoldResponse, error) {
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateReleaseHoldRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	hold, err := server.getHoldForRecipient(ctx, req.GetId(), authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := server.store.ReleaseHoldTx(ctx, db.ReleaseHoldTxParams{
+		ID:    hold.ID,
+		Audit: server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		return nil, holdError(err)
+	}
+
+	rsp := &pb.ReleaseHoldResponse{
+		Hold: convertTransfer(result.Hold),
+	}
+	return rsp, nil
+}
+
+func validateReleaseHoldRequest(req *pb.ReleaseHoldRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
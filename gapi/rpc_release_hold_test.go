@@ -0,0 +1,137 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReleaseHoldAPI(t *testing.T) {
+	sender, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+	toAccount := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    recipient.Username,
+		Currency: util.USD,
+	}
+	hold := randomHold(util.RandomInt(1001, 2000), toAccount.ID)
+
+	testCases := []struct {
+		name          string
+		req           *pb.ReleaseHoldRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.ReleaseHoldResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.ReleaseHoldRequest{Id: hold.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					ReleaseHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ReleaseHoldTxParams) (db.ReleaseHoldTxResult, error) {
+						require.Equal(t, hold.ID, arg.ID)
+						require.False(t, arg.Expired)
+						require.Equal(t, recipient.Username, arg.Audit.Actor)
+
+						released := hold
+						released.Status = db.TransferFailed
+						return db.ReleaseHoldTxResult{Hold: released}, nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReleaseHoldResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, hold.ID, rsp.GetHold().GetId())
+				require.Equal(t, pb.TransferStatus_TRANSFER_STATUS_FAILED, rsp.GetHold().GetStatus())
+			},
+		},
+		{
+			name: "NotRecipient",
+			req:  &pb.ReleaseHoldRequest{Id: hold.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					ReleaseHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, sender.Username, sender.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReleaseHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "HoldNotFound",
+			req:  &pb.ReleaseHoldRequest{Id: hold.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().
+					ReleaseHoldTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReleaseHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "NotActive",
+			req:  &pb.ReleaseHoldRequest{Id: hold.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(hold.ID)).Times(1).Return(hold, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					ReleaseHoldTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReleaseHoldTxResult{}, db.ErrHoldNotActive)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReleaseHoldResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			rsp, err := server.ReleaseHold(newContextWithMethod(ctx, "/pb.VaultguardAPI/ReleaseHold"), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
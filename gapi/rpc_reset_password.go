@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/OmSingh2003/nimbus/val"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
+	violations := validateResetPasswordRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	hashedPassword, err := util.HashPassword(req.GetPassword())
+	if err != nil {
+		return nil, InvalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation("password", err),
+		})
+	}
+
+	txResult, err := server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		ResetID:        req.GetResetId(),
+		SecretHash:     util.HashSecretCode(req.GetSecretCode()),
+		HashedPassword: hashedPassword,
+		Audit:          server.auditContext(ctx, ""),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.FailedPrecondition, "password reset link is invalid, used or expired")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reset password: %s", err)
+	}
+	server.passwordChanges.Invalidate(txResult.User.Username)
+
+	// The new password is already set, so a lockout left over from guessing the
+	// old one shouldn't fail the request
+	if err := server.clearLoginFailures(ctx, txResult.User.Username); err != nil {
+		log.Error().Err(err).Str("username", txResult.User.Username).Msg("failed to clear login failures after password reset")
+	}
+
+	return &pb.ResetPasswordResponse{}, nil
+}
+
+func validateResetPasswordRequest(req *pb.ResetPasswordRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetResetId()); err != nil {
+		violations = append(violations, fieldViolation("reset_id", err))
+	}
+
+	if err := val.ValidateSecretCode(req.GetSecretCode()); err != nil {
+		violations = append(violations, fieldViolation("secret_code", err))
+	}
+
+	if err := val.ValidatePassword(req.GetPassword()); err != nil {
+		violations = append(violations, fieldViolation("password", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,131 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestResetPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	secretCode, err := util.NewSecretCode()
+	require.NoError(t, err)
+	newPassword := "NewSecret123!"
+
+	testCases := []struct {
+		name          string
+		req           *pb.ResetPasswordRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ResetPasswordResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.ResetPasswordRequest{ResetId: 1, SecretCode: secretCode, Password: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ResetPasswordTxParams) (db.ResetPasswordTxResult, error) {
+						require.Equal(t, int64(1), arg.ResetID)
+						require.Equal(t, util.HashSecretCode(secretCode), arg.SecretHash)
+						require.NoError(t, util.CheckPassword(newPassword, arg.HashedPassword))
+						return db.ResetPasswordTxResult{User: user, RevokedSessions: 2}, nil
+					})
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Eq(db.DeleteLoginFailureParams{
+						Scope: loginScopeUsername,
+						Key:   user.Username,
+					})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ResetPasswordResponse, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, rsp)
+			},
+		},
+		{
+			name: "InvalidOrUsedLink",
+			req:  &pb.ResetPasswordRequest{ResetId: 1, SecretCode: secretCode, Password: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, sql.ErrNoRows)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ResetPasswordResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req:  &pb.ResetPasswordRequest{ResetId: 1, SecretCode: secretCode, Password: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ResetPasswordTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ResetPasswordResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "WeakPassword",
+			req:  &pb.ResetPasswordRequest{ResetId: 1, SecretCode: secretCode, Password: "weakpassword1"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ResetPasswordResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidSecretCode",
+			req:  &pb.ResetPasswordRequest{ResetId: 1, SecretCode: "short", Password: newPassword},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ResetPasswordTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ResetPasswordResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			rsp, err := server.ResetPassword(context.Background(), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) ReverseTransfer(ctx context.Context, req *pb.ReverseTransferRequest) (*pb.ReverseTransferResponse, error) {
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	violations := validateReverseTransferRequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, req.GetTransferId())
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "transfer not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get transfer: %s", err)
+	}
+
+	// Only the recipient can give the money back
+	toAccount, err := server.store.GetAccount(ctx, transfer.ToAccountID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get to account: %s", err)
+	}
+	if toAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "only the recipient can reverse a transfer")
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.GetAmount(),
+		Reason:     strings.TrimSpace(req.GetReason()),
+		Audit:      server.auditContext(ctx, authPayload.Username),
+	})
+	if err != nil {
+		return nil, reverseTransferError(err)
+	}
+
+	rsp := &pb.ReverseTransferResponse{
+		Transfer:         convertTransfer(result.Reversal.Transfer),
+		OriginalTransfer: convertTransfer(result.Original),
+	}
+	return rsp, nil
+}
+
+// reverseTransferError maps the errors of ReverseTransferTx to gRPC errors
+func reverseTransferError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Errorf(codes.NotFound, "transfer not found")
+	}
+	if errors.Is(err, db.ErrTransferNotReversible) || errors.Is(err, db.ErrReversalExceedsTransfer) {
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	}
+	if errors.Is(err, db.ErrReversalTooSmall) {
+		return status.Errorf(codes.InvalidArgument, "%s", err)
+	}
+	if errors.Is(err, db.ErrInsufficientFunds) {
+		return status.Errorf(codes.FailedPrecondition, "recipient account has insufficient funds")
+	}
+	if errors.Is(err, db.ErrAccountFrozen) || errors.Is(err, db.ErrAccountClosed) {
+		return status.Errorf(codes.FailedPrecondition, "%s", err)
+	}
+	return status.Errorf(codes.Internal, "failed to reverse transfer: %s", err)
+}
+
+func validateReverseTransferRequest(req *pb.ReverseTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(req.GetTransferId()); err != nil {
+		violations = append(violations, fieldViolation("transfer_id", err))
+	}
+
+	// 0 reverses whatever is left
+	if req.GetAmount() != 0 {
+		if err := val.ValidateAmount(req.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+
+	if req.GetReason() != "" {
+		if err := val.ValidateReason(req.GetReason()); err != nil {
+			violations = append(violations, fieldViolation("reason", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,198 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReverseTransferAPI(t *testing.T) {
+	sender, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+	toAccount := db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    recipient.Username,
+		Currency: util.USD,
+	}
+	transfer := randomTransfer(util.RandomInt(1001, 2000), toAccount.ID)
+
+	reversalResult := func(arg db.ReverseTransferTxParams) db.ReverseTransferTxResult {
+		original := transfer
+		original.ReversedAmount = arg.Amount
+		return db.ReverseTransferTxResult{
+			Original: original,
+			Reversal: db.TransferTxResult{
+				Transfer: db.Transfer{
+					ID:                 transfer.ID + 1,
+					FromAccountID:      transfer.ToAccountID,
+					ToAccountID:        transfer.FromAccountID,
+					Amount:             arg.Amount,
+					Currency:           util.USD,
+					ToAmount:           arg.Amount,
+					ToCurrency:         util.USD,
+					ExchangeRate:       "1",
+					ReversesTransferID: sql.NullInt64{Int64: transfer.ID, Valid: true},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.ReverseTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, rsp *pb.ReverseTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.ReverseTransferRequest{
+				TransferId: transfer.ID,
+				Amount:     1,
+				Reason:     "  returned item  ",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.ReverseTransferTxParams) (db.ReverseTransferTxResult, error) {
+						require.Equal(t, transfer.ID, arg.TransferID)
+						require.Equal(t, int64(1), arg.Amount)
+						require.False(t, arg.Force)
+						require.Equal(t, "returned item", arg.Reason)
+						require.Equal(t, recipient.Username, arg.Audit.Actor)
+						return reversalResult(arg), nil
+					})
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, rsp.GetTransfer().GetReversesTransferId())
+				require.Equal(t, transfer.FromAccountID, rsp.GetTransfer().GetToAccountId())
+				require.Equal(t, int64(1), rsp.GetOriginalTransfer().GetReversedAmount())
+			},
+		},
+		{
+			name: "NotRecipient",
+			req:  &pb.ReverseTransferRequest{TransferId: transfer.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, sender.Username, sender.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "TransferNotFound",
+			req:  &pb.ReverseTransferRequest{TransferId: transfer.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(db.Transfer{}, sql.ErrNoRows)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "ExceedsTransfer",
+			req:  &pb.ReverseTransferRequest{TransferId: transfer.ID, Amount: transfer.ToAmount + 1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrReversalExceedsTransfer)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req:  &pb.ReverseTransferRequest{TransferId: transfer.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Eq(transfer.ID)).Times(1).Return(transfer, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReverseTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "NegativeAmount",
+			req:  &pb.ReverseTransferRequest{TransferId: transfer.ID, Amount: -1},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					ReverseTransferTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, recipient.Username, recipient.Role, time.Minute)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ReverseTransferResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			ctx := tc.buildContext(t, server.tokenMaker)
+			rsp, err := server.ReverseTransfer(newContextWithMethod(ctx, "/pb.VaultguardAPI/ReverseTransfer"), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, InvalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("session_id", err)})
+	}
+
+	// Only the owner's sessions match, so another user's session looks the same as a missing one
+	_, err = server.store.BlockSession(ctx, db.BlockSessionParams{
+		ID:       sessionID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+
+	return &pb.RevokeSessionResponse{}, nil
+}
+
+func (server *Server) RevokeOtherSessions(ctx context.Context, req *pb.RevokeOtherSessionsRequest) (*pb.RevokeOtherSessionsResponse, error) {
+	authPayload, err := server.getAuthPayload(ctx)
+	if err != nil {
+		return nil, authError(err)
+	}
+
+	sessionID, err := uuid.Parse(req.GetSessionId())
+	if err != nil {
+		return nil, InvalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("session_id", err)})
+	}
+
+	// A mistyped or stale session ID would otherwise revoke every session, the current one included
+	session, err := server.store.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+	if session.Username != authPayload.Username {
+		return nil, status.Errorf(codes.NotFound, "session not found")
+	}
+	if session.IsBoolean {
+		return nil, status.Errorf(codes.FailedPrecondition, "session is blocked")
+	}
+
+	revoked, err := server.store.BlockOtherSessions(ctx, db.BlockOtherSessionsParams{
+		Username: authPayload.Username,
+		ID:       sessionID,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to block sessions: %s", err)
+	}
+
+	rsp := &pb.RevokeOtherSessionsResponse{
+		RevokedCount: revoked,
+	}
+	return rsp, nil
+}
@@ -3,12 +3,16 @@ package gapi
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	db "github.com/OmSingh2003/nimbus/db/sqlc"
 	"github.com/OmSingh2003/nimbus/pb"
 	"github.com/OmSingh2003/nimbus/util"
 	"github.com/OmSingh2003/nimbus/val"
+	"github.com/OmSingh2003/nimbus/worker"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -23,7 +27,7 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 	// Get the authenticated user from the token
 	authPayload, err := server.getAuthPayload(ctx)
 	if err != nil {
-		return nil, err
+		return nil, authError(err)
 	}
 
 	// Ensure user can only update their own profile
@@ -60,25 +64,87 @@ func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		}
 	}
 
-	// Only update email if provided
+	// A new email isn't applied here: it's staged until the new address is verified.
+	// An address another user has is staged all the same, so the response doesn't
+	// reveal which emails are registered; ConfirmEmailChangeTx turns it down.
+	var pendingEmail, oldEmail string
 	if req.Email != nil {
-		arg.Email = sql.NullString{
-			String: req.GetEmail(),
-			Valid:  true,
+		user, err := server.store.GetUser(ctx, req.GetUsername())
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+		}
+		if req.GetEmail() != user.Email {
+			pendingEmail = req.GetEmail()
+			oldEmail = user.Email
 		}
 	}
 
-	user, err := server.store.UpdateUser(ctx, arg)
+	// Users can make their own transfers stricter, but not looser than the default
+	if req.TransferConfirmationThreshold != nil {
+		if req.GetTransferConfirmationThreshold() > server.config.TransferConfirmationThreshold {
+			return nil, InvalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+				fieldViolation("transfer_confirmation_threshold", fmt.Errorf("must not exceed %d", server.config.TransferConfirmationThreshold)),
+			})
+		}
+		arg.TransferConfirmationThreshold = sql.NullInt64{
+			Int64: req.GetTransferConfirmationThreshold(),
+			Valid: true,
+		}
+	}
+
+	txResult, err := server.store.UpdateUserTx(ctx, db.UpdateUserTxParams{
+		UpdateUserParams: arg,
+		Audit:            server.auditContext(ctx, authPayload.Username),
+	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to update user: %s", err)
 	}
+	if arg.HashedPassword.Valid {
+		server.passwordChanges.Invalidate(txResult.User.Username)
+	}
+
+	if pendingEmail != "" {
+		err = server.stageEmailChange(ctx, txResult.User.Username, oldEmail, pendingEmail)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	rsp := &pb.UpdateUserResponse{
-		User: convertUser(user),
+		User:         convertUser(txResult.User),
+		PendingEmail: pendingEmail,
 	}
 	return rsp, nil
 }
 
+// stageEmailChange sends a verification link to the new address and lets the old
+// address know about the change. The notice is best effort; without the
+// verification the change can't go ahead.
+func (server *Server) stageEmailChange(ctx context.Context, username string, oldEmail string, newEmail string) error {
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.Queue(worker.QueueCritical),
+	}
+
+	err := server.taskDistributor.DistributeTaskSendEmailChangeVerification(ctx, &worker.PayloadSendEmailChangeVerification{
+		Username: username,
+		NewEmail: newEmail,
+	}, opts...)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to distribute email change verification task: %s", err)
+	}
+
+	err = server.taskDistributor.DistributeTaskSendEmailChangeNotice(ctx, &worker.PayloadSendEmailChangeNotice{
+		Username: username,
+		OldEmail: oldEmail,
+		NewEmail: newEmail,
+	}, opts...)
+	if err != nil {
+		log.Error().Err(err).Str("username", username).Msg("failed to distribute email change notice task")
+	}
+	return nil
+}
+
 func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
 	if err := val.ValidateUsername(req.GetUsername()); err != nil {
 		violations = append(violations, fieldViolation("username", err))
@@ -103,6 +169,12 @@ func validateUpdateUserRequest(req *pb.UpdateUserRequest) (violations []*errdeta
 		}
 	}
 
+	if req.TransferConfirmationThreshold != nil {
+		if err := val.ValidateAmount(req.GetTransferConfirmationThreshold()); err != nil {
+			violations = append(violations, fieldViolation("transfer_confirmation_threshold", err))
+		}
+	}
+
 	return violations
 }
 
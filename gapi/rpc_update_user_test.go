@@ -0,0 +1,203 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/worker"
+	mockwk "github.com/OmSingh2003/nimbus/worker/mock"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpdateUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	newName := "New Name"
+	newEmail := "new_" + user.Email
+
+	testCases := []struct {
+		name          string
+		req           *pb.UpdateUserRequest
+		buildStubs    func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor)
+		checkResponse func(t *testing.T, rsp *pb.UpdateUserResponse, err error)
+	}{
+		{
+			name: "FullName",
+			req:  &pb.UpdateUserRequest{Username: user.Username, FullName: &newName},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+						require.Equal(t, newName, arg.FullName.String)
+						require.False(t, arg.Email.Valid)
+						updated := user
+						updated.FullName = newName
+						return db.UpdateUserTxResult{User: updated}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, newName, rsp.GetUser().GetFullName())
+				require.Empty(t, rsp.GetPendingEmail())
+			},
+		},
+		{
+			name: "EmailChangeIsStaged",
+			req:  &pb.UpdateUserRequest{Username: user.Username, Email: &newEmail},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+						require.False(t, arg.Email.Valid)
+						return db.UpdateUserTxResult{User: user}, nil
+					})
+				taskDistributor.EXPECT().
+					DistributeTaskSendEmailChangeVerification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, payload *worker.PayloadSendEmailChangeVerification, opts ...asynq.Option) error {
+						require.Equal(t, user.Username, payload.Username)
+						require.Equal(t, newEmail, payload.NewEmail)
+						return nil
+					})
+				taskDistributor.EXPECT().
+					DistributeTaskSendEmailChangeNotice(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, payload *worker.PayloadSendEmailChangeNotice, opts ...asynq.Option) error {
+						require.Equal(t, user.Email, payload.OldEmail)
+						require.Equal(t, newEmail, payload.NewEmail)
+						return nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Email, rsp.GetUser().GetEmail())
+				require.Equal(t, newEmail, rsp.GetPendingEmail())
+			},
+		},
+		{
+			name: "SameEmail",
+			req:  &pb.UpdateUserRequest{Username: user.Username, Email: &user.Email},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserTxResult{User: user}, nil)
+				taskDistributor.EXPECT().
+					DistributeTaskSendEmailChangeVerification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Empty(t, rsp.GetPendingEmail())
+			},
+		},
+		{
+			// Whether another user has the email isn't checked until it is confirmed,
+			// so the response is the same either way
+			name: "EmailInUseIsStaged",
+			req:  &pb.UpdateUserRequest{Username: user.Username, Email: &newEmail},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserByEmail(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserTxResult{User: user}, nil)
+				taskDistributor.EXPECT().
+					DistributeTaskSendEmailChangeVerification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				taskDistributor.EXPECT().
+					DistributeTaskSendEmailChangeNotice(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, newEmail, rsp.GetPendingEmail())
+			},
+		},
+		{
+			name: "DistributeError",
+			req:  &pb.UpdateUserRequest{Username: user.Username, Email: &newEmail},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateUserTxResult{User: user}, nil)
+				taskDistributor.EXPECT().
+					DistributeTaskSendEmailChangeVerification(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(errors.New("redis unavailable"))
+				taskDistributor.EXPECT().
+					DistributeTaskSendEmailChangeNotice(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+		{
+			name: "OtherUser",
+			req:  &pb.UpdateUserRequest{Username: "other_user", FullName: &newName},
+			buildStubs: func(store *mockdb.MockStore, taskDistributor *mockwk.MockTaskDistributor) {
+				store.EXPECT().
+					UpdateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UpdateUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			taskCtrl := gomock.NewController(t)
+			defer taskCtrl.Finish()
+			taskDistributor := mockwk.NewMockTaskDistributor(taskCtrl)
+
+			tc.buildStubs(store, taskDistributor)
+			server := newTestServer(t, store, taskDistributor)
+
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, user.Role, time.Minute)
+			rsp, err := server.UpdateUser(newContextWithMethod(ctx, "/pb.VaultguardAPI/UpdateUser"), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/OmSingh2003/nimbus/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Kinds of second factor, as recorded in the login audit event
+const (
+	mfaMethodTOTP         = "totp"
+	mfaMethodRecoveryCode = "recovery_code"
+)
+
+var errInvalidMFACode = status.Error(codes.Unauthenticated, "invalid two-factor code")
+
+// mfaChallenge answers a correct password for a user with two-factor
+// authentication with an MFA token instead of a session
+func (server *Server) mfaChallenge(user db.User) (*pb.LoginUserResponse, error) {
+	mfaToken, mfaPayload, err := server.tokenMaker.CreateToken(
+		user.Username,
+		util.MFAPendingRole,
+		server.config.MFATokenDuration,
+	)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create mfa token: %s", err)
+	}
+
+	rsp := &pb.LoginUserResponse{
+		MfaRequired:       true,
+		MfaToken:          mfaToken,
+		MfaTokenExpiresAt: timestamppb.New(mfaPayload.ExpiredAt),
+	}
+	return rsp, nil
+}
+
+func (server *Server) VerifyMFA(ctx context.Context, req *pb.VerifyMFARequest) (*pb.LoginUserResponse, error) {
+	violations := validateVerifyMFARequest(req)
+	if violations != nil {
+		return nil, InvalidArgumentError(violations)
+	}
+
+	mfaPayload, err := server.tokenMaker.VerifyToken(req.GetMfaToken())
+	if err != nil || mfaPayload.Role != util.MFAPendingRole {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid mfa token")
+	}
+
+	// Wrong codes count as failed logins, so guessing codes backs off and locks out too
+	reservation, err := server.reserveLoginAttempt(ctx, server.loginKeys(ctx, mfaPayload.Username))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUser(ctx, mfaPayload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	mfa, err := server.store.GetUserMFA(ctx, user.Username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor settings: %s", err)
+	}
+	if err != nil || !mfa.EnabledAt.Valid {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+
+	method, ok, err := server.useMFACode(ctx, mfa, req.GetCode())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		_, err = server.store.RecordAuditEventTx(ctx, db.AuditEventParams{
+			Username:  user.Username,
+			EventType: db.AuditLoginFailed,
+			Details:   map[string]any{"reason": "incorrect_mfa_code", "mfa_method": method},
+			Audit:     server.auditContext(ctx, user.Username),
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to record failed login: %s", err)
+		}
+		server.loginAttemptFailed(ctx, reservation, &user)
+		return nil, errInvalidMFACode
+	}
+
+	if err := server.releaseLoginAttempt(ctx, reservation); err != nil {
+		return nil, err
+	}
+	if err := server.clearLoginFailures(ctx, user.Username); err != nil {
+		return nil, err
+	}
+
+	return server.createLoginSession(ctx, user, map[string]any{"mfa_method": method})
+}
+
+// useMFACode checks a TOTP code or recovery code and uses it up, so neither can
+// be used a second time. It returns which kind of code it was.
+func (server *Server) useMFACode(ctx context.Context, mfa db.UserMfa, code string) (string, bool, error) {
+	code = strings.TrimSpace(code)
+
+	if val.ValidateTOTPCode(code) == nil {
+		step, ok := util.ValidateTOTP(mfa.TotpSecret, code, time.Now())
+		if !ok {
+			return mfaMethodTOTP, false, nil
+		}
+		_, err := server.store.UseTOTPStep(ctx, db.UseTOTPStepParams{
+			Username:     mfa.Username,
+			LastUsedStep: step,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return mfaMethodTOTP, false, nil
+			}
+			return mfaMethodTOTP, false, status.Errorf(codes.Internal, "failed to use two-factor code: %s", err)
+		}
+		return mfaMethodTOTP, true, nil
+	}
+
+	_, err := server.store.UseMFARecoveryCode(ctx, db.UseMFARecoveryCodeParams{
+		Username: mfa.Username,
+		CodeHash: util.HashRecoveryCode(code),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mfaMethodRecoveryCode, false, nil
+		}
+		return mfaMethodRecoveryCode, false, status.Errorf(codes.Internal, "failed to use recovery code: %s", err)
+	}
+	return mfaMethodRecoveryCode, true, nil
+}
+
+func validateVerifyMFARequest(req *pb.VerifyMFARequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetMfaToken() == "" {
+		violations = append(violations, fieldViolation("mfa_token", errors.New("must not be empty")))
+	}
+
+	if err := val.ValidateMFACode(req.GetCode()); err != nil {
+		violations = append(violations, fieldViolation("code", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,304 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mockdb "github.com/OmSingh2003/nimbus/db/mockdb"
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/pb"
+	"github.com/OmSingh2003/nimbus/token"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestVerifyMFAAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.IsEmailVerified = true
+
+	secret, err := util.NewTOTPSecret()
+	require.NoError(t, err)
+	mfa := db.UserMfa{
+		Username:   user.Username,
+		TotpSecret: secret,
+		EnabledAt:  sql.NullTime{Time: time.Now(), Valid: true},
+	}
+
+	code, err := util.TOTPCode(secret, util.TOTPStep(time.Now()))
+	require.NoError(t, err)
+	wrongCode := "000000"
+	if wrongCode == code {
+		wrongCode = "111111"
+	}
+
+	usernameKey := db.LoginFailureKey{Scope: loginScopeUsername, Key: user.Username}
+
+	testCases := []struct {
+		name          string
+		role          string
+		code          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.LoginUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			role: util.MFAPendingRole,
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reservedLoginAttempt(1, usernameKey), nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(mfa, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UseTOTPStepParams) (db.UserMfa, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.InDelta(t, util.TOTPStep(time.Now()), arg.LastUsedStep, 1)
+						return mfa, nil
+					})
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, Username: arg.Username}, nil
+					})
+				store.EXPECT().
+					RecordAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.AuditEventParams) (db.AuditEvent, error) {
+						require.Equal(t, db.AuditLogin, arg.EventType)
+						require.Equal(t, mfaMethodTOTP, arg.Details["mfa_method"])
+						return db.AuditEvent{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.GetAccessToken())
+				require.NotEmpty(t, rsp.GetRefreshToken())
+				require.Equal(t, user.Username, rsp.GetUser().GetUsername())
+			},
+		},
+		{
+			name: "RecoveryCode",
+			role: util.MFAPendingRole,
+			code: "ABCDE-FGHIJ",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reservedLoginAttempt(1, usernameKey), nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(mfa, nil)
+				store.EXPECT().
+					UseMFARecoveryCode(gomock.Any(), gomock.Eq(db.UseMFARecoveryCodeParams{
+						Username: user.Username,
+						CodeHash: util.HashRecoveryCode("abcde-fghij"),
+					})).
+					Times(1).
+					Return(db.MfaRecoveryCode{}, nil)
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					DeleteLoginFailure(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+						return db.Session{ID: arg.ID, Username: arg.Username}, nil
+					})
+				store.EXPECT().
+					RecordAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.AuditEventParams) (db.AuditEvent, error) {
+						require.Equal(t, mfaMethodRecoveryCode, arg.Details["mfa_method"])
+						return db.AuditEvent{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.GetAccessToken())
+			},
+		},
+		{
+			name: "IncorrectCode",
+			role: util.MFAPendingRole,
+			code: wrongCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reservedLoginAttempt(1, usernameKey), nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(mfa, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					RecordAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.AuditEventParams) (db.AuditEvent, error) {
+						require.Equal(t, db.AuditLoginFailed, arg.EventType)
+						return db.AuditEvent{}, nil
+					})
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "ReusedCode",
+			role: util.MFAPendingRole,
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(reservedLoginAttempt(1, usernameKey), nil)
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(user, nil)
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(mfa, nil)
+				store.EXPECT().
+					UseTOTPStep(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UserMfa{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordAuditEventTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditEvent{}, nil)
+				store.EXPECT().
+					ReleaseLoginAttempt(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "AccessTokenInsteadOfMFAToken",
+			role: util.DepositorRole,
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "Locked",
+			role: util.MFAPendingRole,
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ReserveLoginAttemptTxResult{}, db.ErrLoginLocked)
+				store.EXPECT().
+					GetUserMFA(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.ResourceExhausted, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidCode",
+			role: util.MFAPendingRole,
+			code: "123",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ReserveLoginAttemptTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.LoginUserResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			storeCtrl := gomock.NewController(t)
+			defer storeCtrl.Finish()
+			store := mockdb.NewMockStore(storeCtrl)
+
+			tc.buildStubs(store)
+			server := newTestServer(t, store, nil)
+
+			mfaToken := createMFAToken(t, server.tokenMaker, user.Username, tc.role)
+			req := &pb.VerifyMFARequest{MfaToken: mfaToken, Code: tc.code}
+			rsp, err := server.VerifyMFA(context.Background(), req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func createMFAToken(t *testing.T, tokenMaker token.Maker, username string, role string) string {
+	mfaToken, _, err := tokenMaker.CreateToken(username, role, time.Minute)
+	require.NoError(t, err)
+	return mfaToken
+}
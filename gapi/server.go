@@ -1,7 +1,9 @@
 package gapi
 
 import (
+
 	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/fx"
 	"github.com/OmSingh2003/nimbus/pb"
 	"github.com/OmSingh2003/nimbus/token"
 	"github.com/OmSingh2003/nimbus/util"
@@ -15,6 +17,8 @@ type Server struct {
 	store          db.Store
 	tokenMaker     token.Maker
 	taskDistributor worker.TaskDistributor
+	rateProvider    fx.RateProvider
+	passwordChanges *token.PasswordChangeCache
 }
 
 // NewServer creates a new gRPC server
@@ -24,11 +28,22 @@ func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDi
 		return nil, err
 	}
 
+	// Rates come from the fx_rates table unless a rates file is configured
+	var rateProvider fx.RateProvider = fx.NewStoreProvider(store)
+	if config.FXRatesFile != "" {
+		rateProvider, err = fx.NewFileProvider(config.FXRatesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	server := &Server{
 		config:          config,
 		store:           store,
 		tokenMaker:      tokenMaker,
 		taskDistributor: taskDistributor,
+		rateProvider:    rateProvider,
+		passwordChanges: token.NewPasswordChangeCache(db.PasswordChangedAt(store), config.PasswordChangeCacheDuration),
 	}
 
 	return server, nil
@@ -70,24 +70,38 @@ func main() {
 	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
 
 	go runTaskProcessor(&config, redisOpt, store)
-	// Run both HTTP Gateway and gRPC servers concurrently
-	go runGatewayServer(config, store, taskDistributor)
-	runGrpcServer(config, store, taskDistributor)
-}
+	go runTaskScheduler(&config, redisOpt)
 
-func runGatewayServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) {
+	// Both servers share one gapi.Server, so state such as the password change cache
+	// is the same whichever of them a request comes through
 	server, err := gapi.NewServer(config, store, taskDistributor)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Cannot create gRPC server")
 	}
 
-	grpcMux := runtime.NewServeMux()
+	// Run both HTTP Gateway and gRPC servers concurrently
+	go runGatewayServer(config, server)
+	runGrpcServer(config, server)
+}
+
+func runGatewayServer(config util.Config, server *gapi.Server) {
+	grpcMux := runtime.NewServeMux(
+		runtime.WithOutgoingHeaderMatcher(gapi.OutgoingHeaderMatcher),
+	)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	err = pb.RegisterVaultguardAPIHandlerServer(ctx, grpcMux, server)
+	err := pb.RegisterVaultguardAPIHandlerServer(ctx, grpcMux, server)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot register handle server")
 	}
+	err = gapi.RegisterStatementHandler(grpcMux, server)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot register statement handler")
+	}
+	err = pb.RegisterAdminServiceHandlerServer(ctx, grpcMux, gapi.NewAdminServer(server))
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot register admin handle server")
+	}
 	mux := http.NewServeMux()
 	// Add CORS middleware
 	corsHandler := func(h http.Handler) http.Handler {
@@ -95,6 +109,7 @@ func runGatewayServer(config util.Config, store db.Store, taskDistributor worker
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, Authorization")
+			w.Header().Set("Access-Control-Expose-Headers", "Content-Disposition")
 			if r.Method == "OPTIONS" {
 				return
 			}
@@ -131,11 +146,7 @@ func runGatewayServer(config util.Config, store db.Store, taskDistributor worker
 	}
 }
 
-func runGrpcServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) {
-	server, err := gapi.NewServer(config, store, taskDistributor)
-	if err != nil {
-		log.Fatal().Err(err).Msg("Cannot create gRPC server")
-	}
+func runGrpcServer(config util.Config, server *gapi.Server) {
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
 			gapi.GrpcLogger,
@@ -143,6 +154,7 @@ func runGrpcServer(config util.Config, store db.Store, taskDistributor worker.Ta
 		),
 	)
 	pb.RegisterVaultguardAPIServer(grpcServer, server)
+	pb.RegisterAdminServiceServer(grpcServer, gapi.NewAdminServer(server))
 	reflection.Register(grpcServer)
 
 	listener, err := net.Listen("tcp", config.GRPCServerAddress)
@@ -179,3 +191,12 @@ func runTaskProcessor(config *util.Config, redisOpt asynq.RedisClientOpt, store
 		log.Fatal().Err(err).Msg("failed to start task processor")
 	}
 }
+
+func runTaskScheduler(config *util.Config, redisOpt asynq.RedisClientOpt) {
+	taskScheduler := worker.NewRedisTaskScheduler(redisOpt, config)
+	log.Info().Msg("start task scheduler")
+	err := taskScheduler.Start()
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to start task scheduler")
+	}
+}
@@ -0,0 +1,118 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: service_admin.proto
+
+package pb
+
+import (
+	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_service_admin_proto protoreflect.FileDescriptor
+
+const file_service_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x13service_admin.proto\x12\x02pb\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\x1a\raccount.proto\x1a\vadmin.proto\x1a\ventry.proto\x1a\x0etransfer.proto2\xe0\x15\n" +
+	"\fAdminService\x12\xb5\x01\n" +
+	"\vSearchUsers\x12\x16.pb.SearchUsersRequest\x1a\x17.pb.SearchUsersResponse\"u\x92A[\x12\fSearch users\x1aKSearches users by part of their username or email, with pagination support.\x82\xd3\xe4\x93\x02\x11\x12\x0f/v1/admin/users\x12\x9f\x01\n" +
+	"\n" +
+	"GetAccount\x12\x15.pb.GetAccountRequest\x1a\x16.pb.GetAccountResponse\"b\x92A@\x12\x0fGet any account\x1a-Retrieves any account by ID, whoever owns it.\x82\xd3\xe4\x93\x02\x19\x12\x17/v1/admin/accounts/{id}\x12\xb0\x02\n" +
+	"\x12ListAccountEntries\x12\x1d.pb.ListAccountEntriesRequest\x1a\x1e.pb.ListAccountEntriesResponse\"\xda\x01\x92A\xa7\x01\x12\x1bList entries of any account\x1a\x87\x01Lists the entries of any account over a date range, including balance adjustments, with the opening and closing balances of the period.\x82\xd3\xe4\x93\x02)\x12'/v1/admin/accounts/{account_id}/entries\x12\xda\x01\n" +
+	"\rFreezeAccount\x12\x18.pb.FreezeAccountRequest\x1a\x19.pb.FreezeAccountResponse\"\x93\x01\x92Ag\x12\x0eFreeze account\x1aUFreezes an account so it can neither send nor receive transfers until it is unfrozen.\x82\xd3\xe4\x93\x02#:\x01*\"\x1e/v1/admin/accounts/{id}/freeze\x12\xd5\x01\n" +
+	"\x0fUnfreezeAccount\x12\x1a.pb.UnfreezeAccountRequest\x1a\x1b.pb.UnfreezeAccountResponse\"\x88\x01\x92AZ\x12\x10Unfreeze account\x1aFUnfreezes a frozen account so it can send and receive transfers again.\x82\xd3\xe4\x93\x02%:\x01*\" /v1/admin/accounts/{id}/unfreeze\x12\xb2\x02\n" +
+	"\x11SetOverdraftLimit\x12\x1c.pb.SetOverdraftLimitRequest\x1a\x1d.pb.SetOverdraftLimitResponse\"\xdf\x01\x92A\xa9\x01\x12\x13Set overdraft limit\x1a\x91\x01Sets how far below zero an account's balance may go. Lowering the limit under what the account is already overdrawn by only stops further debits.\x82\xd3\xe4\x93\x02,:\x01*\"'/v1/admin/accounts/{id}/overdraft_limit\x12\xac\x02\n" +
+	"\x14AdjustAccountBalance\x12\x1f.pb.AdjustAccountBalanceRequest\x1a .pb.AdjustAccountBalanceResponse\"\xd0\x01\x92A\x96\x01\x12\x16Adjust account balance\x1a|Posts a compensating entry to correct an account balance. A reason is required and is recorded with the admin who posted it.\x82\xd3\xe4\x93\x020:\x01*\"+/v1/admin/accounts/{account_id}/adjustments\x12\xfb\x02\n" +
+	"\x17RunLedgerReconciliation\x12\".pb.RunLedgerReconciliationRequest\x1a#.pb.RunLedgerReconciliationResponse\"\x96\x02\x92A\xed\x01\x12\x19Run ledger reconciliation\x1a\xcf\x01Queues a reconciliation run now instead of waiting for the next scheduled one. It checks the ledger rows added since the previous run, records the discrepancies it finds and emails them to the alert address.\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/admin/ledger/reconcile\x12\x9c\x02\n" +
+	"\x0fGetLedgerReport\x12\x1a.pb.GetLedgerReportRequest\x1a\x1b.pb.GetLedgerReportResponse\"\xcf\x01\x92A\xac\x01\x12\x11Get ledger report\x1a\x96\x01Runs the reconciliation checks over the transfers, entries and accounts created in a time window and returns the discrepancies without recording them.\x82\xd3\xe4\x93\x02\x19\x12\x17/v1/admin/ledger/report\x12\x8c\x03\n" +
+	"\x0fReverseTransfer\x12\x1f.pb.AdminReverseTransferRequest\x1a\x1b.pb.ReverseTransferResponse\"\xba\x02\x92A\x82\x02\x12\x14Reverse any transfer\x1a\xe9\x01Reverses all or part of any transfer with a compensating transfer from the recipient back to the sender. With force set the reversal goes through even if an account is frozen or the recipient is overdrawn by it. A reason is required.\x82\xd3\xe4\x93\x02.:\x01*\")/v1/admin/transfers/{transfer_id}/reverseB*Z(github.com/OmSingh2003/vaultguard-api/pbb\x06proto3"
+
+var file_service_admin_proto_goTypes = []any{
+	(*SearchUsersRequest)(nil),              // 0: pb.SearchUsersRequest
+	(*GetAccountRequest)(nil),               // 1: pb.GetAccountRequest
+	(*ListAccountEntriesRequest)(nil),       // 2: pb.ListAccountEntriesRequest
+	(*FreezeAccountRequest)(nil),            // 3: pb.FreezeAccountRequest
+	(*UnfreezeAccountRequest)(nil),          // 4: pb.UnfreezeAccountRequest
+	(*SetOverdraftLimitRequest)(nil),        // 5: pb.SetOverdraftLimitRequest
+	(*AdjustAccountBalanceRequest)(nil),     // 6: pb.AdjustAccountBalanceRequest
+	(*RunLedgerReconciliationRequest)(nil),  // 7: pb.RunLedgerReconciliationRequest
+	(*GetLedgerReportRequest)(nil),          // 8: pb.GetLedgerReportRequest
+	(*AdminReverseTransferRequest)(nil),     // 9: pb.AdminReverseTransferRequest
+	(*SearchUsersResponse)(nil),             // 10: pb.SearchUsersResponse
+	(*GetAccountResponse)(nil),              // 11: pb.GetAccountResponse
+	(*ListAccountEntriesResponse)(nil),      // 12: pb.ListAccountEntriesResponse
+	(*FreezeAccountResponse)(nil),           // 13: pb.FreezeAccountResponse
+	(*UnfreezeAccountResponse)(nil),         // 14: pb.UnfreezeAccountResponse
+	(*SetOverdraftLimitResponse)(nil),       // 15: pb.SetOverdraftLimitResponse
+	(*AdjustAccountBalanceResponse)(nil),    // 16: pb.AdjustAccountBalanceResponse
+	(*RunLedgerReconciliationResponse)(nil), // 17: pb.RunLedgerReconciliationResponse
+	(*GetLedgerReportResponse)(nil),         // 18: pb.GetLedgerReportResponse
+	(*ReverseTransferResponse)(nil),         // 19: pb.ReverseTransferResponse
+}
+var file_service_admin_proto_depIdxs = []int32{
+	0,  // 0: pb.AdminService.SearchUsers:input_type -> pb.SearchUsersRequest
+	1,  // 1: pb.AdminService.GetAccount:input_type -> pb.GetAccountRequest
+	2,  // 2: pb.AdminService.ListAccountEntries:input_type -> pb.ListAccountEntriesRequest
+	3,  // 3: pb.AdminService.FreezeAccount:input_type -> pb.FreezeAccountRequest
+	4,  // 4: pb.AdminService.UnfreezeAccount:input_type -> pb.UnfreezeAccountRequest
+	5,  // 5: pb.AdminService.SetOverdraftLimit:input_type -> pb.SetOverdraftLimitRequest
+	6,  // 6: pb.AdminService.AdjustAccountBalance:input_type -> pb.AdjustAccountBalanceRequest
+	7,  // 7: pb.AdminService.RunLedgerReconciliation:input_type -> pb.RunLedgerReconciliationRequest
+	8,  // 8: pb.AdminService.GetLedgerReport:input_type -> pb.GetLedgerReportRequest
+	9,  // 9: pb.AdminService.ReverseTransfer:input_type -> pb.AdminReverseTransferRequest
+	10, // 10: pb.AdminService.SearchUsers:output_type -> pb.SearchUsersResponse
+	11, // 11: pb.AdminService.GetAccount:output_type -> pb.GetAccountResponse
+	12, // 12: pb.AdminService.ListAccountEntries:output_type -> pb.ListAccountEntriesResponse
+	13, // 13: pb.AdminService.FreezeAccount:output_type -> pb.FreezeAccountResponse
+	14, // 14: pb.AdminService.UnfreezeAccount:output_type -> pb.UnfreezeAccountResponse
+	15, // 15: pb.AdminService.SetOverdraftLimit:output_type -> pb.SetOverdraftLimitResponse
+	16, // 16: pb.AdminService.AdjustAccountBalance:output_type -> pb.AdjustAccountBalanceResponse
+	17, // 17: pb.AdminService.RunLedgerReconciliation:output_type -> pb.RunLedgerReconciliationResponse
+	18, // 18: pb.AdminService.GetLedgerReport:output_type -> pb.GetLedgerReportResponse
+	19, // 19: pb.AdminService.ReverseTransfer:output_type -> pb.ReverseTransferResponse
+	10, // [10:20] is the sub-list for method output_type
+	0,  // [0:10] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_service_admin_proto_init() }
+func file_service_admin_proto_init() {
+	if File_service_admin_proto != nil {
+		return
+	}
+	file_account_proto_init()
+	file_admin_proto_init()
+	file_entry_proto_init()
+	file_transfer_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_service_admin_proto_rawDesc), len(file_service_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_service_admin_proto_goTypes,
+		DependencyIndexes: file_service_admin_proto_depIdxs,
+	}.Build()
+	File_service_admin_proto = out.File
+	file_service_admin_proto_goTypes = nil
+	file_service_admin_proto_depIdxs = nil
+}
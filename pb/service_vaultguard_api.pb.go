@@ -9,6 +9,7 @@ package pb
 import (
 	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -26,65 +27,167 @@ var File_service_vaultguard_api_proto protoreflect.FileDescriptor
 
 const file_service_vaultguard_api_proto_rawDesc = "" +
 	"\n" +
-	"\x1cservice_vaultguard_api.proto\x12\x02pb\x1a\x1cgoogle/api/annotations.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\x1a\n" +
-	"user.proto\x1a\x16rpc_verify_email.proto\x1a\x0etransfer.proto\x1a\raccount.proto2\x91\x12\n" +
+	"\x1cservice_vaultguard_api.proto\x12\x02pb\x1a\x1cgoogle/api/annotations.proto\x1a\x19google/api/httpbody.proto\x1a.protoc-gen-openapiv2/options/annotations.proto\x1a\n" +
+	"user.proto\x1a\x16rpc_verify_email.proto\x1a\x0etransfer.proto\x1a\raccount.proto\x1a\ventry.proto\x1a\rsession.proto\x1a\vaudit.proto\x1a\tmfa.proto\x1a\x14password_reset.proto\x1a\x12email_change.proto\x1a\n" +
+	"hold.proto2\xc1D\n" +
 	"\rVaultguardAPI\x12\xc9\x02\n" +
 	"\n" +
-	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"\x8b\x02\x92A\xed\x01\x12\x19Create a new user account\x1a\xcf\x01Creates a new user account in the VaultGuard system. This endpoint validates user input, securely hashes passwords, and stores user credentials in the database. Returns user details upon successful creation.\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/create_user\x12\xd7\x02\n" +
+	"CreateUser\x12\x15.pb.CreateUserRequest\x1a\x16.pb.CreateUserResponse\"\x8b\x02\x92A\xed\x01\x12\x19Create a new user account\x1a\xcf\x01Creates a new user account in the VaultGuard system. This endpoint validates user input, securely hashes passwords, and stores user credentials in the database. Returns user details upon successful creation.\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/create_user\x12\xa3\x03\n" +
 	"\n" +
-	"UpdateUser\x12\x15.pb.UpdateUserRequest\x1a\x16.pb.UpdateUserResponse\"\x99\x02\x92A\xfb\x01\x12\x14Updates user account\x1a\xe2\x01Updates user account in the VaultGuard system. This endpoint validates user input, securely hashes passwords if provided, and updates user credentials in the database. Returns updated user details upon successful modification.\x82\xd3\xe4\x93\x02\x14:\x01*2\x0f/v1/update_user\x12\xcf\x02\n" +
-	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"\x94\x02\x92A\xf7\x01\x12\x17Authenticate user login\x1a\xdb\x01Authenticates a user with their credentials and returns access tokens. This endpoint validates username/email and password, generates JWT tokens for session management, and provides secure access to protected resources.\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login_user\x12\x8f\x01\n" +
-	"\vVerifyEmail\x12\x16.pb.VerifyEmailRequest\x1a\x17.pb.VerifyEmailResponse\"O\x92A4\x12\fVerify Email\x1a$Use this API to verify email address\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/verify_email\x12\x9a\x02\n" +
-	"\x0eCreateTransfer\x12\x19.pb.CreateTransferRequest\x1a\x1a.pb.CreateTransferResponse\"\xd0\x01\x92A\xb4\x01\x12\x15Create a new transfer\x1a\x9a\x01Creates a new transfer between accounts. This endpoint validates account ownership, checks balances, and executes secure money transfers between accounts.\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/transfers\x12\xfb\x01\n" +
+	"UpdateUser\x12\x15.pb.UpdateUserRequest\x1a\x16.pb.UpdateUserResponse\"\xe5\x02\x92A\xc7\x02\x12\x14Updates user account\x1a\xae\x02Updates user account in the VaultGuard system. This endpoint validates user input, securely hashes passwords if provided, and updates user credentials in the database. A new email is only applied once it's confirmed through ConfirmEmailChange. Returns updated user details upon successful modification.\x82\xd3\xe4\x93\x02\x14:\x01*2\x0f/v1/update_user\x12\xb8\x03\n" +
+	"\tLoginUser\x12\x14.pb.LoginUserRequest\x1a\x15.pb.LoginUserResponse\"\xfd\x02\x92A\xe0\x02\x12\x17Authenticate user login\x1a\xc4\x02Authenticates a user with their credentials and returns access tokens. This endpoint validates username/email and password, generates JWT tokens for session management, and provides secure access to protected resources. Users with two-factor authentication get a short-lived MFA token instead, to exchange through VerifyMFA.\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/login_user\x12\xbc\x02\n" +
+	"\tVerifyMFA\x12\x14.pb.VerifyMFARequest\x1a\x15.pb.LoginUserResponse\"\x81\x02\x92A\xe4\x01\x12\x16Verify two-factor code\x1a\xc9\x01Finishes logging in a user with two-factor authentication. Exchanges the MFA token from LoginUser and a code from their authenticator app, or one of their recovery codes, for access and refresh tokens.\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/verify_mfa\x12\xe4\x02\n" +
+	"\tEnrollMFA\x12\x14.pb.EnrollMFARequest\x1a\x15.pb.EnrollMFAResponse\"\xa9\x02\x92A\x8c\x02\x12#Enroll in two-factor authentication\x1a\xe4\x01Starts enrolling the authenticated user in TOTP two-factor authentication. Returns a new secret and its provisioning URI to show as a QR code. Two-factor authentication isn't enabled until a code is confirmed through ConfirmMFA.\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/mfa/enroll\x12\xa7\x02\n" +
+	"\n" +
+	"ConfirmMFA\x12\x15.pb.ConfirmMFARequest\x1a\x16.pb.ConfirmMFAResponse\"\xe9\x01\x92A\xcb\x01\x12!Confirm two-factor authentication\x1a\xa5\x01Enables two-factor authentication once the authenticated user enters a code from their authenticator app. Returns one-time recovery codes, which are only shown once.\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/mfa/confirm\x12\x8f\x01\n" +
+	"\vVerifyEmail\x12\x16.pb.VerifyEmailRequest\x1a\x17.pb.VerifyEmailResponse\"O\x92A4\x12\fVerify Email\x1a$Use this API to verify email address\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/verify_email\x12\xff\x01\n" +
+	"\x12ConfirmEmailChange\x12\x1d.pb.ConfirmEmailChangeRequest\x1a\x1e.pb.ConfirmEmailChangeResponse\"\xa9\x01\x92A\x85\x01\x12\x14Confirm email change\x1amApplies an email change using the link sent to the new address. Each link can be used once before it expires.\x82\xd3\xe4\x93\x02\x1a\x12\x18/v1/confirm_email_change\x12\xa9\x02\n" +
+	"\x14RequestPasswordReset\x12\x1f.pb.RequestPasswordResetRequest\x1a .pb.RequestPasswordResetResponse\"\xcd\x01\x92A\xa4\x01\x12\x16Request password reset\x1a\x89\x01Emails a one-time password reset link to the account with this email address. The response doesn't reveal whether such an account exists.\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/v1/request_password_reset\x12\x94\x02\n" +
+	"\rResetPassword\x12\x18.pb.ResetPasswordRequest\x1a\x19.pb.ResetPasswordResponse\"\xcd\x01\x92A\xac\x01\x12\x0eReset password\x1a\x99\x01Sets a new password using the link from a password reset email. Each link can be used once before it expires, and all of the user's sessions are revoked.\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/v1/reset_password\x12\xca\x03\n" +
+	"\x0eCreateTransfer\x12\x19.pb.CreateTransferRequest\x1a\x1a.pb.CreateTransferResponse\"\x80\x03\x92A\xe4\x02\x12\x15Create a new transfer\x1a\xca\x02Creates a new transfer between accounts. This endpoint validates account ownership, checks balances, and executes secure money transfers between accounts, converting between currencies when the accounts differ. Transfers over the user's confirmation threshold are held as pending transfers until confirmed through ConfirmTransfer.\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/transfers\x12\xe2\x02\n" +
+	"\x0fConfirmTransfer\x12\x1a.pb.ConfirmTransferRequest\x1a\x1b.pb.ConfirmTransferResponse\"\x95\x02\x92A\xe4\x01\x12\x10Confirm transfer\x1a\xcf\x01Makes a transfer that was held for being over the user's confirmation threshold. The user re-authenticates with their password or a two-factor code. Pending transfers expire if they aren't confirmed in time.\x82\xd3\xe4\x93\x02':\x01*\"\"/v1/pending_transfers/{id}/confirm\x12\xd2\x02\n" +
+	"\x0fReverseTransfer\x12\x1a.pb.ReverseTransferRequest\x1a\x1b.pb.ReverseTransferResponse\"\x85\x02\x92A\xd3\x01\x12\x10Reverse transfer\x1a\xbe\x01Refunds all or part of a transfer the authenticated user received, with a compensating transfer back to the sender. Only the recipient can reverse a transfer, and never for more than it was.\x82\xd3\xe4\x93\x02(:\x01*\"#/v1/transfers/{transfer_id}/reverse\x12\xd6\x03\n" +
+	"\n" +
+	"CreateHold\x12\x15.pb.CreateHoldRequest\x1a\x16.pb.CreateHoldResponse\"\x98\x03\x92A\x80\x03\x12\vCreate hold\x1a\xf0\x02Reserves money on one of the authenticated user's accounts for a transfer that the recipient captures or releases later. Held money can't be spent until then, and the hold is released automatically if it isn't captured before it expires. Holds above the confirmation threshold aren't allowed, since they could be captured without confirmation; make a transfer instead.\x82\xd3\xe4\x93\x02\x0e:\x01*\"\t/v1/holds\x12\xeb\x01\n" +
+	"\vCaptureHold\x12\x16.pb.CaptureHoldRequest\x1a\x17.pb.CaptureHoldResponse\"\xaa\x01\x92A\x85\x01\x12\fCapture hold\x1auTransfers all or part of a held amount to the recipient and releases the rest. Only the recipient can capture a hold.\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/holds/{id}/capture\x12\xff\x01\n" +
+	"\vReleaseHold\x12\x16.pb.ReleaseHoldRequest\x1a\x17.pb.ReleaseHoldResponse\"\xbe\x01\x92A\x99\x01\x12\fRelease hold\x1a\x88\x01Drops a hold without transferring anything, making the held amount available to the sender again. Only the recipient can release a hold.\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/holds/{id}/release\x12\xfb\x01\n" +
 	"\rCreateAccount\x12\x18.pb.CreateAccountRequest\x1a\x19.pb.CreateAccountResponse\"\xb4\x01\x92A\x99\x01\x12\x14Create a new account\x1a\x80\x01Creates a new account for the authenticated user. This endpoint validates the currency and creates an account with zero balance.\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/v1/accounts\x12\xcb\x01\n" +
 	"\n" +
-	"GetAccount\x12\x15.pb.GetAccountRequest\x1a\x16.pb.GetAccountResponse\"\x8d\x01\x92Aq\x12\x11Get account by ID\x1a\\Retrieves a specific account by ID. Only the account owner can access their account details.\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/accounts/{id}\x12\xbb\x01\n" +
-	"\fListAccounts\x12\x17.pb.ListAccountsRequest\x1a\x18.pb.ListAccountsResponse\"x\x92Aa\x12\x12List user accounts\x1aKLists all accounts owned by the authenticated user with pagination support.\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/accounts\x12\xce\x01\n" +
-	"\rListTransfers\x12\x18.pb.ListTransfersRequest\x1a\x19.pb.ListTransfersResponse\"\x87\x01\x92Ao\x12\x13List user transfers\x1aXLists all transfers involving the authenticated user's accounts with pagination support.\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/transfersB\xe3\x01\x92A\xb5\x01\x12\x8a\x01\n" +
+	"GetAccount\x12\x15.pb.GetAccountRequest\x1a\x16.pb.GetAccountResponse\"\x8d\x01\x92Aq\x12\x11Get account by ID\x1a\\Retrieves a specific account by ID. Only the account owner can access their account details.\x82\xd3\xe4\x93\x02\x13\x12\x11/v1/accounts/{id}\x12\xd8\x02\n" +
+	"\fCloseAccount\x12\x17.pb.CloseAccountRequest\x1a\x18.pb.CloseAccountResponse\"\x94\x02\x92A\xee\x01\x12\rClose account\x1a\xdc\x01Closes one of the authenticated user's accounts. The balance must be zero, or it is first swept to another of the user's accounts as a transfer. Closed accounts keep their history but can no longer send or receive money.\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/accounts/{id}/close\x12\xbb\x01\n" +
+	"\fListAccounts\x12\x17.pb.ListAccountsRequest\x1a\x18.pb.ListAccountsResponse\"x\x92Aa\x12\x12List user accounts\x1aKLists all accounts owned by the authenticated user with pagination support.\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/accounts\x12\xa0\x02\n" +
+	"\rListTransfers\x12\x18.pb.ListTransfersRequest\x1a\x19.pb.ListTransfersResponse\"\xd9\x01\x92A\xc0\x01\x12\x13List user transfers\x1a\xa8\x01Lists all transfers involving the authenticated user's accounts with pagination support and optional filters by account, direction, status, date range and amount range.\x82\xd3\xe4\x93\x02\x0f\x12\r/v1/transfers\x12\xea\x02\n" +
+	"\x12ListAccountEntries\x12\x1d.pb.ListAccountEntriesRequest\x1a\x1e.pb.ListAccountEntriesResponse\"\x94\x02\x92A\xe7\x01\x12\x14List account entries\x1a\xce\x01Lists the entries of one of the authenticated user's accounts over a date range, with the running balance, linked transfer and counterparty of each entry, and the opening and closing balances of the period.\x82\xd3\xe4\x93\x02#\x12!/v1/accounts/{account_id}/entries\x12\xe9\x02\n" +
+	"\x0fExportStatement\x12\x1a.pb.ExportStatementRequest\x1a\x14.google.api.HttpBody\"\xa1\x02\x92A\xf2\x01\x12\x18Export account statement\x1a\xd5\x01Downloads the statement of an account for a period as CSV, OFX or PDF, streamed as it is rendered. Statements with too many entries to render inline are generated in the background and emailed to the user instead.\x82\xd3\xe4\x93\x02%\x12#/v1/accounts/{account_id}/statement0\x01\x12\xae\x02\n" +
+	"\x10RenewAccessToken\x12\x1b.pb.RenewAccessTokenRequest\x1a\x1c.pb.RenewAccessTokenResponse\"\xde\x01\x92A\xb9\x01\x12\x12Renew access token\x1a\xa2\x01Exchanges a refresh token for a new access token and a new refresh token. Each refresh token works once; presenting one again revokes every session of that login.\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/renew_access_token\x12\xb9\x01\n" +
+	"\x06Logout\x12\x11.pb.LogoutRequest\x1a\x12.pb.LogoutResponse\"\x87\x01\x92Aj\x12\aLog out\x1a_Ends the session of the given refresh token so it can no longer be used to renew access tokens.\x82\xd3\xe4\x93\x02\x14:\x01*\"\x0f/v1/logout_user\x12\xd4\x01\n" +
+	"\fListSessions\x12\x17.pb.ListSessionsRequest\x1a\x18.pb.ListSessionsResponse\"\x90\x01\x92Ay\x12\rList sessions\x1ahLists the authenticated user's active sessions with the user agent and client IP they were created from.\x82\xd3\xe4\x93\x02\x0e\x12\f/v1/sessions\x12\xd6\x01\n" +
+	"\rRevokeSession\x12\x18.pb.RevokeSessionRequest\x1a\x19.pb.RevokeSessionResponse\"\x8f\x01\x92Ak\x12\x0eRevoke session\x1aYRevokes one of the authenticated user's sessions, blocking its refresh token immediately.\x82\xd3\xe4\x93\x02\x1b*\x19/v1/sessions/{session_id}\x12\xdf\x01\n" +
+	"\x13RevokeOtherSessions\x12\x1e.pb.RevokeOtherSessionsRequest\x1a\x1f.pb.RevokeOtherSessionsResponse\"\x86\x01\x92A_\x12\x15Revoke other sessions\x1aFRevokes all of the authenticated user's sessions except the given one.\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/v1/revoke_other_sessions\x12\xab\x02\n" +
+	"\x12ListSecurityEvents\x12\x1d.pb.ListSecurityEventsRequest\x1a\x1e.pb.ListSecurityEventsResponse\"\xd5\x01\x92A\xb6\x01\x12\x14List security events\x1a\x9d\x01Lists the authenticated user's security events, such as logins, failed logins, password changes, transfers and admin actions on their accounts, newest first.\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/security_eventsB\xe3\x01\x92A\xb5\x01\x12\x8a\x01\n" +
 	"\x0eVaultGuard API\x12\x1dA secure vault management API\"T\n" +
 	"\bOm Singh\x12-https://github.com/OmSingh2003/VaultGuard-API\x1a\x19omsingh.ailearn@gmail.com2\x031.2*\x02\x02\x012\x10application/json:\x10application/jsonZ(github.com/OmSingh2003/vaultguard-api/pbb\x06proto3"
 
 var file_service_vaultguard_api_proto_goTypes = []any{
-	(*CreateUserRequest)(nil),      // 0: pb.CreateUserRequest
-	(*UpdateUserRequest)(nil),      // 1: pb.UpdateUserRequest
-	(*LoginUserRequest)(nil),       // 2: pb.LoginUserRequest
-	(*VerifyEmailRequest)(nil),     // 3: pb.VerifyEmailRequest
-	(*CreateTransferRequest)(nil),  // 4: pb.CreateTransferRequest
-	(*CreateAccountRequest)(nil),   // 5: pb.CreateAccountRequest
-	(*GetAccountRequest)(nil),      // 6: pb.GetAccountRequest
-	(*ListAccountsRequest)(nil),    // 7: pb.ListAccountsRequest
-	(*ListTransfersRequest)(nil),   // 8: pb.ListTransfersRequest
-	(*CreateUserResponse)(nil),     // 9: pb.CreateUserResponse
-	(*UpdateUserResponse)(nil),     // 10: pb.UpdateUserResponse
-	(*LoginUserResponse)(nil),      // 11: pb.LoginUserResponse
-	(*VerifyEmailResponse)(nil),    // 12: pb.VerifyEmailResponse
-	(*CreateTransferResponse)(nil), // 13: pb.CreateTransferResponse
-	(*CreateAccountResponse)(nil),  // 14: pb.CreateAccountResponse
-	(*GetAccountResponse)(nil),     // 15: pb.GetAccountResponse
-	(*ListAccountsResponse)(nil),   // 16: pb.ListAccountsResponse
-	(*ListTransfersResponse)(nil),  // 17: pb.ListTransfersResponse
+	(*CreateUserRequest)(nil),            // 0: pb.CreateUserRequest
+	(*UpdateUserRequest)(nil),            // 1: pb.UpdateUserRequest
+	(*LoginUserRequest)(nil),             // 2: pb.LoginUserRequest
+	(*VerifyMFARequest)(nil),             // 3: pb.VerifyMFARequest
+	(*EnrollMFARequest)(nil),             // 4: pb.EnrollMFARequest
+	(*ConfirmMFARequest)(nil),            // 5: pb.ConfirmMFARequest
+	(*VerifyEmailRequest)(nil),           // 6: pb.VerifyEmailRequest
+	(*ConfirmEmailChangeRequest)(nil),    // 7: pb.ConfirmEmailChangeRequest
+	(*RequestPasswordResetRequest)(nil),  // 8: pb.RequestPasswordResetRequest
+	(*ResetPasswordRequest)(nil),         // 9: pb.ResetPasswordRequest
+	(*CreateTransferRequest)(nil),        // 10: pb.CreateTransferRequest
+	(*ConfirmTransferRequest)(nil),       // 11: pb.ConfirmTransferRequest
+	(*ReverseTransferRequest)(nil),       // 12: pb.ReverseTransferRequest
+	(*CreateHoldRequest)(nil),            // 13: pb.CreateHoldRequest
+	(*CaptureHoldRequest)(nil),           // 14: pb.CaptureHoldRequest
+	(*ReleaseHoldRequest)(nil),           // 15: pb.ReleaseHoldRequest
+	(*CreateAccountRequest)(nil),         // 16: pb.CreateAccountRequest
+	(*GetAccountRequest)(nil),            // 17: pb.GetAccountRequest
+	(*CloseAccountRequest)(nil),          // 18: pb.CloseAccountRequest
+	(*ListAccountsRequest)(nil),          // 19: pb.ListAccountsRequest
+	(*ListTransfersRequest)(nil),         // 20: pb.ListTransfersRequest
+	(*ListAccountEntriesRequest)(nil),    // 21: pb.ListAccountEntriesRequest
+	(*ExportStatementRequest)(nil),       // 22: pb.ExportStatementRequest
+	(*RenewAccessTokenRequest)(nil),      // 23: pb.RenewAccessTokenRequest
+	(*LogoutRequest)(nil),                // 24: pb.LogoutRequest
+	(*ListSessionsRequest)(nil),          // 25: pb.ListSessionsRequest
+	(*RevokeSessionRequest)(nil),         // 26: pb.RevokeSessionRequest
+	(*RevokeOtherSessionsRequest)(nil),   // 27: pb.RevokeOtherSessionsRequest
+	(*ListSecurityEventsRequest)(nil),    // 28: pb.ListSecurityEventsRequest
+	(*CreateUserResponse)(nil),           // 29: pb.CreateUserResponse
+	(*UpdateUserResponse)(nil),           // 30: pb.UpdateUserResponse
+	(*LoginUserResponse)(nil),            // 31: pb.LoginUserResponse
+	(*EnrollMFAResponse)(nil),            // 32: pb.EnrollMFAResponse
+	(*ConfirmMFAResponse)(nil),           // 33: pb.ConfirmMFAResponse
+	(*VerifyEmailResponse)(nil),          // 34: pb.VerifyEmailResponse
+	(*ConfirmEmailChangeResponse)(nil),   // 35: pb.ConfirmEmailChangeResponse
+	(*RequestPasswordResetResponse)(nil), // 36: pb.RequestPasswordResetResponse
+	(*ResetPasswordResponse)(nil),        // 37: pb.ResetPasswordResponse
+	(*CreateTransferResponse)(nil),       // 38: pb.CreateTransferResponse
+	(*ConfirmTransferResponse)(nil),      // 39: pb.ConfirmTransferResponse
+	(*ReverseTransferResponse)(nil),      // 40: pb.ReverseTransferResponse
+	(*CreateHoldResponse)(nil),           // 41: pb.CreateHoldResponse
+	(*CaptureHoldResponse)(nil),          // 42: pb.CaptureHoldResponse
+	(*ReleaseHoldResponse)(nil),          // 43: pb.ReleaseHoldResponse
+	(*CreateAccountResponse)(nil),        // 44: pb.CreateAccountResponse
+	(*GetAccountResponse)(nil),           // 45: pb.GetAccountResponse
+	(*CloseAccountResponse)(nil),         // 46: pb.CloseAccountResponse
+	(*ListAccountsResponse)(nil),         // 47: pb.ListAccountsResponse
+	(*ListTransfersResponse)(nil),        // 48: pb.ListTransfersResponse
+	(*ListAccountEntriesResponse)(nil),   // 49: pb.ListAccountEntriesResponse
+	(*httpbody.HttpBody)(nil),            // 50: google.api.HttpBody
+	(*RenewAccessTokenResponse)(nil),     // 51: pb.RenewAccessTokenResponse
+	(*LogoutResponse)(nil),               // 52: pb.LogoutResponse
+	(*ListSessionsResponse)(nil),         // 53: pb.ListSessionsResponse
+	(*RevokeSessionResponse)(nil),        // 54: pb.RevokeSessionResponse
+	(*RevokeOtherSessionsResponse)(nil),  // 55: pb.RevokeOtherSessionsResponse
+	(*ListSecurityEventsResponse)(nil),   // 56: pb.ListSecurityEventsResponse
 }
 var file_service_vaultguard_api_proto_depIdxs = []int32{
 	0,  // 0: pb.VaultguardAPI.CreateUser:input_type -> pb.CreateUserRequest
 	1,  // 1: pb.VaultguardAPI.UpdateUser:input_type -> pb.UpdateUserRequest
 	2,  // 2: pb.VaultguardAPI.LoginUser:input_type -> pb.LoginUserRequest
-	3,  // 3: pb.VaultguardAPI.VerifyEmail:input_type -> pb.VerifyEmailRequest
-	4,  // 4: pb.VaultguardAPI.CreateTransfer:input_type -> pb.CreateTransferRequest
-	5,  // 5: pb.VaultguardAPI.CreateAccount:input_type -> pb.CreateAccountRequest
-	6,  // 6: pb.VaultguardAPI.GetAccount:input_type -> pb.GetAccountRequest
-	7,  // 7: pb.VaultguardAPI.ListAccounts:input_type -> pb.ListAccountsRequest
-	8,  // 8: pb.VaultguardAPI.ListTransfers:input_type -> pb.ListTransfersRequest
-	9,  // 9: pb.VaultguardAPI.CreateUser:output_type -> pb.CreateUserResponse
-	10, // 10: pb.VaultguardAPI.UpdateUser:output_type -> pb.UpdateUserResponse
-	11, // 11: pb.VaultguardAPI.LoginUser:output_type -> pb.LoginUserResponse
-	12, // 12: pb.VaultguardAPI.VerifyEmail:output_type -> pb.VerifyEmailResponse
-	13, // 13: pb.VaultguardAPI.CreateTransfer:output_type -> pb.CreateTransferResponse
-	14, // 14: pb.VaultguardAPI.CreateAccount:output_type -> pb.CreateAccountResponse
-	15, // 15: pb.VaultguardAPI.GetAccount:output_type -> pb.GetAccountResponse
-	16, // 16: pb.VaultguardAPI.ListAccounts:output_type -> pb.ListAccountsResponse
-	17, // 17: pb.VaultguardAPI.ListTransfers:output_type -> pb.ListTransfersResponse
-	9,  // [9:18] is the sub-list for method output_type
-	0,  // [0:9] is the sub-list for method input_type
+	3,  // 3: pb.VaultguardAPI.VerifyMFA:input_type -> pb.VerifyMFARequest
+	4,  // 4: pb.VaultguardAPI.EnrollMFA:input_type -> pb.EnrollMFARequest
+	5,  // 5: pb.VaultguardAPI.ConfirmMFA:input_type -> pb.ConfirmMFARequest
+	6,  // 6: pb.VaultguardAPI.VerifyEmail:input_type -> pb.VerifyEmailRequest
+	7,  // 7: pb.VaultguardAPI.ConfirmEmailChange:input_type -> pb.ConfirmEmailChangeRequest
+	8,  // 8: pb.VaultguardAPI.RequestPasswordReset:input_type -> pb.RequestPasswordResetRequest
+	9,  // 9: pb.VaultguardAPI.ResetPassword:input_type -> pb.ResetPasswordRequest
+	10, // 10: pb.VaultguardAPI.CreateTransfer:input_type -> pb.CreateTransferRequest
+	11, // 11: pb.VaultguardAPI.ConfirmTransfer:input_type -> pb.ConfirmTransferRequest
+	12, // 12: pb.VaultguardAPI.ReverseTransfer:input_type -> pb.ReverseTransferRequest
+	13, // 13: pb.VaultguardAPI.CreateHold:input_type -> pb.CreateHoldRequest
+	14, // 14: pb.VaultguardAPI.CaptureHold:input_type -> pb.CaptureHoldRequest
+	15, // 15: pb.VaultguardAPI.ReleaseHold:input_type -> pb.ReleaseHoldRequest
+	16, // 16: pb.VaultguardAPI.CreateAccount:input_type -> pb.CreateAccountRequest
+	17, // 17: pb.VaultguardAPI.GetAccount:input_type -> pb.GetAccountRequest
+	18, // 18: pb.VaultguardAPI.CloseAccount:input_type -> pb.CloseAccountRequest
+	19, // 19: pb.VaultguardAPI.ListAccounts:input_type -> pb.ListAccountsRequest
+	20, // 20: pb.VaultguardAPI.ListTransfers:input_type -> pb.ListTransfersRequest
+	21, // 21: pb.VaultguardAPI.ListAccountEntries:input_type -> pb.ListAccountEntriesRequest
+	22, // 22: pb.VaultguardAPI.ExportStatement:input_type -> pb.ExportStatementRequest
+	23, // 23: pb.VaultguardAPI.RenewAccessToken:input_type -> pb.RenewAccessTokenRequest
+	24, // 24: pb.VaultguardAPI.Logout:input_type -> pb.LogoutRequest
+	25, // 25: pb.VaultguardAPI.ListSessions:input_type -> pb.ListSessionsRequest
+	26, // 26: pb.VaultguardAPI.RevokeSession:input_type -> pb.RevokeSessionRequest
+	27, // 27: pb.VaultguardAPI.RevokeOtherSessions:input_type -> pb.RevokeOtherSessionsRequest
+	28, // 28: pb.VaultguardAPI.ListSecurityEvents:input_type -> pb.ListSecurityEventsRequest
+	29, // 29: pb.VaultguardAPI.CreateUser:output_type -> pb.CreateUserResponse
+	30, // 30: pb.VaultguardAPI.UpdateUser:output_type -> pb.UpdateUserResponse
+	31, // 31: pb.VaultguardAPI.LoginUser:output_type -> pb.LoginUserResponse
+	31, // 32: pb.VaultguardAPI.VerifyMFA:output_type -> pb.LoginUserResponse
+	32, // 33: pb.VaultguardAPI.EnrollMFA:output_type -> pb.EnrollMFAResponse
+	33, // 34: pb.VaultguardAPI.ConfirmMFA:output_type -> pb.ConfirmMFAResponse
+	34, // 35: pb.VaultguardAPI.VerifyEmail:output_type -> pb.VerifyEmailResponse
+	35, // 36: pb.VaultguardAPI.ConfirmEmailChange:output_type -> pb.ConfirmEmailChangeResponse
+	36, // 37: pb.VaultguardAPI.RequestPasswordReset:output_type -> pb.RequestPasswordResetResponse
+	37, // 38: pb.VaultguardAPI.ResetPassword:output_type -> pb.ResetPasswordResponse
+	38, // 39: pb.VaultguardAPI.CreateTransfer:output_type -> pb.CreateTransferResponse
+	39, // 40: pb.VaultguardAPI.ConfirmTransfer:output_type -> pb.ConfirmTransferResponse
+	40, // 41: pb.VaultguardAPI.ReverseTransfer:output_type -> pb.ReverseTransferResponse
+	41, // 42: pb.VaultguardAPI.CreateHold:output_type -> pb.CreateHoldResponse
+	42, // 43: pb.VaultguardAPI.CaptureHold:output_type -> pb.CaptureHoldResponse
+	43, // 44: pb.VaultguardAPI.ReleaseHold:output_type -> pb.ReleaseHoldResponse
+	44, // 45: pb.VaultguardAPI.CreateAccount:output_type -> pb.CreateAccountResponse
+	45, // 46: pb.VaultguardAPI.GetAccount:output_type -> pb.GetAccountResponse
+	46, // 47: pb.VaultguardAPI.CloseAccount:output_type -> pb.CloseAccountResponse
+	47, // 48: pb.VaultguardAPI.ListAccounts:output_type -> pb.ListAccountsResponse
+	48, // 49: pb.VaultguardAPI.ListTransfers:output_type -> pb.ListTransfersResponse
+	49, // 50: pb.VaultguardAPI.ListAccountEntries:output_type -> pb.ListAccountEntriesResponse
+	50, // 51: pb.VaultguardAPI.ExportStatement:output_type -> google.api.HttpBody
+	51, // 52: pb.VaultguardAPI.RenewAccessToken:output_type -> pb.RenewAccessTokenResponse
+	52, // 53: pb.VaultguardAPI.Logout:output_type -> pb.LogoutResponse
+	53, // 54: pb.VaultguardAPI.ListSessions:output_type -> pb.ListSessionsResponse
+	54, // 55: pb.VaultguardAPI.RevokeSession:output_type -> pb.RevokeSessionResponse
+	55, // 56: pb.VaultguardAPI.RevokeOtherSessions:output_type -> pb.RevokeOtherSessionsResponse
+	56, // 57: pb.VaultguardAPI.ListSecurityEvents:output_type -> pb.ListSecurityEventsResponse
+	29, // [29:58] is the sub-list for method output_type
+	0,  // [0:29] is the sub-list for method input_type
 	0,  // [0:0] is the sub-list for extension type_name
 	0,  // [0:0] is the sub-list for extension extendee
 	0,  // [0:0] is the sub-list for field type_name
@@ -99,6 +202,13 @@ func file_service_vaultguard_api_proto_init() {
 	file_rpc_verify_email_proto_init()
 	file_transfer_proto_init()
 	file_account_proto_init()
+	file_entry_proto_init()
+	file_session_proto_init()
+	file_audit_proto_init()
+	file_mfa_proto_init()
+	file_password_reset_proto_init()
+	file_email_change_proto_init()
+	file_hold_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
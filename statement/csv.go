@@ -0,0 +1,56 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvRenderer writes one row per entry under a header row, which is what spreadsheet
+// imports expect; the balances of the period are the first and last running balance.
+type csvRenderer struct {
+	writer    *csv.Writer
+	statement Statement
+}
+
+func newCSVRenderer(w io.Writer, statement Statement) *csvRenderer {
+	return &csvRenderer{
+		writer:    csv.NewWriter(w),
+		statement: statement,
+	}
+}
+
+func (renderer *csvRenderer) Begin() error {
+	return renderer.writer.Write([]string{"date", "entry_id", "transfer_id", "counterparty_account", "amount", "running_balance", "currency"})
+}
+
+func (renderer *csvRenderer) WriteLines(lines []Line) error {
+	for _, line := range lines {
+		transferID := ""
+		if line.TransferID != 0 {
+			transferID = strconv.FormatInt(line.TransferID, 10)
+		}
+
+		err := renderer.writer.Write([]string{
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(line.EntryID, 10),
+			transferID,
+			line.CounterpartyAccountNumber,
+			formatAmount(line.Amount),
+			formatAmount(line.RunningBalance),
+			renderer.statement.Currency,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	renderer.writer.Flush()
+	return renderer.writer.Error()
+}
+
+func (renderer *csvRenderer) End() error {
+	renderer.writer.Flush()
+	return renderer.writer.Error()
+}
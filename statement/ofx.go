@@ -0,0 +1,194 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>` + "\n" +
+	`<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+
+// ofxBankID identifies Nimbus as the institution in BANKACCTFROM
+const ofxBankID = "NIMBUS"
+
+// ofxDocument is the layout of the document the OFX renderer writes. It writes the
+// document element by element so the transactions can be written as they are loaded.
+type ofxDocument struct {
+	XMLName xml.Name       `xml:"OFX"`
+	SignOn  ofxSignOn      `xml:"SIGNONMSGSRSV1>SONRS"`
+	Bank    ofxStatementRs `xml:"BANKMSGSRSV1>STMTTRNRS"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignOn struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DTServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxStatementRs struct {
+	TrnUID    string       `xml:"TRNUID"`
+	Status    ofxStatus    `xml:"STATUS"`
+	Statement ofxStatement `xml:"STMTRS"`
+}
+
+type ofxStatement struct {
+	CurDef    string         `xml:"CURDEF"`
+	Account   ofxBankAccount `xml:"BANKACCTFROM"`
+	TranList  ofxTranList    `xml:"BANKTRANLIST"`
+	LedgerBal ofxBalance     `xml:"LEDGERBAL"`
+}
+
+type ofxBankAccount struct {
+	BankID   string `xml:"BANKID"`
+	AcctID   string `xml:"ACCTID"`
+	AcctType string `xml:"ACCTTYPE"`
+}
+
+type ofxTranList struct {
+	DTStart      string           `xml:"DTSTART"`
+	DTEnd        string           `xml:"DTEND"`
+	Transactions []ofxTransaction `xml:"STMTTRN"`
+}
+
+type ofxTransaction struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DTPosted string `xml:"DTPOSTED"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FitID    string `xml:"FITID"`
+	Name     string `xml:"NAME,omitempty"`
+	Memo     string `xml:"MEMO,omitempty"`
+}
+
+type ofxBalance struct {
+	BalAmt string `xml:"BALAMT"`
+	DTAsOf string `xml:"DTASOF"`
+}
+
+// ofxRenderer writes an OFX 2.2 bank statement response. Encoding errors are kept
+// in err, so the elements can be written one after another and checked once.
+type ofxRenderer struct {
+	w         io.Writer
+	encoder   *xml.Encoder
+	statement Statement
+	err       error
+}
+
+func newOFXRenderer(w io.Writer, statement Statement) *ofxRenderer {
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return &ofxRenderer{
+		w:         w,
+		encoder:   encoder,
+		statement: statement,
+	}
+}
+
+func (renderer *ofxRenderer) Begin() error {
+	if _, err := io.WriteString(renderer.w, ofxHeader); err != nil {
+		return err
+	}
+
+	accountID := renderer.statement.AccountNumber
+	if accountID == "" {
+		accountID = strconv.FormatInt(renderer.statement.AccountID, 10)
+	}
+
+	renderer.start("OFX")
+	renderer.start("SIGNONMSGSRSV1")
+	renderer.element("SONRS", ofxSignOn{
+		Status:   ofxStatus{Code: 0, Severity: "INFO"},
+		DTServer: ofxTime(time.Now()),
+		Language: "ENG",
+	})
+	renderer.end("SIGNONMSGSRSV1")
+	renderer.start("BANKMSGSRSV1")
+	renderer.start("STMTTRNRS")
+	renderer.element("TRNUID", "0")
+	renderer.element("STATUS", ofxStatus{Code: 0, Severity: "INFO"})
+	renderer.start("STMTRS")
+	renderer.element("CURDEF", renderer.statement.Currency)
+	renderer.element("BANKACCTFROM", ofxBankAccount{
+		BankID:   ofxBankID,
+		AcctID:   accountID,
+		AcctType: "CHECKING",
+	})
+	renderer.start("BANKTRANLIST")
+	renderer.element("DTSTART", ofxTime(renderer.statement.StartTime))
+	renderer.element("DTEND", ofxTime(renderer.statement.EndTime))
+	return renderer.flush()
+}
+
+func (renderer *ofxRenderer) WriteLines(lines []Line) error {
+	for _, line := range lines {
+		transaction := ofxTransaction{
+			TrnType:  "CREDIT",
+			DTPosted: ofxTime(line.CreatedAt),
+			TrnAmt:   formatAmount(line.Amount),
+			FitID:    strconv.FormatInt(line.EntryID, 10),
+			Name:     line.CounterpartyAccountNumber,
+		}
+		if line.Amount < 0 {
+			transaction.TrnType = "DEBIT"
+		}
+		if line.TransferID != 0 {
+			transaction.Memo = fmt.Sprintf("Transfer %d", line.TransferID)
+		}
+		renderer.element("STMTTRN", transaction)
+	}
+	return renderer.flush()
+}
+
+func (renderer *ofxRenderer) End() error {
+	renderer.end("BANKTRANLIST")
+	renderer.element("LEDGERBAL", ofxBalance{
+		BalAmt: formatAmount(renderer.statement.ClosingBalance),
+		DTAsOf: ofxTime(renderer.statement.EndTime),
+	})
+	renderer.end("STMTRS")
+	renderer.end("STMTTRNRS")
+	renderer.end("BANKMSGSRSV1")
+	renderer.end("OFX")
+	if err := renderer.flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(renderer.w, "\n")
+	return err
+}
+
+func (renderer *ofxRenderer) start(name string) {
+	if renderer.err == nil {
+		renderer.err = renderer.encoder.EncodeToken(xml.StartElement{Name: xml.Name{Local: name}})
+	}
+}
+
+func (renderer *ofxRenderer) end(name string) {
+	if renderer.err == nil {
+		renderer.err = renderer.encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: name}})
+	}
+}
+
+func (renderer *ofxRenderer) element(name string, v any) {
+	if renderer.err == nil {
+		renderer.err = renderer.encoder.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}})
+	}
+}
+
+func (renderer *ofxRenderer) flush() error {
+	if renderer.err == nil {
+		renderer.err = renderer.encoder.Flush()
+	}
+	return renderer.err
+}
+
+// ofxTime formats t as an OFX datetime in UTC
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
@@ -0,0 +1,194 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Page layout of the generated PDF, in points on an A4 page
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 9
+	pdfLineHeight   = 13
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+)
+
+// pdfRenderer writes the statement as a plain text table in a monospaced font.
+// It writes the PDF objects by hand so no PDF library is needed.
+//
+// Objects 1-3 are the catalog, the page tree and the font; each page then takes
+// two objects, the page itself and its content stream. Pages are written as they
+// fill up, and the page tree last, once the number of pages is known.
+type pdfRenderer struct {
+	w         *pdfWriter
+	statement Statement
+	// lines of the page being filled
+	lines []string
+	pages int
+}
+
+// pdfWriter counts the bytes written so far, for the offsets in the xref table
+type pdfWriter struct {
+	w       io.Writer
+	n       int
+	offsets []int
+}
+
+func newPDFRenderer(w io.Writer, statement Statement) *pdfRenderer {
+	return &pdfRenderer{
+		w:         &pdfWriter{w: w},
+		statement: statement,
+	}
+}
+
+func (renderer *pdfRenderer) Begin() error {
+	if err := renderer.w.writeString("%PDF-1.4\n"); err != nil {
+		return err
+	}
+	if err := renderer.w.writeObject(1, "<< /Type /Catalog /Pages 2 0 R >>"); err != nil {
+		return err
+	}
+	if err := renderer.w.writeObject(3, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>"); err != nil {
+		return err
+	}
+
+	accountNumber := renderer.statement.AccountNumber
+	if accountNumber == "" {
+		accountNumber = fmt.Sprintf("%d", renderer.statement.AccountID)
+	}
+
+	return renderer.addLines(
+		"Nimbus account statement",
+		"",
+		fmt.Sprintf("Account:  %s (%s)", accountNumber, renderer.statement.Currency),
+		fmt.Sprintf("Owner:    %s", renderer.statement.Owner),
+		fmt.Sprintf("Period:   %s to %s", renderer.statement.StartTime.UTC().Format(time.RFC3339), renderer.statement.EndTime.UTC().Format(time.RFC3339)),
+		fmt.Sprintf("Opening balance: %s", formatAmount(renderer.statement.OpeningBalance)),
+		"",
+		fmt.Sprintf("%-20s  %-10s  %-16s  %14s  %14s", "Date", "Transfer", "Counterparty", "Amount", "Balance"),
+	)
+}
+
+func (renderer *pdfRenderer) WriteLines(lines []Line) error {
+	for _, line := range lines {
+		transferID := ""
+		if line.TransferID != 0 {
+			transferID = fmt.Sprintf("%d", line.TransferID)
+		}
+		err := renderer.addLines(fmt.Sprintf("%-20s  %-10s  %-16s  %14s  %14s",
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			transferID,
+			line.CounterpartyAccountNumber,
+			formatAmount(line.Amount),
+			formatAmount(line.RunningBalance),
+		))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (renderer *pdfRenderer) End() error {
+	err := renderer.addLines("", fmt.Sprintf("Closing balance: %s", formatAmount(renderer.statement.ClosingBalance)))
+	if err != nil {
+		return err
+	}
+	if err := renderer.writePage(); err != nil {
+		return err
+	}
+
+	kids := make([]string, renderer.pages)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	err = renderer.w.writeObject(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), renderer.pages))
+	if err != nil {
+		return err
+	}
+
+	return renderer.w.writeTrailer()
+}
+
+// addLines adds lines to the page being filled, writing out each page once it's full
+func (renderer *pdfRenderer) addLines(lines ...string) error {
+	for _, line := range lines {
+		if len(renderer.lines) == pdfLinesPerPage {
+			if err := renderer.writePage(); err != nil {
+				return err
+			}
+		}
+		renderer.lines = append(renderer.lines, line)
+	}
+	return nil
+}
+
+// writePage writes the page being filled and its content stream
+func (renderer *pdfRenderer) writePage() error {
+	pageObject := 4 + 2*renderer.pages
+	content := pdfContentStream(renderer.lines)
+
+	err := renderer.w.writeObject(pageObject,
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, pageObject+1))
+	if err != nil {
+		return err
+	}
+	err = renderer.w.writeObject(pageObject+1, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	if err != nil {
+		return err
+	}
+
+	renderer.pages++
+	renderer.lines = renderer.lines[:0]
+	return nil
+}
+
+func (w *pdfWriter) writeString(s string) error {
+	n, err := io.WriteString(w.w, s)
+	w.n += n
+	return err
+}
+
+// writeObject writes object number and records where it starts
+func (w *pdfWriter) writeObject(number int, object string) error {
+	for len(w.offsets) < number {
+		w.offsets = append(w.offsets, 0)
+	}
+	w.offsets[number-1] = w.n
+	return w.writeString(fmt.Sprintf("%d 0 obj\n%s\nendobj\n", number, object))
+}
+
+// writeTrailer writes the xref table of the objects written so far and the trailer
+func (w *pdfWriter) writeTrailer() error {
+	xrefOffset := w.n
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, xrefOffset)
+	return w.writeString(buf.String())
+}
+
+// pdfContentStream draws the lines top to bottom starting at the top margin
+func pdfContentStream(lines []string) string {
+	var stream strings.Builder
+	fmt.Fprintf(&stream, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin)
+	for _, line := range lines {
+		fmt.Fprintf(&stream, "(%s) '\n", pdfEscape(line))
+	}
+	stream.WriteString("ET")
+	return stream.String()
+}
+
+// pdfEscape escapes the characters that are special inside a PDF string literal
+func pdfEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
@@ -0,0 +1,217 @@
+// Package statement renders an account's entries over a period as a downloadable
+// statement in CSV, OFX or PDF.
+package statement
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+)
+
+// Format is the file format a statement is rendered in
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+	FormatOFX Format = "ofx"
+	FormatPDF Format = "pdf"
+)
+
+// ErrUnsupportedFormat is returned for a format other than csv, ofx or pdf
+var ErrUnsupportedFormat = errors.New("unsupported statement format")
+
+// loadBatchSize is how many entries Load reads per query
+const loadBatchSize = 500
+
+// Line is one entry on a statement
+type Line struct {
+	EntryID                   int64
+	CreatedAt                 time.Time
+	Amount                    int64
+	RunningBalance            int64
+	TransferID                int64
+	CounterpartyAccountNumber string
+}
+
+// Statement holds everything needed to render an account statement.
+// Amounts are in the minor unit of Currency.
+type Statement struct {
+	AccountID      int64
+	AccountNumber  string
+	Owner          string
+	Currency       string
+	StartTime      time.Time
+	EndTime        time.Time
+	OpeningBalance int64
+	ClosingBalance int64
+	Lines          []Line
+}
+
+// ParseFormat parses a format name, ignoring case
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatCSV, FormatOFX, FormatPDF:
+		return format, nil
+	}
+	return "", ErrUnsupportedFormat
+}
+
+// ContentType returns the MIME type of the format
+func (format Format) ContentType() string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatOFX:
+		return "application/x-ofx"
+	case FormatPDF:
+		return "application/pdf"
+	}
+	return "application/octet-stream"
+}
+
+// FileName returns the name a statement in this format is downloaded or attached as
+func (format Format) FileName(statement Statement) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s",
+		statement.AccountID,
+		statement.StartTime.UTC().Format("20060102"),
+		statement.EndTime.UTC().Format("20060102"),
+		format,
+	)
+}
+
+// Renderer writes a statement in parts, so its lines can be written a page at a time
+// as they are loaded instead of being held in memory all at once
+type Renderer interface {
+	// Begin writes everything that comes before the lines
+	Begin() error
+	// WriteLines writes the next lines of the statement, in order
+	WriteLines(lines []Line) error
+	// End writes everything that comes after the lines
+	End() error
+}
+
+// NewRenderer returns a renderer that writes statement to w in the given format.
+// The lines of statement are ignored; they are passed to WriteLines instead.
+func NewRenderer(w io.Writer, format Format, statement Statement) (Renderer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVRenderer(w, statement), nil
+	case FormatOFX:
+		return newOFXRenderer(w, statement), nil
+	case FormatPDF:
+		return newPDFRenderer(w, statement), nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// Render writes the statement to w in the given format
+func Render(w io.Writer, format Format, statement Statement) error {
+	renderer, err := NewRenderer(w, format, statement)
+	if err != nil {
+		return err
+	}
+	if err := renderer.Begin(); err != nil {
+		return err
+	}
+	if err := renderer.WriteLines(statement.Lines); err != nil {
+		return err
+	}
+	return renderer.End()
+}
+
+// Load reads all entries of the account in [startTime, endTime) along with the
+// opening and closing balances of the period.
+func Load(ctx context.Context, q db.Querier, account db.Account, startTime, endTime time.Time) (Statement, error) {
+	statement, err := LoadSummary(ctx, q, account, startTime, endTime)
+	if err != nil {
+		return statement, err
+	}
+
+	err = LoadLines(ctx, q, statement, func(lines []Line) error {
+		statement.Lines = append(statement.Lines, lines...)
+		return nil
+	})
+	return statement, err
+}
+
+// LoadSummary reads the opening and closing balances of the account over [startTime, endTime),
+// leaving the lines to LoadLines.
+func LoadSummary(ctx context.Context, q db.Querier, account db.Account, startTime, endTime time.Time) (Statement, error) {
+	statement := Statement{
+		AccountID:     account.ID,
+		AccountNumber: account.AccountNumber.String,
+		Owner:         account.Owner,
+		Currency:      account.Currency,
+		StartTime:     startTime,
+		EndTime:       endTime,
+	}
+
+	balances, err := q.GetAccountStatementBalances(ctx, db.GetAccountStatementBalancesParams{
+		StartTime: startTime,
+		EndTime:   endTime,
+		AccountID: account.ID,
+	})
+	if err != nil {
+		return statement, fmt.Errorf("failed to get statement balances: %w", err)
+	}
+	statement.OpeningBalance = balances.OpeningBalance
+	statement.ClosingBalance = balances.ClosingBalance
+	return statement, nil
+}
+
+// LoadLines reads the entries of the statement's account and period a page at a time,
+// calling fn with the lines of each page in order.
+func LoadLines(ctx context.Context, q db.Querier, statement Statement, fn func(lines []Line) error) error {
+	arg := db.ListAccountStatementEntriesParams{
+		AccountID: statement.AccountID,
+		StartTime: statement.StartTime,
+		EndTime:   statement.EndTime,
+		Limit:     loadBatchSize,
+	}
+	for {
+		entries, err := q.ListAccountStatementEntries(ctx, arg)
+		if err != nil {
+			return fmt.Errorf("failed to list statement entries: %w", err)
+		}
+
+		lines := make([]Line, 0, len(entries))
+		for _, entry := range entries {
+			lines = append(lines, Line{
+				EntryID:                   entry.ID,
+				CreatedAt:                 entry.CreatedAt,
+				Amount:                    entry.Amount,
+				RunningBalance:            entry.RunningBalance,
+				TransferID:                entry.TransferID.Int64,
+				CounterpartyAccountNumber: entry.CounterpartyAccountNumber,
+			})
+		}
+		if len(lines) > 0 {
+			if err := fn(lines); err != nil {
+				return err
+			}
+		}
+
+		if len(entries) < loadBatchSize {
+			return nil
+		}
+		last := entries[len(entries)-1]
+		arg.AfterCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+		arg.AfterID = sql.NullInt64{Int64: last.ID, Valid: true}
+	}
+}
+
+// formatAmount formats an amount in minor units with two decimals, e.g. -1050 as -10.50
+func formatAmount(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
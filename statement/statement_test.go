@@ -0,0 +1,154 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement(n int) Statement {
+	startTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	statement := Statement{
+		AccountID:      7,
+		AccountNumber:  "1234567890",
+		Owner:          "alice",
+		Currency:       "USD",
+		StartTime:      startTime,
+		EndTime:        startTime.AddDate(0, 1, 0),
+		OpeningBalance: 10000,
+	}
+
+	balance := statement.OpeningBalance
+	for i := 0; i < n; i++ {
+		amount := int64(-250)
+		if i%2 == 1 {
+			amount = 1005
+		}
+		balance += amount
+		statement.Lines = append(statement.Lines, Line{
+			EntryID:                   int64(100 + i),
+			CreatedAt:                 startTime.Add(time.Duration(i+1) * time.Hour),
+			Amount:                    amount,
+			RunningBalance:            balance,
+			TransferID:                int64(50 + i),
+			CounterpartyAccountNumber: "0987654321",
+		})
+	}
+	statement.ClosingBalance = balance
+	return statement
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"csv", "OFX", "Pdf"} {
+		format, err := ParseFormat(name)
+		require.NoError(t, err)
+		require.Equal(t, Format(strings.ToLower(name)), format)
+	}
+
+	_, err := ParseFormat("xlsx")
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestFileName(t *testing.T) {
+	statement := testStatement(0)
+	require.Equal(t, "statement-7-20250101-20250201.pdf", FormatPDF.FileName(statement))
+}
+
+func TestFormatAmount(t *testing.T) {
+	require.Equal(t, "0.00", formatAmount(0))
+	require.Equal(t, "10.05", formatAmount(1005))
+	require.Equal(t, "-2.50", formatAmount(-250))
+	require.Equal(t, "-0.07", formatAmount(-7))
+}
+
+func TestRenderCSV(t *testing.T) {
+	statement := testStatement(3)
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, FormatCSV, statement))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, "date", records[0][0])
+	require.Equal(t, []string{"2025-01-01T01:00:00Z", "100", "50", "0987654321", "-2.50", "97.50", "USD"}, records[1])
+	require.Equal(t, "10.05", records[2][4])
+}
+
+func TestRenderOFX(t *testing.T) {
+	statement := testStatement(2)
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, FormatOFX, statement))
+	require.True(t, strings.HasPrefix(buf.String(), "<?xml"))
+	require.Contains(t, buf.String(), `OFXHEADER="200"`)
+
+	var doc ofxDocument
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+
+	stmt := doc.Bank.Statement
+	require.Equal(t, "USD", stmt.CurDef)
+	require.Equal(t, "1234567890", stmt.Account.AcctID)
+	require.Len(t, stmt.TranList.Transactions, 2)
+	require.Equal(t, "DEBIT", stmt.TranList.Transactions[0].TrnType)
+	require.Equal(t, "-2.50", stmt.TranList.Transactions[0].TrnAmt)
+	require.Equal(t, "CREDIT", stmt.TranList.Transactions[1].TrnType)
+	require.Equal(t, "20250101000000.000[0:GMT]", stmt.TranList.DTStart)
+	require.Equal(t, formatAmount(statement.ClosingBalance), stmt.LedgerBal.BalAmt)
+}
+
+func TestRenderPDF(t *testing.T) {
+	statement := testStatement(pdfLinesPerPage)
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, FormatPDF, statement))
+
+	pdf := buf.String()
+	require.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	require.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	// The header lines push the entries onto a second page
+	require.Contains(t, pdf, "/Count 2")
+	require.Contains(t, pdf, "(Closing balance: "+formatAmount(statement.ClosingBalance)+") '")
+}
+
+func TestRenderUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := Render(&buf, Format("xlsx"), testStatement(1))
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestRendererWritesLinesInPages(t *testing.T) {
+	statement := testStatement(2*pdfLinesPerPage + 3)
+
+	for _, format := range []Format{FormatCSV, FormatOFX, FormatPDF} {
+		t.Run(string(format), func(t *testing.T) {
+			var whole bytes.Buffer
+			require.NoError(t, Render(&whole, format, statement))
+
+			var paged bytes.Buffer
+			renderer, err := NewRenderer(&paged, format, statement)
+			require.NoError(t, err)
+			require.NoError(t, renderer.Begin())
+			for lines := statement.Lines; len(lines) > 0; {
+				n := min(7, len(lines))
+				require.NoError(t, renderer.WriteLines(lines[:n]))
+				lines = lines[n:]
+			}
+			require.NoError(t, renderer.End())
+
+			if format == FormatOFX {
+				// The documents differ only in when they were generated
+				var doc ofxDocument
+				require.NoError(t, xml.Unmarshal(paged.Bytes(), &doc))
+				require.Len(t, doc.Bank.Statement.TranList.Transactions, len(statement.Lines))
+				return
+			}
+			require.Equal(t, whole.String(), paged.String())
+		})
+	}
+}
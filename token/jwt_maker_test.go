@@ -13,12 +13,13 @@ func TestJWTMaker(t *testing.T) {
 	require.NoError(t, err)
 
 	username := "username"
+	role := "depositor"
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, duration)
+	token, payload, err := maker.CreateToken(username, role, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 	require.NotEmpty(t, token)
@@ -29,6 +30,7 @@ func TestJWTMaker(t *testing.T) {
 
 	require.NotZero(t, verifiedPayload.ID)
 	require.Equal(t, username, verifiedPayload.Username)
+	require.Equal(t, role, verifiedPayload.Role)
 	require.WithinDuration(t, issuedAt, verifiedPayload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, verifiedPayload.ExpiredAt, time.Second)
 }
@@ -37,7 +39,7 @@ func TestExpiredJWTToken(t *testing.T) {
 	maker, err := NewJWTMaker("12345678901234567890123456789012")
 	require.NoError(t, err)
 
-	token, _, err := maker.CreateToken("username", -time.Minute)
+	token, _, err := maker.CreateToken("username", "depositor", -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -48,7 +50,7 @@ func TestExpiredJWTToken(t *testing.T) {
 }
 
 func TestInvalidJWTTokenAlgNone(t *testing.T) {
-	payload, err := NewPayload("username", time.Minute)
+	payload, err := NewPayload("username", "depositor", time.Minute)
 	require.NoError(t, err)
 
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodNone, payload)
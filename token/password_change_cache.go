@@ -0,0 +1,99 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrTokenRevoked is returned for tokens issued before the user last changed their password
+var ErrTokenRevoked = errors.New("token was issued before the password was changed")
+
+// maxPasswordChangeEntries bounds the cache. Expired entries are dropped when it
+// fills up, and everything is dropped if that isn't enough.
+const maxPasswordChangeEntries = 10000
+
+// PasswordChangeLoader returns when a user last changed their password
+type PasswordChangeLoader func(ctx context.Context, username string) (time.Time, error)
+
+type passwordChange struct {
+	changedAt time.Time
+	expiresAt time.Time
+}
+
+// PasswordChangeCache remembers when users last changed their password, so tokens
+// issued before then can be rejected without a database query on every request.
+// A password change made elsewhere takes up to the cache duration to apply.
+type PasswordChangeCache struct {
+	load     PasswordChangeLoader
+	duration time.Duration
+
+	mu      sync.Mutex
+	entries map[string]passwordChange
+}
+
+// NewPasswordChangeCache creates a cache that keeps what load returns for duration
+func NewPasswordChangeCache(load PasswordChangeLoader, duration time.Duration) *PasswordChangeCache {
+	return &PasswordChangeCache{
+		load:     load,
+		duration: duration,
+		entries:  make(map[string]passwordChange),
+	}
+}
+
+// CheckToken returns ErrTokenRevoked if the token was issued before the user
+// last changed their password
+func (cache *PasswordChangeCache) CheckToken(ctx context.Context, payload *Payload) error {
+	changedAt, err := cache.changedAt(ctx, payload.Username)
+	if err != nil {
+		return err
+	}
+	if payload.IssuedAt.Before(changedAt) {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// Invalidate forgets the user's entry, so a password change made through this
+// server applies straight away
+func (cache *PasswordChangeCache) Invalidate(username string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	delete(cache.entries, username)
+}
+
+func (cache *PasswordChangeCache) changedAt(ctx context.Context, username string) (time.Time, error) {
+	now := time.Now()
+
+	cache.mu.Lock()
+	entry, ok := cache.entries[username]
+	cache.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.changedAt, nil
+	}
+
+	// Loaded without the lock held, so a slow query doesn't hold up other users
+	changedAt, err := cache.load(ctx, username)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if len(cache.entries) >= maxPasswordChangeEntries {
+		for key, entry := range cache.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(cache.entries, key)
+			}
+		}
+		if len(cache.entries) >= maxPasswordChangeEntries {
+			cache.entries = make(map[string]passwordChange)
+		}
+	}
+	cache.entries[username] = passwordChange{
+		changedAt: changedAt,
+		expiresAt: now.Add(cache.duration),
+	}
+	return changedAt, nil
+}
@@ -0,0 +1,65 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordChangeCache(t *testing.T) {
+	changedAt := time.Now().Add(-time.Hour)
+	loads := 0
+	cache := NewPasswordChangeCache(func(ctx context.Context, username string) (time.Time, error) {
+		loads++
+		return changedAt, nil
+	}, time.Minute)
+
+	payload, err := NewPayload("username", "depositor", time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.CheckToken(context.Background(), payload))
+	require.NoError(t, cache.CheckToken(context.Background(), payload))
+	require.Equal(t, 1, loads)
+
+	// The cached time is used until the entry is invalidated
+	changedAt = time.Now().Add(time.Second)
+	require.NoError(t, cache.CheckToken(context.Background(), payload))
+
+	cache.Invalidate("username")
+	require.ErrorIs(t, cache.CheckToken(context.Background(), payload), ErrTokenRevoked)
+	require.Equal(t, 2, loads)
+
+	// A token issued after the change is accepted
+	payload.IssuedAt = changedAt.Add(time.Second)
+	require.NoError(t, cache.CheckToken(context.Background(), payload))
+}
+
+func TestPasswordChangeCacheExpiry(t *testing.T) {
+	loads := 0
+	cache := NewPasswordChangeCache(func(ctx context.Context, username string) (time.Time, error) {
+		loads++
+		return time.Time{}, nil
+	}, 0)
+
+	payload, err := NewPayload("username", "depositor", time.Minute)
+	require.NoError(t, err)
+
+	require.NoError(t, cache.CheckToken(context.Background(), payload))
+	require.NoError(t, cache.CheckToken(context.Background(), payload))
+	require.Equal(t, 2, loads)
+}
+
+func TestPasswordChangeCacheLoadError(t *testing.T) {
+	loadErr := errors.New("database unavailable")
+	cache := NewPasswordChangeCache(func(ctx context.Context, username string) (time.Time, error) {
+		return time.Time{}, loadErr
+	}, time.Minute)
+
+	payload, err := NewPayload("username", "depositor", time.Minute)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, cache.CheckToken(context.Background(), payload), loadErr)
+}
@@ -12,12 +12,13 @@ func TestPasetoMaker(t *testing.T) {
 	require.NoError(t, err)
 
 	username := "username"
+	role := "depositor"
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token,payload, err := maker.CreateToken(username, duration)
+	token,payload, err := maker.CreateToken(username, role, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -28,6 +29,7 @@ func TestPasetoMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, role, payload.Role)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -36,7 +38,7 @@ func TestExpiredPasetoToken(t *testing.T) {
 	maker, err := NewPasetoMaker("12345678901234567890123456789012")
 	require.NoError(t, err)
 
-	token,payload, err := maker.CreateToken("username", -time.Minute)
+	token,payload, err := maker.CreateToken("username", "depositor", -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -0,0 +1,8 @@
+package util
+
+// Statuses an account can be in
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
@@ -2,6 +2,7 @@ package util
 
 import (
 	"os"
+	"strconv"
 	"time"
 )
 
@@ -20,6 +21,33 @@ type Config struct {
 	EmailSenderAddress   string        `mapstructure:"EMAIL_SENDER_ADDRESS"`
 	EmailSenderPassword  string        `mapstructure:"EMAIL_SENDER_PASSWORD"`
 	EmailVerificationURL string        `mapstructure:"EMAIL_VERIFICATION_URL"`
+	FXRatesFile          string        `mapstructure:"FX_RATES_FILE"`
+	FXSpreadBps          int64         `mapstructure:"FX_SPREAD_BPS"`
+	StatementInlineLimit int64         `mapstructure:"STATEMENT_INLINE_LIMIT"`
+	LoginMaxFailures     int64         `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginBackoffBase     time.Duration `mapstructure:"LOGIN_BACKOFF_BASE"`
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	MFATokenDuration     time.Duration `mapstructure:"MFA_TOKEN_DURATION"`
+	// TrustedProxyHops is how many proxies in front of the HTTP gateway append to
+	// X-Forwarded-For; the client IP is the entry just before theirs
+	TrustedProxyHops int `mapstructure:"TRUSTED_PROXY_HOPS"`
+	// TransferConfirmationThreshold is in USD cents and applies to users who haven't
+	// set a lower one
+	TransferConfirmationThreshold int64         `mapstructure:"TRANSFER_CONFIRMATION_THRESHOLD"`
+	PendingTransferDuration       time.Duration `mapstructure:"PENDING_TRANSFER_DURATION"`
+	// PasswordResetURL is the page a password reset link opens, which calls ResetPassword
+	PasswordResetURL      string        `mapstructure:"PASSWORD_RESET_URL"`
+	PasswordResetDuration time.Duration `mapstructure:"PASSWORD_RESET_DURATION"`
+	// PasswordChangeCacheDuration is how long a user's password_changed_at is
+	// cached for when checking tokens
+	PasswordChangeCacheDuration time.Duration `mapstructure:"PASSWORD_CHANGE_CACHE_DURATION"`
+	// ReconciliationAlertEmail is sent the discrepancies found by reconciliation runs
+	ReconciliationInterval   time.Duration `mapstructure:"RECONCILIATION_INTERVAL"`
+	ReconciliationAlertEmail string        `mapstructure:"RECONCILIATION_ALERT_EMAIL"`
+	// Holds are released if they aren't captured within HoldDuration. Expired holds
+	// are looked for every HoldExpiryInterval.
+	HoldDuration       time.Duration `mapstructure:"HOLD_DURATION"`
+	HoldExpiryInterval time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
 }
 
 // LoadConfig reads configuration from file or environment variables
@@ -35,6 +63,7 @@ func LoadConfig(path string) (config Config, err error) {
 	config.EmailSenderAddress = getEnvOrDefault("EMAIL_SENDER_ADDRESS", "")
 	config.EmailSenderPassword = getEnvOrDefault("EMAIL_SENDER_PASSWORD", "")
 	config.EmailVerificationURL = getEnvOrDefault("EMAIL_VERIFICATION_URL", "")
+	config.FXRatesFile = getEnvOrDefault("FX_RATES_FILE", "")
 
 	// Parse duration values
 	accessTokenDuration := getEnvOrDefault("ACCESS_TOKEN_DURATION", "15m")
@@ -49,6 +78,89 @@ func LoadConfig(path string) (config Config, err error) {
 		return config, err
 	}
 
+	// Spread kept on cross-currency transfers, in basis points
+	config.FXSpreadBps, err = strconv.ParseInt(getEnvOrDefault("FX_SPREAD_BPS", "0"), 10, 64)
+	if err != nil {
+		return config, err
+	}
+
+	// Statements with more entries than this are emailed instead of downloaded
+	config.StatementInlineLimit, err = strconv.ParseInt(getEnvOrDefault("STATEMENT_INLINE_LIMIT", "1000"), 10, 64)
+	if err != nil {
+		return config, err
+	}
+
+	// Failed logins back off exponentially from LoginBackoffBase, and the username or
+	// client IP is locked out for LoginLockoutDuration after LoginMaxFailures in a row
+	config.LoginMaxFailures, err = strconv.ParseInt(getEnvOrDefault("LOGIN_MAX_FAILURES", "5"), 10, 64)
+	if err != nil {
+		return config, err
+	}
+
+	config.LoginBackoffBase, err = time.ParseDuration(getEnvOrDefault("LOGIN_BACKOFF_BASE", "1s"))
+	if err != nil {
+		return config, err
+	}
+
+	config.LoginLockoutDuration, err = time.ParseDuration(getEnvOrDefault("LOGIN_LOCKOUT_DURATION", "15m"))
+	if err != nil {
+		return config, err
+	}
+
+	config.TrustedProxyHops, err = strconv.Atoi(getEnvOrDefault("TRUSTED_PROXY_HOPS", "0"))
+	if err != nil {
+		return config, err
+	}
+
+	// Time a user with two-factor authentication has to enter their code after their password
+	config.MFATokenDuration, err = time.ParseDuration(getEnvOrDefault("MFA_TOKEN_DURATION", "5m"))
+	if err != nil {
+		return config, err
+	}
+
+	// Transfers above the threshold, converted into USD cents, are held for
+	// PendingTransferDuration until the user confirms them with their password or a two-factor code
+	config.TransferConfirmationThreshold, err = strconv.ParseInt(getEnvOrDefault("TRANSFER_CONFIRMATION_THRESHOLD", "100000"), 10, 64)
+	if err != nil {
+		return config, err
+	}
+
+	config.PendingTransferDuration, err = time.ParseDuration(getEnvOrDefault("PENDING_TRANSFER_DURATION", "10m"))
+	if err != nil {
+		return config, err
+	}
+
+	// Password reset links are single use and expire after PasswordResetDuration
+	config.PasswordResetURL = getEnvOrDefault("PASSWORD_RESET_URL", "")
+	config.PasswordResetDuration, err = time.ParseDuration(getEnvOrDefault("PASSWORD_RESET_DURATION", "1h"))
+	if err != nil {
+		return config, err
+	}
+
+	// Tokens issued before a password change are rejected. Another server instance
+	// may take this long to notice the change.
+	config.PasswordChangeCacheDuration, err = time.ParseDuration(getEnvOrDefault("PASSWORD_CHANGE_CACHE_DURATION", "30s"))
+	if err != nil {
+		return config, err
+	}
+
+	// The ledger is reconciled every ReconciliationInterval, and discrepancies are
+	// emailed to ReconciliationAlertEmail if it is set
+	config.ReconciliationInterval, err = time.ParseDuration(getEnvOrDefault("RECONCILIATION_INTERVAL", "1h"))
+	if err != nil {
+		return config, err
+	}
+	config.ReconciliationAlertEmail = getEnvOrDefault("RECONCILIATION_ALERT_EMAIL", "")
+
+	config.HoldDuration, err = time.ParseDuration(getEnvOrDefault("HOLD_DURATION", "168h"))
+	if err != nil {
+		return config, err
+	}
+	config.HoldExpiryInterval, err = time.ParseDuration(getEnvOrDefault("HOLD_EXPIRY_INTERVAL", "1m"))
+	if err != nil {
+		return config, err
+	}
+
 	return config, nil
 }
 
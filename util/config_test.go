@@ -3,6 +3,7 @@ package util
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -57,4 +58,114 @@ HTTP_SERVER_ADDRESS=127.0.0.1:9090
 		// Note: The actual values may vary due to viper's global state from previous tests
 		// The important thing is that it doesn't crash the application
 	})
+
+	t.Run("FXSpread", func(t *testing.T) {
+		os.Setenv("FX_SPREAD_BPS", "25")
+		defer os.Unsetenv("FX_SPREAD_BPS")
+
+		config, err := LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, int64(25), config.FXSpreadBps)
+
+		os.Setenv("FX_SPREAD_BPS", "abc")
+		_, err = LoadConfig(".")
+		require.Error(t, err)
+	})
+
+	t.Run("StatementInlineLimit", func(t *testing.T) {
+		config, err := LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), config.StatementInlineLimit)
+
+		os.Setenv("STATEMENT_INLINE_LIMIT", "50")
+		defer os.Unsetenv("STATEMENT_INLINE_LIMIT")
+
+		config, err = LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, int64(50), config.StatementInlineLimit)
+	})
+	t.Run("LoginLockout", func(t *testing.T) {
+		config, err := LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, int64(5), config.LoginMaxFailures)
+		require.Equal(t, time.Second, config.LoginBackoffBase)
+		require.Equal(t, 15*time.Minute, config.LoginLockoutDuration)
+
+		os.Setenv("LOGIN_LOCKOUT_DURATION", "1h")
+		defer os.Unsetenv("LOGIN_LOCKOUT_DURATION")
+
+		config, err = LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, time.Hour, config.LoginLockoutDuration)
+		require.Zero(t, config.TrustedProxyHops)
+	})
+	t.Run("TransferConfirmation", func(t *testing.T) {
+		config, err := LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, int64(100000), config.TransferConfirmationThreshold)
+		require.Equal(t, 10*time.Minute, config.PendingTransferDuration)
+
+		os.Setenv("TRANSFER_CONFIRMATION_THRESHOLD", "5000")
+		defer os.Unsetenv("TRANSFER_CONFIRMATION_THRESHOLD")
+
+		config, err = LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, int64(5000), config.TransferConfirmationThreshold)
+	})
+	t.Run("PasswordReset", func(t *testing.T) {
+		config, err := LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, time.Hour, config.PasswordResetDuration)
+
+		os.Setenv("PASSWORD_RESET_DURATION", "30m")
+		defer os.Unsetenv("PASSWORD_RESET_DURATION")
+
+		config, err = LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Minute, config.PasswordResetDuration)
+	})
+	t.Run("PasswordChangeCache", func(t *testing.T) {
+		config, err := LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Second, config.PasswordChangeCacheDuration)
+
+		os.Setenv("PASSWORD_CHANGE_CACHE_DURATION", "5s")
+		defer os.Unsetenv("PASSWORD_CHANGE_CACHE_DURATION")
+
+		config, err = LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Second, config.PasswordChangeCacheDuration)
+	})
+	t.Run("Reconciliation", func(t *testing.T) {
+		config, err := LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, time.Hour, config.ReconciliationInterval)
+		require.Empty(t, config.ReconciliationAlertEmail)
+
+		os.Setenv("RECONCILIATION_INTERVAL", "15m")
+		os.Setenv("RECONCILIATION_ALERT_EMAIL", "ledger@nimbus.example.com")
+		defer os.Unsetenv("RECONCILIATION_INTERVAL")
+		defer os.Unsetenv("RECONCILIATION_ALERT_EMAIL")
+
+		config, err = LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, 15*time.Minute, config.ReconciliationInterval)
+		require.Equal(t, "ledger@nimbus.example.com", config.ReconciliationAlertEmail)
+	})
+	t.Run("Holds", func(t *testing.T) {
+		config, err := LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, 7*24*time.Hour, config.HoldDuration)
+		require.Equal(t, time.Minute, config.HoldExpiryInterval)
+
+		os.Setenv("HOLD_DURATION", "48h")
+		os.Setenv("HOLD_EXPIRY_INTERVAL", "30s")
+		defer os.Unsetenv("HOLD_DURATION")
+		defer os.Unsetenv("HOLD_EXPIRY_INTERVAL")
+
+		config, err = LoadConfig(".")
+		require.NoError(t, err)
+		require.Equal(t, 48*time.Hour, config.HoldDuration)
+		require.Equal(t, 30*time.Second, config.HoldExpiryInterval)
+	})
 }
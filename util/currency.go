@@ -15,17 +15,6 @@ func IsSupportedCurrency(currency string) bool {
 	return false
 }
 
-// GetWelcomeCreditAmount returns the welcome credit amount in cents for different currencies
-// Base amount is $100 USD
-func GetWelcomeCreditAmount(currency string) int64 {
-	switch currency {
-	case USD:
-		return 10000 // $100.00 in cents
-	case EUR:
-		return 9500  // €95.00 in cents (approximate exchange rate)
-	case INR:
-		return 830000 // ₹8300.00 in paisa (approximate exchange rate)
-	default:
-		return 10000 // Default to USD equivalent
-	}
-}
+// WelcomeCreditAmount is the welcome credit in USD cents ($100.00).
+// New accounts in other currencies get it converted at the current rate.
+const WelcomeCreditAmount = 10000
@@ -0,0 +1,17 @@
+package util
+
+// Roles a user can have; every user signs up as a depositor
+const (
+	DepositorRole = "depositor"
+	SupportRole   = "support"
+	AdminRole     = "admin"
+)
+
+// MFAPendingRole is put in place of the user's role in the token a login returns
+// while the second factor is outstanding. Nothing accepts it except MFA verification.
+const MFAPendingRole = "mfa_pending"
+
+// IsStaffRole reports whether the role belongs to Nimbus staff, who may read any account
+func IsStaffRole(role string) bool {
+	return role == SupportRole || role == AdminRole
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// NewSecretCode returns a random code for a one-time link. Unlike RandomString it
+// reads from crypto/rand, so the code can't be predicted.
+func NewSecretCode() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("cannot generate secret code: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// HashSecretCode returns the hash a one-time link's code is stored as, so a leaked
+// row can't be used to follow the link
+func HashSecretCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
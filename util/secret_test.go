@@ -0,0 +1,21 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSecretCode(t *testing.T) {
+	code1, err := NewSecretCode()
+	require.NoError(t, err)
+	require.Len(t, code1, 64)
+
+	code2, err := NewSecretCode()
+	require.NoError(t, err)
+	require.NotEqual(t, code1, code2)
+
+	require.Equal(t, HashSecretCode(code1), HashSecretCode(code1))
+	require.NotEqual(t, HashSecretCode(code1), HashSecretCode(code2))
+	require.NotEqual(t, code1, HashSecretCode(code1))
+}
@@ -3,11 +3,14 @@ package val
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 var (
-	isValidateUsername = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
-	isValidateEmail    = regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`).MatchString
+	isValidateUsername       = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
+	isValidateEmail          = regexp.MustCompile(`^[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}$`).MatchString
+	isValidateIdempotencyKey = regexp.MustCompile(`^[A-Za-z0-9._:\-]+$`).MatchString
+	isValidateTOTPCode       = regexp.MustCompile(`^[0-9]{6}$`).MatchString
 )
 
 func validateString(value string, minLength int, maxLength int) error {
@@ -145,3 +148,49 @@ func ValidatePageSize(value int32) error {
 	}
 	return nil
 }
+
+func ValidateAdjustmentAmount(value int64) error {
+	if value == 0 {
+		return fmt.Errorf("must not be zero")
+	}
+	if value > 1000000000 || value < -1000000000 {
+		return fmt.Errorf("must be within 1,000,000,000 either way")
+	}
+	return nil
+}
+
+func ValidateOverdraftLimit(value int64) error {
+	if value < 0 {
+		return fmt.Errorf("must not be negative")
+	}
+	if value > 1000000000 {
+		return fmt.Errorf("must not exceed 1,000,000,000")
+	}
+	return nil
+}
+
+func ValidateReason(value string) error {
+	return validateString(strings.TrimSpace(value), 5, 500)
+}
+
+func ValidateIdempotencyKey(value string) error {
+	if err := validateString(value, 1, 255); err != nil {
+		return err
+	}
+	if !isValidateIdempotencyKey(value) {
+		return fmt.Errorf("must contain only letters, digits, dot, colon, dash or underscore")
+	}
+	return nil
+}
+
+func ValidateTOTPCode(value string) error {
+	if !isValidateTOTPCode(value) {
+		return fmt.Errorf("must be a 6-digit code")
+	}
+	return nil
+}
+
+// ValidateMFACode accepts either a TOTP code or a recovery code
+func ValidateMFACode(value string) error {
+	return validateString(strings.TrimSpace(value), 6, 20)
+}
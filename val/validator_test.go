@@ -1,6 +1,7 @@
 package val
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -509,6 +510,86 @@ func TestValidateAccountOwner(t *testing.T) {
 	}
 }
 
+func TestValidateIdempotencyKey(t *testing.T) {
+	testCases := []struct {
+		name      string
+		key       string
+		expectErr bool
+	}{
+		{
+			name:      "valid uuid",
+			key:       "3f2b8c1e-7d4a-4e3b-9a6f-2c1d0e9b8a7f",
+			expectErr: false,
+		},
+		{
+			name:      "empty",
+			key:       "",
+			expectErr: true,
+		},
+		{
+			name:      "too long",
+			key:       strings.Repeat("a", 256),
+			expectErr: true,
+		},
+		{
+			name:      "invalid characters",
+			key:       "retry key/1",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateIdempotencyKey(tc.key)
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	testCases := []struct {
+		name      string
+		code      string
+		expectErr bool
+	}{
+		{
+			name:      "valid",
+			code:      "012345",
+			expectErr: false,
+		},
+		{
+			name:      "too short",
+			code:      "12345",
+			expectErr: true,
+		},
+		{
+			name:      "letters",
+			code:      "12345a",
+			expectErr: true,
+		},
+		{
+			name:      "recovery code",
+			code:      "abcde-fghij",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTOTPCode(tc.code)
+			if tc.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
 // Benchmark tests
 func BenchmarkValidateUsername(b *testing.B) {
 	username := "test_user123"
@@ -544,4 +625,3 @@ func BenchmarkValidateCurrency(b *testing.B) {
 		_ = ValidateCurrency(currency)
 	}
 }
-
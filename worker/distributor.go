@@ -17,6 +17,41 @@ type TaskDistributor interface {
 		payload *PayloadDemoResponse,
 		opts ...asynq.Option,
 	) error
+	DistributeTaskSendStatement(
+		ctx context.Context,
+		payload *PayloadSendStatement,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendLockoutEmail(
+		ctx context.Context,
+		payload *PayloadSendLockoutEmail,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskExpirePendingTransfer(
+		ctx context.Context,
+		payload *PayloadExpirePendingTransfer,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendPasswordResetEmail(
+		ctx context.Context,
+		payload *PayloadSendPasswordResetEmail,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendEmailChangeVerification(
+		ctx context.Context,
+		payload *PayloadSendEmailChangeVerification,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskSendEmailChangeNotice(
+		ctx context.Context,
+		payload *PayloadSendEmailChangeNotice,
+		opts ...asynq.Option,
+	) error
+	DistributeTaskReconcileLedger(
+		ctx context.Context,
+		payload *PayloadReconcileLedger,
+		opts ...asynq.Option,
+	) error
 }
 
 type RedisTaskDistributor struct {
@@ -61,6 +61,139 @@ func (mr *MockTaskDistributorMockRecorder) DistributeTaskDemoResponse(ctx, paylo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskDemoResponse", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskDemoResponse), varargs...)
 }
 
+// DistributeTaskExpirePendingTransfer mocks base method.
+func (m *MockTaskDistributor) DistributeTaskExpirePendingTransfer(ctx context.Context, payload *worker.PayloadExpirePendingTransfer, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskExpirePendingTransfer", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskExpirePendingTransfer indicates an expected call of DistributeTaskExpirePendingTransfer.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskExpirePendingTransfer(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskExpirePendingTransfer", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskExpirePendingTransfer), varargs...)
+}
+
+// DistributeTaskReconcileLedger mocks base method.
+func (m *MockTaskDistributor) DistributeTaskReconcileLedger(ctx context.Context, payload *worker.PayloadReconcileLedger, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskReconcileLedger", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskReconcileLedger indicates an expected call of DistributeTaskReconcileLedger.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskReconcileLedger(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskReconcileLedger", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskReconcileLedger), varargs...)
+}
+
+// DistributeTaskSendEmailChangeNotice mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendEmailChangeNotice(ctx context.Context, payload *worker.PayloadSendEmailChangeNotice, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendEmailChangeNotice", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendEmailChangeNotice indicates an expected call of DistributeTaskSendEmailChangeNotice.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendEmailChangeNotice(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendEmailChangeNotice", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendEmailChangeNotice), varargs...)
+}
+
+// DistributeTaskSendEmailChangeVerification mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendEmailChangeVerification(ctx context.Context, payload *worker.PayloadSendEmailChangeVerification, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendEmailChangeVerification", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendEmailChangeVerification indicates an expected call of DistributeTaskSendEmailChangeVerification.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendEmailChangeVerification(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendEmailChangeVerification", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendEmailChangeVerification), varargs...)
+}
+
+// DistributeTaskSendLockoutEmail mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendLockoutEmail(ctx context.Context, payload *worker.PayloadSendLockoutEmail, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendLockoutEmail", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendLockoutEmail indicates an expected call of DistributeTaskSendLockoutEmail.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendLockoutEmail(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendLockoutEmail", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendLockoutEmail), varargs...)
+}
+
+// DistributeTaskSendPasswordResetEmail mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendPasswordResetEmail(ctx context.Context, payload *worker.PayloadSendPasswordResetEmail, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendPasswordResetEmail", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendPasswordResetEmail indicates an expected call of DistributeTaskSendPasswordResetEmail.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendPasswordResetEmail(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendPasswordResetEmail", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendPasswordResetEmail), varargs...)
+}
+
+// DistributeTaskSendStatement mocks base method.
+func (m *MockTaskDistributor) DistributeTaskSendStatement(ctx context.Context, payload *worker.PayloadSendStatement, opts ...asynq.Option) error {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, payload}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DistributeTaskSendStatement", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DistributeTaskSendStatement indicates an expected call of DistributeTaskSendStatement.
+func (mr *MockTaskDistributorMockRecorder) DistributeTaskSendStatement(ctx, payload any, opts ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, payload}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DistributeTaskSendStatement", reflect.TypeOf((*MockTaskDistributor)(nil).DistributeTaskSendStatement), varargs...)
+}
+
 // DistributeTaskSendVerifyEmail mocks base method.
 func (m *MockTaskDistributor) DistributeTaskSendVerifyEmail(ctx context.Context, payload *worker.PayloadSendVerifyEmail, opts ...asynq.Option) error {
 	m.ctrl.T.Helper()
@@ -57,6 +57,14 @@ func (processor *RedisTaskProcessor) Start() error {
 
 	mux.HandleFunc(TaskSendVerifyEmail, processor.ProcessTaskSendVerifyEmail)
 	mux.HandleFunc(TaskDemoResponse, processor.ProcessTaskDemoResponse)
+	mux.HandleFunc(TaskSendStatement, processor.ProcessTaskSendStatement)
+	mux.HandleFunc(TaskSendLockoutEmail, processor.ProcessTaskSendLockoutEmail)
+	mux.HandleFunc(TaskExpirePendingTransfer, processor.ProcessTaskExpirePendingTransfer)
+	mux.HandleFunc(TaskSendPasswordResetEmail, processor.ProcessTaskSendPasswordResetEmail)
+	mux.HandleFunc(TaskSendEmailChangeVerification, processor.ProcessTaskSendEmailChangeVerification)
+	mux.HandleFunc(TaskSendEmailChangeNotice, processor.ProcessTaskSendEmailChangeNotice)
+	mux.HandleFunc(TaskReconcileLedger, processor.ProcessTaskReconcileLedger)
+	mux.HandleFunc(TaskExpireHolds, processor.ProcessTaskExpireHolds)
 
 	return processor.server.Start(mux)
 }
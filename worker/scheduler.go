@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+// TaskScheduler enqueues the tasks that run periodically
+type TaskScheduler interface {
+	Start() error
+	Shutdown()
+}
+
+type RedisTaskScheduler struct {
+	scheduler *asynq.Scheduler
+	config    *util.Config
+}
+
+func NewRedisTaskScheduler(redisOpt asynq.RedisClientOpt, config *util.Config) TaskScheduler {
+	scheduler := asynq.NewScheduler(
+		redisOpt,
+		&asynq.SchedulerOpts{
+			EnqueueErrorHandler: func(task *asynq.Task, opts []asynq.Option, err error) {
+				log.Error().Err(err).Str("type", task.Type()).
+					Bytes("payload", task.Payload()).Msg("enqueue scheduled task failed")
+			},
+			Logger: NewLogger(),
+		},
+	)
+
+	return &RedisTaskScheduler{
+		scheduler: scheduler,
+		config:    config,
+	}
+}
+
+func (scheduler *RedisTaskScheduler) Start() error {
+	payload, err := json.Marshal(&PayloadReconcileLedger{TriggeredBy: ReconcileLedgerScheduler})
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	// Every server instance runs a scheduler; Unique keeps them from enqueueing a run each
+	interval := scheduler.config.ReconciliationInterval
+	_, err = scheduler.scheduler.Register(
+		fmt.Sprintf("@every %s", interval),
+		asynq.NewTask(TaskReconcileLedger, payload),
+		asynq.Queue(QueueDefault),
+		asynq.Unique(interval),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to schedule ledger reconciliation: %w", err)
+	}
+
+	interval = scheduler.config.HoldExpiryInterval
+	_, err = scheduler.scheduler.Register(
+		fmt.Sprintf("@every %s", interval),
+		asynq.NewTask(TaskExpireHolds, nil),
+		asynq.Queue(QueueDefault),
+		asynq.Unique(interval),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to schedule hold expiry: %w", err)
+	}
+
+	return scheduler.scheduler.Start()
+}
+
+func (scheduler *RedisTaskScheduler) Shutdown() {
+	scheduler.scheduler.Shutdown()
+}
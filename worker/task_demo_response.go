@@ -74,6 +74,7 @@ func (processor *RedisTaskProcessor) ProcessTaskDemoResponse(ctx context.Context
 			FromAccountID: demoAccount.ID,
 			ToAccountID:   payload.FromAccountID,
 			Amount:        response1Amount,
+			Currency:      demoAccount.Currency,
 		})
 		if err != nil {
 			log.Error().Err(err).Msg("failed to create demo response 1")
@@ -93,6 +94,7 @@ func (processor *RedisTaskProcessor) ProcessTaskDemoResponse(ctx context.Context
 			FromAccountID: demoAccount.ID,
 			ToAccountID:   payload.FromAccountID,
 			Amount:        response2Amount,
+			Currency:      demoAccount.Currency,
 		})
 		if err != nil {
 			log.Error().Err(err).Msg("failed to create demo response 2")
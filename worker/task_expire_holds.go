@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskExpireHolds = "task:expire_holds"
+
+// expireHoldsActor is who expired holds are recorded as released by
+const expireHoldsActor = "scheduler"
+
+// expireHoldsBatchSize is how many expired holds a run releases; the next run picks up the rest
+const expireHoldsBatchSize = 500
+
+// ProcessTaskExpireHolds releases the holds whose expiry has passed. Each hold is
+// released in its own transaction, so one that fails doesn't keep the others held.
+func (processor *RedisTaskProcessor) ProcessTaskExpireHolds(ctx context.Context, task *asynq.Task) error {
+	ids, err := processor.store.ListExpiredHolds(ctx, db.ListExpiredHoldsParams{
+		ExpiredBefore: time.Now(),
+		Limit:         expireHoldsBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list expired holds: %w", err)
+	}
+
+	released := 0
+	for _, id := range ids {
+		_, err := processor.store.ReleaseHoldTx(ctx, db.ReleaseHoldTxParams{
+			ID:      id,
+			Expired: true,
+			Audit:   db.AuditContext{Actor: expireHoldsActor},
+		})
+		if err != nil {
+			// The hold was captured or released since it was listed
+			if errors.Is(err, db.ErrHoldNotActive) {
+				continue
+			}
+			log.Error().Err(err).Int64("hold_id", id).Msg("failed to release expired hold")
+			continue
+		}
+		released++
+	}
+
+	log.Info().Str("type", task.Type()).Int("expired", len(ids)).
+		Int("released", released).Msg("processed task")
+	return nil
+}
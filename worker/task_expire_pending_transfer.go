@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskExpirePendingTransfer = "task:expire_pending_transfer"
+
+type PayloadExpirePendingTransfer struct {
+	PendingTransferID int64 `json:"pending_transfer_id"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskExpirePendingTransfer(
+	ctx context.Context,
+	payload *PayloadExpirePendingTransfer,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskExpirePendingTransfer, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+// ProcessTaskExpirePendingTransfer runs when a pending transfer's confirmation window
+// closes. Transfers that were confirmed in time are left alone.
+func (processor *RedisTaskProcessor) ProcessTaskExpirePendingTransfer(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadExpirePendingTransfer
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	pending, err := processor.store.ExpirePendingTransfer(ctx, payload.PendingTransferID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+				Msg("pending transfer is no longer pending")
+			return nil
+		}
+		return fmt.Errorf("failed to expire pending transfer: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("username", pending.Username).Msg("processed task")
+	return nil
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskReconcileLedger = "task:reconcile_ledger"
+
+// ReconcileLedgerScheduler is who scheduled reconciliation runs are recorded as triggered by
+const ReconcileLedgerScheduler = "scheduler"
+
+// reconciliationSettleTime is how old ledger rows must be before a run checks them,
+// so it doesn't move past rows of transactions that haven't committed yet
+const reconciliationSettleTime = time.Minute
+
+// reconciliationAlertLimit is how many discrepancies an alert email lists
+const reconciliationAlertLimit = 50
+
+type PayloadReconcileLedger struct {
+	// TriggeredBy is the admin who asked for the run, or ReconcileLedgerScheduler
+	TriggeredBy string `json:"triggered_by"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskReconcileLedger(
+	ctx context.Context,
+	payload *PayloadReconcileLedger,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskReconcileLedger, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+// ProcessTaskReconcileLedger checks the ledger rows added since the previous run and
+// emails the discrepancies it finds to the reconciliation alert address
+func (processor *RedisTaskProcessor) ProcessTaskReconcileLedger(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadReconcileLedger
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	result, err := processor.store.ReconcileLedgerTx(ctx, db.ReconcileLedgerTxParams{
+		TriggeredBy: payload.TriggeredBy,
+		Until:       time.Now().Add(-reconciliationSettleTime),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ledger: %w", err)
+	}
+
+	if len(result.Discrepancies) > 0 {
+		log.Error().Str("type", task.Type()).Int64("run_id", result.Run.ID).
+			Int("discrepancies", len(result.Discrepancies)).Msg("ledger discrepancies found")
+
+		// A retry would start a new run over later rows, so a failed alert is only logged
+		if err := processor.sendReconciliationAlert(result); err != nil {
+			log.Error().Err(err).Int64("run_id", result.Run.ID).Msg("failed to send reconciliation alert")
+		}
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Int64("run_id", result.Run.ID).Msg("processed task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) sendReconciliationAlert(result db.ReconcileLedgerTxResult) error {
+	if processor.config.ReconciliationAlertEmail == "" {
+		return fmt.Errorf("no reconciliation alert email is configured")
+	}
+
+	var lines strings.Builder
+	for i, discrepancy := range result.Discrepancies {
+		if i == reconciliationAlertLimit {
+			fmt.Fprintf(&lines, "... and %d more<br/>\n", len(result.Discrepancies)-i)
+			break
+		}
+		fmt.Fprintf(&lines, "%s: %s<br/>\n", discrepancy.Kind, describeDiscrepancy(discrepancy))
+	}
+
+	subject := fmt.Sprintf("Nimbus ledger reconciliation found %d discrepancies", len(result.Discrepancies))
+	content := fmt.Sprintf(`Reconciliation run %d, triggered by %s, found these discrepancies:<br/>
+%s
+They are recorded in ledger_discrepancies with run_id %d.<br/>
+`, result.Run.ID, result.Run.TriggeredBy, lines.String(), result.Run.ID)
+	to := []string{processor.config.ReconciliationAlertEmail}
+
+	return processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+}
+
+// describeDiscrepancy names the account and transfer a discrepancy is about and the amounts that differ
+func describeDiscrepancy(discrepancy db.LedgerDiscrepancy) string {
+	var parts []string
+	if discrepancy.AccountID.Valid {
+		parts = append(parts, fmt.Sprintf("account %d", discrepancy.AccountID.Int64))
+	}
+	if discrepancy.TransferID.Valid {
+		parts = append(parts, fmt.Sprintf("transfer %d", discrepancy.TransferID.Int64))
+	}
+	parts = append(parts, fmt.Sprintf("expected %d, found %d", discrepancy.Expected, discrepancy.Actual))
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendEmailChangeNotice = "task:send_email_change_notice"
+
+// PayloadSendEmailChangeNotice is sent to the address the user had when they
+// asked for the change, in case someone else did
+type PayloadSendEmailChangeNotice struct {
+	Username string `json:"username"`
+	OldEmail string `json:"old_email"`
+	NewEmail string `json:"new_email"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendEmailChangeNotice(
+	ctx context.Context,
+	payload *PayloadSendEmailChangeNotice,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendEmailChangeNotice, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendEmailChangeNotice(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendEmailChangeNotice
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	subject := "Your Nimbus email address is being changed"
+	content := fmt.Sprintf(`Hello %s,<br/>
+Someone asked to change the email address of your Nimbus account to %s.<br/>
+The change will only take effect once the new address is confirmed.<br/>
+If this wasn't you, please change your password right away.<br/>
+`, user.FullName, payload.NewEmail)
+	to := []string{payload.OldEmail}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send email change notice: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("email", payload.OldEmail).Msg("processed task")
+	return nil
+}
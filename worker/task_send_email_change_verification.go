@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendEmailChangeVerification = "task:send_email_change_verification"
+
+type PayloadSendEmailChangeVerification struct {
+	Username string `json:"username"`
+	NewEmail string `json:"new_email"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendEmailChangeVerification(
+	ctx context.Context,
+	payload *PayloadSendEmailChangeVerification,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendEmailChangeVerification, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendEmailChangeVerification(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendEmailChangeVerification
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	secretCode, err := util.NewSecretCode()
+	if err != nil {
+		return err
+	}
+	emailChange, err := processor.store.CreateEmailChange(ctx, db.CreateEmailChangeParams{
+		Username:   user.Username,
+		OldEmail:   user.Email,
+		NewEmail:   payload.NewEmail,
+		SecretHash: util.HashSecretCode(secretCode),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create email change: %w", err)
+	}
+
+	subject := "Confirm your new Nimbus email address"
+	confirmUrl := fmt.Sprintf("%s/v1/confirm_email_change?change_id=%d&secret_code=%s",
+		processor.config.EmailVerificationURL, emailChange.ID, secretCode)
+	content := fmt.Sprintf(`Hello %s,<br/>
+You asked to change the email address of your Nimbus account to this one.<br/>
+Please <a href="%s">click here to confirm your new email address</a><br/>
+Or copy and paste this link in your browser: %s<br/>
+Your email address won't change until you do. This link will expire in 24 hours.<br/>
+`, user.FullName, confirmUrl, confirmUrl)
+	to := []string{emailChange.NewEmail}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send email change verification: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("email", emailChange.NewEmail).Msg("processed task")
+	return nil
+}
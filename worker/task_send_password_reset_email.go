@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	db "github.com/OmSingh2003/nimbus/db/sqlc"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendPasswordResetEmail = "task:send_password_reset_email"
+
+type PayloadSendPasswordResetEmail struct {
+	Username string `json:"username"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendPasswordResetEmail(
+	ctx context.Context,
+	payload *PayloadSendPasswordResetEmail,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendPasswordResetEmail, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendPasswordResetEmail(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendPasswordResetEmail
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Only the hash is stored; the code itself only ever appears in the email
+	secretCode, err := util.NewSecretCode()
+	if err != nil {
+		return err
+	}
+	passwordReset, err := processor.store.CreatePasswordReset(ctx, db.CreatePasswordResetParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretHash: util.HashSecretCode(secretCode),
+		ExpiredAt:  time.Now().Add(processor.config.PasswordResetDuration),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create password reset: %w", err)
+	}
+
+	subject := "Reset your Nimbus password"
+	resetUrl := fmt.Sprintf("%s?reset_id=%d&secret_code=%s",
+		processor.config.PasswordResetURL, passwordReset.ID, secretCode)
+	content := fmt.Sprintf(`Hello %s,<br/>
+We received a request to reset the password for your Nimbus account.<br/>
+Please <a href="%s">click here to choose a new password</a><br/>
+Or copy and paste this link in your browser: %s<br/>
+This link can only be used once and will expire in %s.<br/>
+If you didn't ask to reset your password, you can ignore this email.<br/>
+`, user.FullName, resetUrl, resetUrl, processor.config.PasswordResetDuration)
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("email", user.Email).Msg("processed task")
+	return nil
+}
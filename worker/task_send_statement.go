@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/OmSingh2003/nimbus/statement"
+	"github.com/OmSingh2003/nimbus/util"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskSendStatement = "task:send_statement"
+
+type PayloadSendStatement struct {
+	Username  string    `json:"username"`
+	AccountID int64     `json:"account_id"`
+	Format    string    `json:"format"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+func (distributor *RedisTaskDistributor) DistributeTaskSendStatement(
+	ctx context.Context,
+	payload *PayloadSendStatement,
+	opts ...asynq.Option,
+) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendStatement, jsonPayload, opts...)
+	info, err := distributor.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("queue", info.Queue).Int("max_retry", info.MaxRetry).Msg("enqueued task")
+	return nil
+}
+
+func (processor *RedisTaskProcessor) ProcessTaskSendStatement(ctx context.Context, task *asynq.Task) error {
+	var payload PayloadSendStatement
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	format, err := statement.ParseFormat(payload.Format)
+	if err != nil {
+		return fmt.Errorf("invalid statement format %q: %w", payload.Format, asynq.SkipRetry)
+	}
+
+	user, err := processor.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	account, err := processor.store.GetAccount(ctx, payload.AccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+	if account.Owner != user.Username && !util.IsStaffRole(user.Role) {
+		return fmt.Errorf("account %d doesn't belong to %s: %w", account.ID, user.Username, asynq.SkipRetry)
+	}
+
+	stmt, err := statement.Load(ctx, processor.store, account, payload.StartTime, payload.EndTime)
+	if err != nil {
+		return err
+	}
+
+	// The mailer attaches files from disk, so render into a temporary directory
+	dir, err := os.MkdirTemp("", "statement")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	fileName := filepath.Join(dir, format.FileName(stmt))
+	file, err := os.Create(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to create statement file: %w", err)
+	}
+	err = statement.Render(file, format, stmt)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render statement: %w", err)
+	}
+
+	subject := "Your Nimbus account statement"
+	content := fmt.Sprintf(`Hello %s,<br/>
+Attached is the statement of your %s account from %s to %s.<br/>
+It lists %d entries.<br/>
+`, user.FullName, account.Currency,
+		payload.StartTime.UTC().Format(time.RFC1123), payload.EndTime.UTC().Format(time.RFC1123), len(stmt.Lines))
+	to := []string{user.Email}
+
+	err = processor.mailer.SendEmail(subject, content, to, nil, nil, []string{fileName})
+	if err != nil {
+		return fmt.Errorf("failed to send statement email: %w", err)
+	}
+
+	log.Info().Str("type", task.Type()).Bytes("payload", task.Payload()).
+		Str("email", user.Email).Int("entries", len(stmt.Lines)).Msg("processed task")
+	return nil
+}